@@ -0,0 +1,103 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Field sort yang didukung listing prestasi
+const (
+	SortCreatedAt = "created_at" // achievement_references (PostgreSQL)
+	SortUpdatedAt = "updated_at" // achievement_references (PostgreSQL)
+	SortPoints    = "points"     // achievements (MongoDB)
+	SortTitle     = "title"      // achievements (MongoDB)
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Filter listing prestasi. Field kosong / nil berarti tidak difilter
+type Filter struct {
+	// Sisi PostgreSQL
+	Statuses       []string   // Hanya status ini
+	HiddenStatuses []string   // Status yang disembunyikan oleh scope endpoint
+	CreatedFrom    *time.Time // Inklusif
+	CreatedTo      *time.Time // Inklusif
+	StudentID      string
+	ProgramStudy   string
+	AdvisorID      string   // lecturers.id
+	AdvisorUserID  string   // Scope Dosen Wali yang sedang login (users.id)
+	MongoIDs       []string // Hasil filter sisi MongoDB (nil = tidak dibatasi)
+
+	// Sisi MongoDB
+	AchievementType string
+	Tag             string
+	MinPoints       *int
+	MaxPoints       *int
+}
+
+// Ada filter yang harus dijalankan di MongoDB
+func (f Filter) HasMongoFilter() bool {
+	return f.AchievementType != "" || f.Tag != "" || f.MinPoints != nil || f.MaxPoints != nil
+}
+
+type Query struct {
+	Filter Filter
+	SortBy string
+	Desc   bool
+	Limit  int
+	After  *Cursor // Nil = halaman pertama
+}
+
+// Sort dijalankan di MongoDB (field konten), selain itu di PostgreSQL
+func (q Query) SortInMongo() bool {
+	return q.SortBy == SortPoints || q.SortBy == SortTitle
+}
+
+func ValidSort(field string) bool {
+	switch field {
+	case SortCreatedAt, SortUpdatedAt, SortPoints, SortTitle:
+		return true
+	}
+	return false
+}
+
+// Posisi terakhir sebuah halaman (keyset): nilai field sort + id sebagai tie-breaker.
+// Sort & arah ikut disimpan agar cursor tidak dipakai dengan urutan yang berbeda
+type Cursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d"`
+	Value  string `json:"v"`
+	ID     string `json:"id"`
+}
+
+// Cursor dikirim ke client sebagai string opaque (base64url JSON)
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode cursor dan pastikan cocok dengan sort yang diminta
+func DecodeCursor(raw, sortBy string, desc bool) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortBy != sortBy || c.Desc != desc {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
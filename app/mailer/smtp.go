@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// Pengirim SMTP. STARTTLS dipakai jika server mendukung (wajib jika RequireTLS)
+type SMTP struct {
+	Addr       string // host:port
+	Username   string // Kosong = tanpa AUTH
+	Password   string
+	From       string // "Nama <alamat@domain>"
+	RequireTLS bool
+	Timeout    time.Duration
+	TLSConfig  *tls.Config // Opsional (default: verifikasi sertifikat host)
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	data, err := Build(s.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(s.From)
+	to, _ := mail.ParseAddress(msg.To)
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	host, _, _ := net.SplitHostPort(s.Addr)
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		cfg := s.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{ServerName: host}
+		}
+		if err := c.StartTLS(cfg); err != nil {
+			return classify(err)
+		}
+	} else if s.RequireTLS {
+		return fmt.Errorf("%w: server %s does not support STARTTLS", ErrPermanent, s.Addr)
+	}
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return classify(err)
+		}
+	}
+
+	if err := c.Mail(from.Address); err != nil {
+		return classify(err)
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return classify(err)
+	}
+	w, err := c.Data()
+	if err != nil {
+		return classify(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return classify(err)
+	}
+	if err := w.Close(); err != nil {
+		return classify(err)
+	}
+	return c.Quit()
+}
+
+// Balasan 5xx (mis. alamat tidak ada) tidak akan berhasil jika diulang; 4xx & error jaringan dicoba lagi
+func classify(err error) error {
+	var tp *textproto.Error
+	if errors.As(err, &tp) && tp.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	return err
+}
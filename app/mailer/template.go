@@ -0,0 +1,99 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Bahasa email yang didukung; locale lain memakai DefaultLocale
+const DefaultLocale = "id"
+
+var Locales = []string{"id", "en"}
+
+// Format tanggal per bahasa (dipakai template lewat fungsi "date")
+var dateFormats = map[string]string{
+	"id": "02/01/2006 15:04",
+	"en": "Jan 2, 2006 15:04",
+}
+
+// Template nama event: "<nama>.subject" & "<nama>.text" (teks) dan "<nama>.html"
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// Data yang tersedia di template
+type Data struct {
+	RecipientName string
+	StudentName   string
+	StudentNIM    string
+	Note          string
+	Link          string       // URL aplikasi (kosong jika APP_BASE_URL tidak diatur)
+	Advisor       bool         // Penerima adalah dosen wali
+	FormerAdvisor bool         // advisor_changed: penerima adalah dosen wali lama
+	Pending       []DigestItem // lecturer_digest
+}
+
+type DigestItem struct {
+	StudentName string
+	StudentNIM  string
+	Link        string
+	SubmittedAt time.Time
+}
+
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{text: map[string]*texttemplate.Template{}, html: map[string]*htmltemplate.Template{}}
+	for _, locale := range Locales {
+		layout := dateFormats[locale]
+		date := func(tm time.Time) string { return tm.Local().Format(layout) }
+
+		text, err := texttemplate.New(locale).Funcs(texttemplate.FuncMap{"date": date}).
+			ParseFS(templateFS, "templates/"+locale+".txt.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		html, err := htmltemplate.New(locale).Funcs(htmltemplate.FuncMap{"date": date}).
+			ParseFS(templateFS, "templates/"+locale+".html.tmpl")
+		if err != nil {
+			return nil, err
+		}
+		t.text[locale], t.html[locale] = text, html
+	}
+	return t, nil
+}
+
+// Render subject, isi teks & HTML (Message.To diisi pemanggil)
+func (t *Templates) Render(name, locale string, data Data) (Message, error) {
+	if _, ok := t.text[locale]; !ok {
+		locale = DefaultLocale
+	}
+	var subject, text, html bytes.Buffer
+	if err := t.text[locale].ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	if err := t.text[locale].ExecuteTemplate(&text, name+".text", data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := t.html[locale].ExecuteTemplate(&html, name+".html", data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+	return Message{
+		// Subject satu baris (mencegah header injection dari nama / catatan)
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// Cek template milik event tersedia (untuk event tanpa email, mis. komentar)
+func (t *Templates) Has(name string) bool {
+	return t.text[DefaultLocale].Lookup(name+".subject") != nil
+}
@@ -7,10 +7,57 @@ import (
 
 // Struct khusus untuk object di dalam array attachments
 type Attachment struct {
+	ID         string    `bson:"id,omitempty" json:"id,omitempty"`
 	FileName   string    `bson:"fileName" json:"fileName"`
-	FileURL    string    `bson:"fileUrl" json:"fileUrl"`
-	FileType   string    `bson:"fileType" json:"fileType"`
+	FileURL    string    `bson:"fileUrl" json:"fileUrl"` // Endpoint download (butuh akses ke prestasi)
+	FileType   string    `bson:"fileType" json:"fileType"` // Hasil sniffing isi file, bukan dari client
+	Size       int64     `bson:"size,omitempty" json:"size,omitempty"`
+	ChecksumSHA256 string `bson:"checksumSha256,omitempty" json:"checksumSha256,omitempty"`
+	StorageKey string    `bson:"storageKey,omitempty" json:"-"` // Key di backend storage, tidak diekspos
 	UploadedAt time.Time `bson:"uploadedAt" json:"uploadedAt"`
+
+	// Hasil scan antivirus (kosong = diunggah sebelum ada scanning)
+	ScanStatus    string     `bson:"scanStatus,omitempty" json:"scanStatus,omitempty"`
+	ScanSignature string     `bson:"scanSignature,omitempty" json:"scanSignature,omitempty"`
+	ScannedAt     *time.Time `bson:"scannedAt,omitempty" json:"scannedAt,omitempty"`
+
+	// Percobaan scan yang gagal (retry dengan backoff sampai batas, lalu ScanFailed)
+	ScanAttempts      int        `bson:"scanAttempts,omitempty" json:"-"`
+	ScanNextAttemptAt *time.Time `bson:"scanNextAttemptAt,omitempty" json:"-"`
+	ScanError         string     `bson:"scanError,omitempty" json:"-"`
+
+	// Thumbnail & preview (dibuat worker setelah lampiran lolos scan)
+	Preview *AttachmentPreview `bson:"preview,omitempty" json:"preview,omitempty"`
+}
+
+// Status pembuatan preview lampiran
+const (
+	PreviewPending     = "pending"
+	PreviewReady       = "ready"
+	PreviewUnsupported = "unsupported" // Tipe file tidak bisa dirender (mis. PDF tanpa renderer)
+	PreviewFailed      = "failed"      // File rusak, tidak dicoba ulang
+)
+
+type AttachmentPreview struct {
+	Status       string `bson:"status" json:"status"`
+	ThumbnailURL string `bson:"thumbnailUrl,omitempty" json:"thumbnailUrl,omitempty"`
+	PreviewURL   string `bson:"previewUrl,omitempty" json:"previewUrl,omitempty"`
+	ThumbnailKey string `bson:"thumbnailKey,omitempty" json:"-"`
+	PreviewKey   string `bson:"previewKey,omitempty" json:"-"`
+}
+
+// Status scan lampiran
+const (
+	ScanPending  = "pending"  // Menunggu scanner, belum bisa diunduh / di-submit
+	ScanClean    = "clean"
+	ScanInfected = "infected" // File dipindah ke karantina
+	ScanFailed   = "failed"   // Tidak bisa discan (file hilang / batas retry), harus diunggah ulang
+)
+
+// Lampiran yang menunggu diproses worker (scan / preview) beserta dokumen pemiliknya
+type PendingAttachment struct {
+	AchievementID primitive.ObjectID `bson:"_id"`
+	Attachment    Attachment         `bson:"attachment"`
 }
 
 type Achievement struct {
@@ -24,8 +71,14 @@ type Achievement struct {
 	Attachments []Attachment `bson:"attachments" json:"attachments"` // Array of Objects
 	Tags        []string     `bson:"tags" json:"tags"`
 	Points      int          `bson:"points" json:"points"` // Number (Poin prestasi)
+	PointsRuleVersion int    `bson:"pointsRuleVersion,omitempty" json:"pointsRuleVersion,omitempty"` // Versi aturan poin (0 = poin default jenis)
 
 	CreatedAt time.Time  `bson:"createdAt" json:"createdAt"`
 	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`
 	DeletedAt *time.Time `bson:"deletedAt,omitempty" json:"deletedAt,omitempty"` // Soft Delete support
-}
\ No newline at end of file
+}
+// Hasil pencarian full-text beserta skor relevansi ($meta: textScore)
+type AchievementSearchHit struct {
+	Achievement `bson:",inline"`
+	Score       float64 `bson:"score" json:"score"`
+}
@@ -8,11 +8,77 @@ type AchievementReference struct {
 	ID                 string    `json:"id"`
 	StudentID          string    `json:"student_id"`
 	MongoAchievementID string    `json:"mongo_achievement_id"`
-	Status             string    `json:"status"` // draft, submitted, verified, rejected, deleted
+	Status             string    `json:"status"` // draft, submitted, verified, rejected, needs_revision, deleted
 	SubmittedAt        *time.Time `json:"submitted_at"`
 	VerifiedAt         *time.Time `json:"verified_at"`
 	RejectionNote      *string   `json:"rejection_note"`
 	VerifiedBy         *string   `json:"verified_by"`
+	VerifiedPoints     *int      `json:"verified_points"`     // dibekukan saat verifikasi
+	PointsRuleVersion  *int      `json:"points_rule_version"` // versi aturan poin yang dipakai
+	ReviewRound        int       `json:"review_round"`        // bertambah setiap kali diajukan (0 = belum pernah)
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
-}
\ No newline at end of file
+}
+// Satu baris log transisi status prestasi
+type AchievementStatusHistory struct {
+	ID               string    `json:"id"`
+	AchievementRefID string    `json:"achievement_ref_id"`
+	ActorID          *string   `json:"actor_id"`
+	ActorName        *string   `json:"actor_name,omitempty"` // Untuk join query
+	FromStatus       *string   `json:"from_status"`
+	ToStatus         string    `json:"to_status"`
+	Note             *string   `json:"note"`
+	Round            int       `json:"round"` // Ronde review saat transisi terjadi
+	CreatedAt        time.Time `json:"created_at"`
+
+	Comments []RevisionComment `json:"comments,omitempty"` // Komentar per field (needs_revision)
+}
+
+// Komentar Dosen Wali untuk satu field pada satu ronde review
+type RevisionComment struct {
+	ID               string    `json:"id"`
+	AchievementRefID string    `json:"achievement_ref_id"`
+	Round            int       `json:"round"`
+	Field            string    `json:"field"` // title, details.rank, attachments.<id>, ...
+	Comment          string    `json:"comment"`
+	CreatedBy        *string   `json:"created_by"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Batas jumlah komentar per permintaan revisi
+const MaxRevisionComments = 50
+
+type RevisionCommentInput struct {
+	Field   string `json:"field"`
+	Comment string `json:"comment"`
+}
+
+// Payload permintaan revisi: catatan umum (opsional) + komentar per field (minimal satu)
+type RevisionRequest struct {
+	Note     string                 `json:"note,omitempty"`
+	Comments []RevisionCommentInput `json:"comments"`
+}
+
+// Batas jumlah item per request review massal
+const MaxBulkReviewItems = 100
+
+// Satu keputusan dalam review massal (action: verify / reject)
+type BulkReviewItem struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Note   string `json:"note,omitempty"` // Wajib untuk reject
+}
+
+type BulkReviewRequest struct {
+	Items []BulkReviewItem `json:"items"`
+}
+
+// Hasil per item; item lain tetap diproses walau satu item gagal
+type BulkReviewResult struct {
+	ID      string `json:"id"`
+	Action  string `json:"action"`
+	Success bool   `json:"success"`
+	Status  string `json:"status,omitempty"` // Status setelah diproses
+	Code    int    `json:"code"`             // HTTP status yang setara untuk item ini
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis prestasi: JSON-Schema untuk details + poin default
+type AchievementType struct {
+	Code          string          `json:"code"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	DetailsSchema json.RawMessage `json:"details_schema" swaggertype:"object"`
+	DefaultPoints int             `json:"default_points"`
+	IsActive      bool            `json:"is_active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"time"
+)
+
+// Jenis event audit autentikasi
+const (
+	AuditLoginFailed       = "login_failed"
+	AuditLoginLocked       = "login_locked"     // Percobaan login saat akun terkunci
+	AuditLoginIPBlocked    = "login_ip_blocked" // Percobaan login saat IP sedang di-backoff
+	AuditAccountLocked     = "account_locked"
+	AuditAccountUnlocked   = "account_unlocked"
+	AuditPasswordChanged   = "password_changed"
+	AuditPasswordReset     = "password_reset"      // Admin menerbitkan token reset
+	AuditPasswordResetUsed = "password_reset_used" // User set password baru memakai token reset
+	AuditMFAEnabled        = "mfa_enabled"
+	AuditMFADisabled       = "mfa_disabled" // Oleh user sendiri atau reset oleh admin
+)
+
+type AuthAuditLog struct {
+	ID        string    `json:"id"`
+	UserID    *string   `json:"user_id"`
+	ActorID   *string   `json:"actor_id"`
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+	Event     string    `json:"event"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
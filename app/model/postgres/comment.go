@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"time"
+)
+
+// Panjang maksimal isi komentar
+const MaxCommentLength = 5000
+
+// Komentar diskusi pada prestasi. Balasan hanya satu tingkat (parent_id = komentar utama)
+type AchievementComment struct {
+	ID               string           `json:"id"`
+	AchievementRefID string           `json:"achievement_ref_id"`
+	ParentID         *string          `json:"parent_id"`
+	AuthorID         *string          `json:"author_id"`
+	AuthorName       *string          `json:"author_name,omitempty"` // Untuk join query
+	AuthorRole       *string          `json:"author_role,omitempty"`
+	Body             string           `json:"body"` // Kosong jika sudah dihapus
+	Mentions         []CommentMention `json:"mentions"`
+	CreatedAt        time.Time        `json:"created_at"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+	EditedAt         *time.Time       `json:"edited_at"`
+	DeletedAt        *time.Time       `json:"deleted_at,omitempty"`
+
+	Replies []AchievementComment `json:"replies,omitempty"`
+}
+
+type CommentMention struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+}
+
+// Payload tulis / ubah komentar. Mention ditulis di body sebagai @username
+type CommentRequest struct {
+	Body     string `json:"body"`
+	ParentID string `json:"parent_id,omitempty"` // Isi untuk membalas
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import "time"
+
+// Status email outbox (pending / processing / failed sama dengan achievement outbox)
+const EmailSent = "sent"
+
+// Template ringkasan harian Dosen Wali
+const EmailLecturerDigest = "lecturer_digest"
+
+// Jenis event yang juga dikirim lewat email (urutan tampilan preferensi)
+var EmailEventTypes = []string{
+	NotifAchievementSubmitted, NotifAchievementVerified, NotifAchievementRejected, NotifAchievementNeedsRevision,
+	NotifAdvisorChanged,
+}
+
+// Status tujuan transisi yang memicu email; event-nya ditulis di transaksi status yang sama
+var TransitionEmailEvents = map[string]string{
+	"submitted":      NotifAchievementSubmitted,
+	"verified":       NotifAchievementVerified,
+	"rejected":       NotifAchievementRejected,
+	"needs_revision": NotifAchievementNeedsRevision,
+}
+
+// Event workflow yang menunggu dirender menjadi email oleh worker
+type EmailEvent struct {
+	ID        string
+	Event     NotificationEvent
+	Attempts  int
+	CreatedAt time.Time
+}
+
+type Email struct {
+	ID        string    `json:"id"`
+	UserID    *string   `json:"user_id"`
+	To        string    `json:"to_address"`
+	EventType string    `json:"event_type"`
+	Subject   string    `json:"subject"`
+	Text      string    `json:"-"`
+	HTML      string    `json:"-"`
+	Status    string    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Penerima email beserta bahasa pilihannya
+type EmailRecipient struct {
+	UserID   string
+	Email    string
+	FullName string
+	Locale   string
+}
+
+// Prestasi mahasiswa bimbingan yang menunggu verifikasi (isi ringkasan harian)
+type PendingVerification struct {
+	AchievementRefID string
+	StudentName      string
+	StudentNIM       string
+	SubmittedAt      time.Time
+}
@@ -0,0 +1,26 @@
+package postgres
+
+// Status 2FA user (secret tidak pernah dikirim ke JSON)
+type TOTPState struct {
+	Secret                 string `json:"-"`
+	Enabled                bool   `json:"enabled"`
+	LastStep               int64  `json:"-"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining"`
+}
+
+// Request Body langkah kedua login
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // Kode TOTP 6 digit atau recovery code
+}
+
+// Request Body untuk aktivasi / regenerate recovery code
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// Request Body untuk menonaktifkan 2FA
+type DisableTOTPRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Jenis event notifikasi
+const (
+	NotifAchievementSubmitted     = "achievement_submitted"      // Ke dosen wali
+	NotifAchievementVerified      = "achievement_verified"       // Ke mahasiswa
+	NotifAchievementRejected      = "achievement_rejected"       // Ke mahasiswa
+	NotifAchievementNeedsRevision = "achievement_needs_revision" // Ke mahasiswa
+	NotifCommentCreated           = "comment_created"            // Ke pihak lain dalam diskusi
+	NotifCommentMention           = "comment_mention"            // Ke user yang di-mention
+	NotifAdvisorChanged           = "advisor_changed"            // Ke mahasiswa, dosen wali baru & lama
+)
+
+// Semua jenis event yang bisa diatur di preferensi (urutan tampilan)
+var NotificationEventTypes = []string{
+	NotifAchievementSubmitted, NotifAchievementVerified, NotifAchievementRejected, NotifAchievementNeedsRevision,
+	NotifCommentCreated, NotifCommentMention, NotifAdvisorChanged,
+}
+
+type Notification struct {
+	ID               string          `json:"id"`
+	UserID           string          `json:"user_id"`
+	EventType        string          `json:"event_type"`
+	Title            string          `json:"title"`
+	Message          string          `json:"message"`
+	AchievementRefID *string         `json:"achievement_ref_id"`
+	ActorID          *string         `json:"actor_id"`
+	ActorName        *string         `json:"actor_name,omitempty"` // Untuk join query
+	Data             json.RawMessage `json:"data" swaggertype:"object"`
+	ReadAt           *time.Time      `json:"read_at"`
+	CreatedAt        time.Time       `json:"created_at"`
+}
+
+// Event yang dipublikasikan service; penerima ditentukan dari jenis event.
+// Disimpan sebagai payload email_events untuk dirender worker email
+type NotificationEvent struct {
+	Type             string   `json:"type"`
+	ActorID          string   `json:"actor_id,omitempty"`
+	AchievementRefID string   `json:"achievement_ref_id,omitempty"` // Event prestasi & komentar
+	StudentID        string   `json:"student_id,omitempty"`         // advisor_changed
+	CommentID        string   `json:"comment_id,omitempty"`         // Event komentar
+	Note             string   `json:"note,omitempty"`               // Catatan review / cuplikan komentar
+	MentionedUserIDs []string `json:"mentioned_user_ids,omitempty"` // comment_mention
+
+	PreviousAdvisorUserID string `json:"previous_advisor_user_id,omitempty"` // advisor_changed: dosen wali lama
+}
+
+// Mahasiswa pemilik & dosen walinya (user ID; kosong jika tidak ada)
+type NotificationParticipants struct {
+	StudentUserID string
+	AdvisorUserID string
+	StudentName   string // Untuk isi email
+	StudentNIM    string
+}
+
+type NotificationPreference struct {
+	EventType string `json:"event_type"`
+	InApp     bool   `json:"in_app"`
+	Email     *bool  `json:"email,omitempty"` // Hanya untuk jenis event yang punya email
+}
+
+// Pengaturan email per user
+type NotificationSettings struct {
+	Locale      string `json:"locale"`       // Bahasa email: id, en
+	EmailDigest bool   `json:"email_digest"` // Dosen Wali: pengajuan baru dirangkum harian, bukan per email
+}
+
+// Payload tandai dibaca
+type MarkNotificationsRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// Payload ubah preferensi: { "preferences": { "comment_created": false }, "email": { "achievement_verified": false }, "locale": "en", "email_digest": true }
+type NotificationPreferencesRequest struct {
+	Preferences map[string]bool `json:"preferences"`
+	Email       map[string]bool `json:"email"`
+	Locale      string          `json:"locale"`
+	EmailDigest *bool           `json:"email_digest"`
+}
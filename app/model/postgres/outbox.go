@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status event outbox
+const (
+	OutboxPending    = "pending"
+	OutboxProcessing = "processing" // Sedang diklaim worker (lease habis -> bisa diklaim ulang)
+	OutboxDone       = "done"
+	OutboxFailed     = "failed" // Gagal permanen setelah batas retry, diperbaiki oleh rekonsiliasi
+)
+
+// Jenis event outbox achievement
+const (
+	EventAchievementSoftDelete = "achievement.soft_delete"
+	EventAttachmentFileDelete  = "attachment.file_delete" // GC file lampiran yang dihapus / diganti
+)
+
+type OutboxEvent struct {
+	ID          string          `json:"id"`
+	AggregateID string          `json:"aggregate_id"` // achievement_references.id
+	EventType   string          `json:"event_type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// Payload untuk event yang menyentuh dokumen MongoDB
+type MongoDocumentPayload struct {
+	MongoID string `json:"mongo_id"`
+}
+
+// Payload untuk event yang menghapus file di storage lampiran
+type StoredFilePayload struct {
+	StorageKey string `json:"storage_key"`
+}
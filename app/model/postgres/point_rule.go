@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Satu versi aturan poin (format rules: lihat package scoring)
+type PointRuleSet struct {
+	Version     int             `json:"version"`
+	Description string          `json:"description"`
+	Rules       json.RawMessage `json:"rules" swaggertype:"object"`
+	IsActive    bool            `json:"is_active"`
+	CreatedBy   *string         `json:"created_by"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ActivatedAt *time.Time      `json:"activated_at"`
+}
+
+// Poin yang dibekukan ke reference saat verifikasi
+type PointsSnapshot struct {
+	Points      int
+	RuleVersion int // 0 = belum ada aturan aktif (poin default jenis)
+}
+
+type PreviewPointsRequest struct {
+	AchievementType string                 `json:"achievementType"`
+	Details         map[string]interface{} `json:"details"`
+	Version         int                    `json:"version"` // 0 = aturan aktif
+}
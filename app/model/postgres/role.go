@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"time"
+)
+
+type Role struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Require2FA  bool      `json:"require_2fa"` // User dengan role ini wajib mengaktifkan TOTP
+	CreatedAt   time.Time `json:"created_at"`
+	Permissions []string  `json:"permissions"`
+}
+
+// Payload ubah role. require_2fa yang tidak dikirim tidak mengubah policy yang ada
+type UpdateRoleRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Require2FA  *bool  `json:"require_2fa"`
+}
+
+type Permission struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"` // Format: resource:action, contoh achievement:verify
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+	Description string `json:"description"`
+}
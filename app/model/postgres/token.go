@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"time"
+)
+
+// Status sesi user untuk validasi token di middleware
+type SessionState struct {
+	IsActive     bool
+	TokenVersion int
+	TokenRevoked bool // jti ada di denylist
+}
+
+// Refresh token (yang disimpan hanya hash-nya)
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string // Satu family = satu rantai rotasi sejak login
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// Token reset password dari admin (sekali pakai, yang disimpan hanya hash-nya)
+type PasswordResetToken struct {
+	ID        string
+	UserID    string
+	CreatedBy string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Request Body untuk refresh token / logout
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
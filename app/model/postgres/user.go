@@ -5,17 +5,23 @@ import (
 )
 
 type User struct {
-	ID           string    `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"password_hash"` // Tidak dikirim ke JSON response
-	FullName     string    `json:"full_name"`
-	RoleID       string    `json:"role_id"`
-	RoleName     string    `json:"role_name,omitempty"` // Untuk join query
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Permissions  []string  `json:"permissions"`
+	ID                  string     `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	PasswordHash        string     `json:"password_hash"` // Tidak dikirim ke JSON response
+	FullName            string     `json:"full_name"`
+	RoleID              string     `json:"role_id"`
+	RoleName            string     `json:"role_name,omitempty"` // Untuk join query
+	IsActive            bool       `json:"is_active"`
+	TokenVersion        int        `json:"-"` // Dinaikkan saat semua sesi dicabut
+	FailedLoginAttempts int        `json:"failed_login_attempts"`
+	LockedUntil         *time.Time `json:"locked_until"` // Terisi selama akun dikunci
+	MustChangePassword  bool       `json:"must_change_password"`
+	TOTPEnabled         bool       `json:"totp_enabled"`
+	Require2FA          bool       `json:"require_2fa"` // Dari policy role
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	Permissions         []string   `json:"permissions"`
 }
 
 // Request Body untuk Login
@@ -24,6 +30,18 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// Request Body untuk ganti password sendiri
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// Request Body untuk set password baru memakai token reset dari admin
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
 type SeedRequest struct {
 	RoleID string `json:"role_id"`
 }
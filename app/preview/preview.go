@@ -0,0 +1,201 @@
+package preview
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Ukuran sisi terpanjang (px)
+const (
+	ThumbnailSize = 320
+	PreviewSize   = 1280
+	maxPixels     = 50_000_000 // Tolak gambar raksasa (decompression bomb)
+	jpegQuality   = 80
+)
+
+var (
+	ErrUnsupported = errors.New("preview: unsupported file type")
+	ErrInvalid     = errors.New("preview: file cannot be rendered")
+)
+
+// Render halaman pertama PDF menjadi gambar (sisi terpanjang maks maxSize)
+type PDFRenderer interface {
+	FirstPage(ctx context.Context, pdf []byte, maxSize int) (image.Image, error)
+}
+
+// Hasil render dalam format JPEG
+type Result struct {
+	Thumbnail []byte
+	Preview   []byte
+}
+
+type Generator struct {
+	PDF PDFRenderer // nil = preview PDF tidak didukung
+}
+
+// Renderer PDF dari env: PDFTOPPM_PATH, atau pdftoppm di PATH jika ada
+func FromEnv() *Generator {
+	bin := os.Getenv("PDFTOPPM_PATH")
+	if bin == "" {
+		found, err := exec.LookPath("pdftoppm")
+		if err != nil {
+			log.Println("pdftoppm not found, PDF previews are disabled")
+			return &Generator{}
+		}
+		bin = found
+	}
+	return &Generator{PDF: Pdftoppm{Bin: bin}}
+}
+
+// Render membuat thumbnail & preview dari isi file (contentType hasil sniffing saat upload)
+func (g *Generator) Render(ctx context.Context, content []byte, contentType string) (*Result, error) {
+	var src image.Image
+	switch contentType {
+	case "image/png", "image/jpeg", "image/webp":
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		if cfg.Width*cfg.Height > maxPixels {
+			return nil, fmt.Errorf("%w: image too large (%dx%d)", ErrInvalid, cfg.Width, cfg.Height)
+		}
+		if src, _, err = image.Decode(bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	case "application/pdf":
+		if g.PDF == nil {
+			return nil, ErrUnsupported
+		}
+		page, err := g.PDF.FirstPage(ctx, content, PreviewSize)
+		if err != nil {
+			return nil, err
+		}
+		src = page
+	default:
+		return nil, ErrUnsupported
+	}
+
+	full := Fit(src, PreviewSize)
+	res := &Result{}
+	var err error
+	if res.Preview, err = encode(full); err != nil {
+		return nil, err
+	}
+	if res.Thumbnail, err = encode(Fit(full, ThumbnailSize)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func encode(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Fit mengecilkan gambar (box filter) agar sisi terpanjang <= maxSize.
+// Transparansi diratakan ke latar putih karena output JPEG
+func Fit(src image.Image, maxSize int) *image.RGBA {
+	b := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, b.Min, draw.Over)
+
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return flat
+	}
+	dw, dh := maxSize, h*maxSize/w
+	if h > w {
+		dw, dh = w*maxSize/h, maxSize
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, (y+1)*h/dh
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, (x+1)*w/dw
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+			var r, g, bl, n int
+			for sy := y0; sy < y1; sy++ {
+				row := flat.Pix[sy*flat.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+3]
+					r, g, bl = r+int(p[0]), g+int(p[1]), bl+int(p[2])
+					n++
+				}
+			}
+			i := y*dst.Stride + x*4
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(bl/n), 255
+		}
+	}
+	return dst
+}
+
+// Renderer PDF memakai pdftoppm (poppler-utils)
+type Pdftoppm struct {
+	Bin string
+}
+
+func (p Pdftoppm) FirstPage(ctx context.Context, pdf []byte, maxSize int) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "preview-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.pdf")
+	if err := os.WriteFile(in, pdf, 0o600); err != nil {
+		return nil, err
+	}
+	out := filepath.Join(dir, "page")
+	cmd := exec.CommandContext(ctx, p.Bin, "-f", "1", "-l", "1", "-singlefile", "-png",
+		"-scale-to", fmt.Sprint(maxSize), in, out)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if ctx.Err() != nil || !errors.As(err, &exit) {
+			return nil, fmt.Errorf("pdftoppm: %w", err) // Bukan kesalahan file, dicoba lagi
+		}
+		return nil, fmt.Errorf("%w: pdftoppm: %v %s", ErrInvalid, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	f, err := os.Open(out + ".png")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(io.LimitReader(f, 64<<20))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return img, nil
+}
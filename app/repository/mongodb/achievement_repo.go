@@ -1,24 +1,58 @@
 package mongodb
 
 import (
+	"be_uas/app/listing"
 	"be_uas/app/model/mongodb"
 	"context"
+	"errors"
 	"time"
 	"fmt"
+	"strconv"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type IAchievementRepoMongo interface {
 	InsertAchievement(ctx context.Context, data mongodb.Achievement) (string, error)
 	SoftDeleteAchievement(ctx context.Context, hexID string) error
 	FindAchievementByID(ctx context.Context, hexID string) (*mongodb.Achievement, error)
+	FindAchievementsByIDs(ctx context.Context, hexIDs []string) (map[string]*mongodb.Achievement, error)
+
+	// Listing: filter konten (tipe, tag, poin) & sort berdasarkan field konten
+	FindIDsByFilter(ctx context.Context, f listing.Filter) ([]string, error)
+	QueryAchievements(ctx context.Context, q listing.Query, hexIDs []string) ([]mongodb.Achievement, string, error)
+
+	// Pencarian full-text (hexIDs nil = tanpa batasan scope)
+	SearchAchievements(ctx context.Context, text string, hexIDs []string, skip, limit int) ([]mongodb.AchievementSearchHit, error)
 	UpdateAchievement(ctx context.Context, hexID string, data mongodb.Achievement) error
 	AddAttachment(ctx context.Context, hexID string, attachment mongodb.Attachment) error
+	RemoveAttachment(ctx context.Context, hexID, attachmentID string) error
+	ReplaceAttachment(ctx context.Context, hexID, attachmentID string, attachment mongodb.Attachment) error
+
+	// Scan antivirus lampiran (worker)
+	FindPendingScans(ctx context.Context, limit int) ([]mongodb.PendingAttachment, error)
+	SetScanResult(ctx context.Context, hexID, attachmentID, status, signature, storageKey string) error
+	RecordScanFailure(ctx context.Context, hexID, attachmentID, lastError string, nextAttemptAt time.Time, dead bool) error
+
+	// Thumbnail & preview lampiran (worker, hanya untuk lampiran yang sudah lolos scan)
+	FindPendingPreviews(ctx context.Context, limit int) ([]mongodb.PendingAttachment, error)
+	SetPreview(ctx context.Context, hexID, attachmentID string, preview mongodb.AttachmentPreview) error
+
+	// Hitung ulang poin (aturan berubah / verifikasi), updatedAt tidak disentuh
+	SetPoints(ctx context.Context, hexID string, points, ruleVersion int) error
+
+	// Kompensasi & rekonsiliasi dengan PostgreSQL
+	DeleteAchievement(ctx context.Context, hexID string) error
+	RestoreAchievement(ctx context.Context, hexID string) error
+	FindDeletionStates(ctx context.Context, hexIDs []string) (map[string]bool, error)
+	ListActiveIDsBefore(ctx context.Context, cutoff time.Time, afterHexID string, limit int) ([]string, error)
 }
 
+var ErrAttachmentNotFound = errors.New("attachment not found")
+
 type AchievementRepoMongo struct {
 	Collection *mongo.Collection
 }
@@ -53,6 +87,31 @@ func (r *AchievementRepoMongo) FindAchievementByID(ctx context.Context, hexID st
 	return &achievement, nil
 }
 
+// Ambil banyak detail sekaligus dengan satu query $in. Hasil di-key per hex ID,
+// urutan dikembalikan oleh pemanggil. ID yang tidak ditemukan tidak ada di map
+func (r *AchievementRepoMongo) FindAchievementsByIDs(ctx context.Context, hexIDs []string) (map[string]*mongodb.Achievement, error) {
+	found := make(map[string]*mongodb.Achievement)
+	objIDs := toObjectIDs(hexIDs)
+	if len(objIDs) == 0 {
+		return found, nil
+	}
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var achievement mongodb.Achievement
+		if err := cursor.Decode(&achievement); err != nil {
+			return nil, err
+		}
+		found[achievement.ID.Hex()] = &achievement
+	}
+	return found, cursor.Err()
+}
+
 func (r *AchievementRepoMongo) UpdateAchievement(ctx context.Context, hexID string, data mongodb.Achievement) error {
     objID, _ := primitive.ObjectIDFromHex(hexID)
     
@@ -64,6 +123,7 @@ func (r *AchievementRepoMongo) UpdateAchievement(ctx context.Context, hexID stri
         "details":         data.Details,
         "tags":            data.Tags,
         "points":          data.Points,
+        "pointsRuleVersion": data.PointsRuleVersion,
         "updatedAt":       data.UpdatedAt,
     }}
     
@@ -96,4 +156,450 @@ func (r *AchievementRepoMongo) AddAttachment(ctx context.Context, hexID string,
     }
 
     return nil
-}
\ No newline at end of file
+}
+
+// ErrAttachmentNotFound jika dokumen tidak punya lampiran dengan ID tersebut
+func (r *AchievementRepoMongo) RemoveAttachment(ctx context.Context, hexID, attachmentID string) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	filter := bson.M{"_id": objID, "attachments.id": attachmentID}
+	update := bson.M{"$pull": bson.M{"attachments": bson.M{"id": attachmentID}}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// Ganti satu elemen attachments di posisinya (urutan lampiran tetap)
+func (r *AchievementRepoMongo) ReplaceAttachment(ctx context.Context, hexID, attachmentID string, attachment mongodb.Attachment) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	filter := bson.M{"_id": objID, "attachments.id": attachmentID}
+	update := bson.M{"$set": bson.M{"attachments.$": attachment}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// Lampiran berstatus pending dari dokumen aktif, yang paling lama diunggah lebih dulu
+func (r *AchievementRepoMongo) FindPendingScans(ctx context.Context, limit int) ([]mongodb.PendingAttachment, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"attachments.scanStatus": mongodb.ScanPending, "deletedAt": nil}}},
+		{{Key: "$unwind", Value: "$attachments"}},
+		{{Key: "$match", Value: bson.M{
+			"attachments.scanStatus": mongodb.ScanPending,
+			// Yang gagal sebelumnya baru diambil lagi setelah backoff-nya lewat
+			"$or": bson.A{
+				bson.M{"attachments.scanNextAttemptAt": nil},
+				bson.M{"attachments.scanNextAttemptAt": bson.M{"$lte": time.Now()}},
+			},
+		}}},
+		{{Key: "$sort", Value: bson.M{"attachments.uploadedAt": 1}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 1, "attachment": "$attachments"}}},
+	}
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var pending []mongodb.PendingAttachment
+	if err := cursor.All(ctx, &pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// Simpan hasil scan. Hanya lampiran yang masih pending yang diubah (ErrAttachmentNotFound jika
+// lampiran sudah dihapus / diganti selama scan berjalan)
+func (r *AchievementRepoMongo) SetScanResult(ctx context.Context, hexID, attachmentID, status, signature, storageKey string) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	filter := bson.M{
+		"_id":         objID,
+		"attachments": bson.M{"$elemMatch": bson.M{"id": attachmentID, "scanStatus": mongodb.ScanPending}},
+	}
+	set := bson.M{
+		"attachments.$.scanStatus": status,
+		"attachments.$.scannedAt":  time.Now(),
+		"attachments.$.storageKey": storageKey,
+	}
+	if signature != "" {
+		set["attachments.$.scanSignature"] = signature
+	}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// Catat scan yang gagal: dijadwalkan ulang pada nextAttemptAt, atau ScanFailed jika dead.
+// Hanya lampiran yang masih pending yang diubah (ErrAttachmentNotFound jika tidak ada)
+func (r *AchievementRepoMongo) RecordScanFailure(ctx context.Context, hexID, attachmentID, lastError string, nextAttemptAt time.Time, dead bool) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	filter := bson.M{
+		"_id":         objID,
+		"attachments": bson.M{"$elemMatch": bson.M{"id": attachmentID, "scanStatus": mongodb.ScanPending}},
+	}
+	set := bson.M{
+		"attachments.$.scanError":         lastError,
+		"attachments.$.scanNextAttemptAt": nextAttemptAt,
+	}
+	if dead {
+		set["attachments.$.scanStatus"] = mongodb.ScanFailed
+		set["attachments.$.scannedAt"] = time.Now()
+	}
+	update := bson.M{"$set": set, "$inc": bson.M{"attachments.$.scanAttempts": 1}}
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+// Lampiran bersih yang preview-nya belum dibuat
+func (r *AchievementRepoMongo) FindPendingPreviews(ctx context.Context, limit int) ([]mongodb.PendingAttachment, error) {
+	match := bson.M{"attachments.scanStatus": mongodb.ScanClean, "attachments.preview.status": mongodb.PreviewPending}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"attachments": bson.M{"$elemMatch": bson.M{"scanStatus": mongodb.ScanClean, "preview.status": mongodb.PreviewPending}},
+			"deletedAt":   nil,
+		}}},
+		{{Key: "$unwind", Value: "$attachments"}},
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.M{"attachments.uploadedAt": 1}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{"_id": 1, "attachment": "$attachments"}}},
+	}
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var pending []mongodb.PendingAttachment
+	if err := cursor.All(ctx, &pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// Simpan hasil preview, hanya jika lampiran masih ada dan preview-nya masih pending
+func (r *AchievementRepoMongo) SetPreview(ctx context.Context, hexID, attachmentID string, preview mongodb.AttachmentPreview) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	filter := bson.M{
+		"_id":         objID,
+		"attachments": bson.M{"$elemMatch": bson.M{"id": attachmentID, "preview.status": mongodb.PreviewPending}},
+	}
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"attachments.$.preview": preview}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
+
+func (r *AchievementRepoMongo) SetPoints(ctx context.Context, hexID string, points, ruleVersion int) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	update := bson.M{"$set": bson.M{"points": points, "pointsRuleVersion": ruleVersion}}
+	_, err = r.Collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	return err
+}
+
+// Hapus permanen (kompensasi jika reference di PostgreSQL gagal dibuat)
+func (r *AchievementRepoMongo) DeleteAchievement(ctx context.Context, hexID string) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	_, err = r.Collection.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+// Batalkan soft delete (PostgreSQL adalah sumber kebenaran status)
+func (r *AchievementRepoMongo) RestoreAchievement(ctx context.Context, hexID string) error {
+	objID, err := primitive.ObjectIDFromHex(hexID)
+	if err != nil {
+		return fmt.Errorf("invalid mongodb object id format: %s", hexID)
+	}
+	_, err = r.Collection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$unset": bson.M{"deletedAt": ""}})
+	return err
+}
+
+// Map id -> sudah di-soft-delete atau belum. ID yang tidak ada di map berarti dokumennya tidak ada
+func (r *AchievementRepoMongo) FindDeletionStates(ctx context.Context, hexIDs []string) (map[string]bool, error) {
+	states := make(map[string]bool)
+	objIDs := toObjectIDs(hexIDs)
+	if len(objIDs) == 0 {
+		return states, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "deletedAt": 1})
+	cursor, err := r.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID        primitive.ObjectID `bson:"_id"`
+			DeletedAt *time.Time         `bson:"deletedAt"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		states[doc.ID.Hex()] = doc.DeletedAt != nil
+	}
+	return states, cursor.Err()
+}
+
+// ID dokumen aktif (belum dihapus) yang dibuat sebelum cutoff, urut per _id untuk paging
+func (r *AchievementRepoMongo) ListActiveIDsBefore(ctx context.Context, cutoff time.Time, afterHexID string, limit int) ([]string, error) {
+	filter := bson.M{
+		"createdAt": bson.M{"$lt": cutoff},
+		"deletedAt": bson.M{"$eq": nil},
+	}
+	if afterHexID != "" {
+		after, err := primitive.ObjectIDFromHex(afterHexID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mongodb object id format: %s", afterHexID)
+		}
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"_id": 1}).
+		SetSort(bson.M{"_id": 1}).
+		SetLimit(int64(limit))
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+	return ids, cursor.Err()
+}
+
+// Filter sisi MongoDB dari query listing
+func contentFilter(f listing.Filter) bson.M {
+	filter := bson.M{}
+	if f.AchievementType != "" {
+		filter["achievementType"] = f.AchievementType
+	}
+	if f.Tag != "" {
+		filter["tags"] = f.Tag
+	}
+	points := bson.M{}
+	if f.MinPoints != nil {
+		points["$gte"] = *f.MinPoints
+	}
+	if f.MaxPoints != nil {
+		points["$lte"] = *f.MaxPoints
+	}
+	if len(points) > 0 {
+		filter["points"] = points
+	}
+	return filter
+}
+
+func toObjectIDs(hexIDs []string) []primitive.ObjectID {
+	objIDs := make([]primitive.ObjectID, 0, len(hexIDs))
+	for _, h := range hexIDs {
+		if objID, err := primitive.ObjectIDFromHex(h); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+	return objIDs
+}
+
+// Hex ID semua dokumen yang cocok dengan filter konten
+func (r *AchievementRepoMongo) FindIDsByFilter(ctx context.Context, f listing.Filter) ([]string, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.Collection.Find(ctx, contentFilter(f), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := []string{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID.Hex())
+	}
+	return ids, cursor.Err()
+}
+
+// Satu halaman dokumen (dibatasi ke hexIDs hasil filter PostgreSQL; nil = semua dokumen),
+// urut berdasarkan poin / judul. Return cursor halaman berikutnya (kosong jika sudah halaman terakhir)
+func (r *AchievementRepoMongo) QueryAchievements(ctx context.Context, q listing.Query, hexIDs []string) ([]mongodb.Achievement, string, error) {
+	field := "points"
+	if q.SortBy == listing.SortTitle {
+		field = "title"
+	}
+	dir, cmp := 1, "$gt"
+	if q.Desc {
+		dir, cmp = -1, "$lt"
+	}
+
+	filter := contentFilter(q.Filter)
+	if hexIDs != nil {
+		filter["_id"] = bson.M{"$in": toObjectIDs(hexIDs)}
+	}
+	if q.After != nil {
+		afterID, err := primitive.ObjectIDFromHex(q.After.ID)
+		if err != nil {
+			return nil, "", listing.ErrInvalidCursor
+		}
+		var value interface{} = q.After.Value
+		if field == "points" {
+			n, err := strconv.Atoi(q.After.Value)
+			if err != nil {
+				return nil, "", listing.ErrInvalidCursor
+			}
+			value = n
+		}
+		filter["$or"] = bson.A{
+			bson.M{field: bson.M{cmp: value}},
+			bson.M{field: value, "_id": bson.M{cmp: afterID}},
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: field, Value: dir}, {Key: "_id", Value: dir}}).
+		SetLimit(int64(q.Limit + 1))
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []mongodb.Achievement
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, "", err
+	}
+
+	if len(docs) <= q.Limit {
+		return docs, "", nil
+	}
+	docs = docs[:q.Limit]
+	return docs, SortCursor(q, docs[len(docs)-1]).Encode(), nil
+}
+
+// Cursor keyset setelah dokumen tertentu untuk sort field MongoDB
+func SortCursor(q listing.Query, doc mongodb.Achievement) listing.Cursor {
+	value := doc.Title
+	if q.SortBy == listing.SortPoints {
+		value = strconv.Itoa(doc.Points)
+	}
+	return listing.Cursor{SortBy: q.SortBy, Desc: q.Desc, Value: value, ID: doc.ID.Hex()}
+}
+
+// Field details yang ikut diindeks untuk pencarian full-text
+var SearchableDetailFields = []string{
+	"competitionName", "organizer", "eventName", "publicationTitle", "publisher",
+	"organizationName", "position", "certificationName", "issuedBy", "location",
+}
+
+// Bobot relevansi: judul paling penting, lalu tag, deskripsi, dan details
+func searchIndexKeys() (bson.D, bson.M) {
+	keys := bson.D{{Key: "title", Value: "text"}, {Key: "tags", Value: "text"}, {Key: "description", Value: "text"}}
+	weights := bson.M{"title": 10, "tags": 5, "description": 2}
+	for _, f := range SearchableDetailFields {
+		keys = append(keys, bson.E{Key: "details." + f, Value: "text"})
+		weights["details."+f] = 1
+	}
+	return keys, weights
+}
+
+// Buat index pencarian full-text (MongoDB hanya mengizinkan satu text index per collection)
+func EnsureAchievementIndexes(ctx context.Context, db *mongo.Database) error {
+	keys, weights := searchIndexKeys()
+	model := mongo.IndexModel{
+		Keys: keys,
+		Options: options.Index().
+			SetName("achievement_text_search").
+			SetWeights(weights).
+			SetDefaultLanguage("none"), // Teks campuran Indonesia / Inggris: tanpa stemming & stop words
+	}
+	_, err := db.Collection("achievements").Indexes().CreateOne(ctx, model)
+	return err
+}
+
+// Cari dokumen aktif dengan $text, urut berdasarkan skor relevansi
+func (r *AchievementRepoMongo) SearchAchievements(ctx context.Context, text string, hexIDs []string, skip, limit int) ([]mongodb.AchievementSearchHit, error) {
+	filter := bson.M{
+		"$text":     bson.M{"$search": text},
+		"deletedAt": bson.M{"$eq": nil},
+	}
+	if hexIDs != nil {
+		filter["_id"] = bson.M{"$in": toObjectIDs(hexIDs)}
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(skip)).
+		SetLimit(int64(limit))
+	cursor, err := r.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	hits := []mongodb.AchievementSearchHit{}
+	if err := cursor.All(ctx, &hits); err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
@@ -1,13 +1,14 @@
 package postgres
 
 import (
+	"be_uas/app/model/postgres"
 	"database/sql"
 )
 
 type IAcademicRepoPG interface {
 	GetAllStudents() ([]map[string]interface{}, error)
 	GetStudentByID(id string) (map[string]interface{}, error)
-	UpdateStudentAdvisor(studentID, advisorID string) error
+	UpdateStudentAdvisor(studentID, advisorID, actorID string) (*postgres.NotificationEvent, error)
 	
 	GetAllLecturers() ([]map[string]interface{}, error)
 	GetLecturerAdvisees(lecturerID string) ([]map[string]interface{}, error)
@@ -65,9 +66,41 @@ func (r *AcademicRepoPG) GetStudentByID(id string) (map[string]interface{}, erro
 	}, nil
 }
 
-func (r *AcademicRepoPG) UpdateStudentAdvisor(studentID, advisorID string) error {
-	_, err := r.DB.Exec("UPDATE students SET advisor_id = $1 WHERE id = $2", advisorID, studentID)
-	return err
+// Ganti dosen wali; event pergantian (beserta dosen wali lama) ditulis sebagai event email di
+// transaksi yang sama. nil jika dosen wali tidak berubah, sql.ErrNoRows jika mahasiswa tidak ada
+func (r *AcademicRepoPG) UpdateStudentAdvisor(studentID, advisorID, actorID string) (*postgres.NotificationEvent, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT s.advisor_id, l.user_id
+		FROM students s
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		WHERE s.id = $1
+		FOR UPDATE OF s
+	`
+	var prevAdvisorID, prevAdvisorUserID sql.NullString
+	if err := tx.QueryRow(query, studentID).Scan(&prevAdvisorID, &prevAdvisorUserID); err != nil {
+		return nil, err
+	}
+	if prevAdvisorID.Valid && prevAdvisorID.String == advisorID {
+		return nil, nil
+	}
+
+	if _, err := tx.Exec("UPDATE students SET advisor_id = $1 WHERE id = $2", advisorID, studentID); err != nil {
+		return nil, err
+	}
+	ev := postgres.NotificationEvent{
+		Type: postgres.NotifAdvisorChanged, ActorID: actorID, StudentID: studentID,
+		PreviousAdvisorUserID: prevAdvisorUserID.String,
+	}
+	if err := insertEmailEvent(tx, ev); err != nil {
+		return nil, err
+	}
+	return &ev, tx.Commit()
 }
 
 func (r *AcademicRepoPG) GetAllLecturers() ([]map[string]interface{}, error) {
@@ -1,25 +1,53 @@
 package postgres
 
 import (
+	"be_uas/app/listing"
 	"be_uas/app/model/postgres"
 	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type IAchievementRepoPG interface {
-	CreateReference(ref postgres.AchievementReference) error
+	CreateReference(ref postgres.AchievementReference, actorID string) error
 	GetReferenceByID(id string) (*postgres.AchievementReference, error)
-	UpdateStatus(id string, status string) error
+	UpdateStatus(id, fromStatus, status, actorID string, check func() error) error
 	GetStudentIDByUserID(userID string) (string, error)
-	GetAchievementsByAdvisorID(userID string) ([]postgres.AchievementReference, error)
-	UpdateVerification(id string, status string, verifiedBy string, rejectionNote *string) error
-	GetAllAchievements(limit, offset int) ([]postgres.AchievementReference, int, error)
-	GetAchievementsByStudentID(studentID string) ([]postgres.AchievementReference, error)
+	GetLecturerIDByUserID(userID string) (string, error)
+	IsStudentAdvisedBy(studentID, userID string) (bool, error)
+	UpdateVerification(id, fromStatus, status, verifiedBy string, rejectionNote *string, points *postgres.PointsSnapshot) error
+	LockForEdit(id, status string, edit func() error) error
+	QueryAchievements(q listing.Query) ([]postgres.AchievementReference, string, error)
+	FindReferencesByFilter(f listing.Filter) ([]postgres.AchievementReference, error)
+	FindReferencesByFilterLimit(f listing.Filter, limit int) ([]postgres.AchievementReference, error)
+	GetStatusHistory(refID string, limit, offset int) ([]postgres.AchievementStatusHistory, int, error)
+	RequestRevision(id, fromStatus, reviewerID string, note *string, comments []postgres.RevisionCommentInput) error
+	GetRevisionComments(refID string) ([]postgres.RevisionComment, error)
+
+	// Rekonsiliasi PostgreSQL <-> MongoDB
+	ListReferenceLinks(afterID string, limit int) ([]postgres.AchievementReference, error)
+	FindReferencedMongoIDs(mongoIDs []string) (map[string]bool, error)
+	MarkReferenceDeleted(id, note string) error
 }
 
 type AchievementRepoPG struct {
 	DB *sql.DB
 }
 
+// Status reference sudah diubah request lain sejak dibaca handler
+type StatusConflictError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *StatusConflictError) Error() string {
+	return fmt.Sprintf("achievement status changed from %s to %s", e.Expected, e.Actual)
+}
+
 func NewAchievementRepoPG(db *sql.DB) IAchievementRepoPG {
 	return &AchievementRepoPG{DB: db}
 }
@@ -31,11 +59,45 @@ func (r *AchievementRepoPG) GetStudentIDByUserID(userID string) (string, error)
 	return studentID, err
 }
 
-func (r *AchievementRepoPG) CreateReference(ref postgres.AchievementReference) error {
+func (r *AchievementRepoPG) GetLecturerIDByUserID(userID string) (string, error) {
+	var lecturerID string
+	query := `SELECT id FROM lecturers WHERE user_id = $1`
+	err := r.DB.QueryRow(query, userID).Scan(&lecturerID)
+	return lecturerID, err
+}
+
+// Cek apakah user (dosen) adalah dosen wali dari mahasiswa tertentu
+func (r *AchievementRepoPG) IsStudentAdvisedBy(studentID, userID string) (bool, error) {
+	var ok bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM students s
+			JOIN lecturers l ON s.advisor_id = l.id
+			WHERE s.id = $1 AND l.user_id = $2
+		)
+	`
+	err := r.DB.QueryRow(query, studentID, userID).Scan(&ok)
+	return ok, err
+}
+
+func (r *AchievementRepoPG) CreateReference(ref postgres.AchievementReference, actorID string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `INSERT INTO achievement_references (id, student_id, mongo_achievement_id, status, created_at, updated_at) 
               VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.DB.Exec(query, ref.ID, ref.StudentID, ref.MongoAchievementID, ref.Status, ref.CreatedAt, ref.UpdatedAt)
-	return err
+	if _, err := tx.Exec(query, ref.ID, ref.StudentID, ref.MongoAchievementID, ref.Status, ref.CreatedAt, ref.UpdatedAt); err != nil {
+		return err
+	}
+
+	note := "Achievement created"
+	if err := insertStatusHistory(tx, ref.ID, actorID, nil, ref.Status, &note); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *AchievementRepoPG) GetReferenceByID(id string) (*postgres.AchievementReference, error) {
@@ -44,6 +106,7 @@ func (r *AchievementRepoPG) GetReferenceByID(id string) (*postgres.AchievementRe
 		SELECT 
 			id, student_id, mongo_achievement_id, status, 
 			submitted_at, verified_at, verified_by, rejection_note, 
+			verified_points, points_rule_version, review_round,
 			created_at, updated_at 
 		FROM achievement_references 
 		WHERE id = $1
@@ -58,6 +121,9 @@ func (r *AchievementRepoPG) GetReferenceByID(id string) (*postgres.AchievementRe
 		&ref.VerifiedAt,    
 		&ref.VerifiedBy, 
 		&ref.RejectionNote,
+		&ref.VerifiedPoints,
+		&ref.PointsRuleVersion,
+		&ref.ReviewRound,
 		&ref.CreatedAt, 
 		&ref.UpdatedAt,
 	)
@@ -68,98 +134,451 @@ func (r *AchievementRepoPG) GetReferenceByID(id string) (*postgres.AchievementRe
 	return ref, nil
 }
 
-func (r *AchievementRepoPG) UpdateStatus(id string, status string) error {
-	query := `UPDATE achievement_references SET status = $1, updated_at = NOW() WHERE id = $2`
-	_, err := r.DB.Exec(query, status, id)
-	return err
+// Ubah status jika status saat ini masih fromStatus (StatusConflictError jika sudah berubah).
+// check (opsional) dijalankan setelah reference dikunci; error darinya membatalkan transisi
+func (r *AchievementRepoPG) UpdateStatus(id, fromStatus, status, actorID string, check func() error) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockExpectedStatus(tx, id, fromStatus); err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE achievement_references 
+		SET status = $1::varchar, 
+			submitted_at = CASE WHEN $1::varchar = 'submitted' THEN NOW() ELSE submitted_at END, 
+			review_round = CASE WHEN $1::varchar = 'submitted' THEN review_round + 1 ELSE review_round END,
+			updated_at = NOW() 
+		WHERE id = $2
+	`
+	if _, err := tx.Exec(query, status, id); err != nil {
+		return err
+	}
+
+	if err := insertStatusHistory(tx, id, actorID, &fromStatus, status, nil); err != nil {
+		return err
+	}
+	if err := insertTransitionEmailEvent(tx, id, actorID, status, nil); err != nil {
+		return err
+	}
+
+	// Soft delete di MongoDB lewat outbox agar tetap terjadi walau Mongo sedang gagal
+	if status == "deleted" {
+		var mongoID string
+		if err := tx.QueryRow(`SELECT mongo_achievement_id FROM achievement_references WHERE id = $1`, id).Scan(&mongoID); err != nil {
+			return err
+		}
+		payload := postgres.MongoDocumentPayload{MongoID: mongoID}
+		if err := insertOutboxEvent(tx, id, postgres.EventAchievementSoftDelete, payload); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-func (r *AchievementRepoPG) GetAchievementsByAdvisorID(userID string) ([]postgres.AchievementReference, error) {
-	// Query: Cari Lecturer ID dari User ID -> Cari Student yang dibimbing -> Cari Prestasi
+// Update status Verify/Reject
+// points != nil membekukan poin (verifikasi); nil mengosongkannya (penolakan)
+func (r *AchievementRepoPG) UpdateVerification(id, fromStatus, status, verifiedBy string, rejectionNote *string, points *postgres.PointsSnapshot) error {
 	query := `
-		SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.created_at, ar.updated_at
-		FROM achievement_references ar
-		JOIN students s ON ar.student_id = s.id
-		JOIN lecturers l ON s.advisor_id = l.id
-		WHERE l.user_id = $1 AND ar.status != 'draft' AND ar.status != 'deleted'
-		ORDER BY ar.created_at DESC
+		UPDATE achievement_references 
+		SET status = $1, verified_by = $2, rejection_note = $3, verified_at = NOW(), updated_at = NOW(),
+			verified_points = $5, points_rule_version = $6
+		WHERE id = $4
 	`
-	rows, err := r.DB.Query(query, userID)
+	var frozenPoints, ruleVersion interface{}
+	if points != nil {
+		frozenPoints, ruleVersion = points.Points, points.RuleVersion
+	}
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockExpectedStatus(tx, id, fromStatus); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(query, status, verifiedBy, rejectionNote, id, frozenPoints, ruleVersion); err != nil {
+		return err
+	}
+
+	if err := insertStatusHistory(tx, id, verifiedBy, &fromStatus, status, rejectionNote); err != nil {
+		return err
+	}
+	if err := insertTransitionEmailEvent(tx, id, verifiedBy, status, rejectionNote); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Jalankan edit konten (MongoDB) sambil mengunci reference, hanya jika status masih sama.
+// Transisi status yang bersamaan menunggu sampai edit selesai
+func (r *AchievementRepoPG) LockForEdit(id, status string, edit func() error) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockExpectedStatus(tx, id, status); err != nil {
+		return err
+	}
+	if err := edit(); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE achievement_references SET updated_at = NOW() WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Catat event email untuk status tujuan tertentu, ikut commit / rollback bersama transisinya
+func insertTransitionEmailEvent(tx *sql.Tx, id, actorID, status string, note *string) error {
+	eventType, ok := postgres.TransitionEmailEvents[status]
+	if !ok {
+		return nil
+	}
+	ev := postgres.NotificationEvent{Type: eventType, ActorID: actorID, AchievementRefID: id}
+	if note != nil {
+		ev.Note = strings.TrimSpace(*note)
+	}
+	return insertEmailEvent(tx, ev)
+}
+
+// Riwayat transisi status (urut dari yang paling lama)
+func (r *AchievementRepoPG) GetStatusHistory(refID string, limit, offset int) ([]postgres.AchievementStatusHistory, int, error) {
+	query := `
+		SELECT h.id, h.achievement_ref_id, h.actor_id, u.full_name, h.from_status, h.to_status, h.note, COALESCE(h.round, 0), h.created_at
+		FROM achievement_status_history h
+		LEFT JOIN users u ON h.actor_id = u.id
+		WHERE h.achievement_ref_id = $1
+		ORDER BY h.created_at ASC, h.id ASC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.DB.Query(query, refID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var history []postgres.AchievementStatusHistory
+	for rows.Next() {
+		var h postgres.AchievementStatusHistory
+		if err := rows.Scan(&h.ID, &h.AchievementRefID, &h.ActorID, &h.ActorName, &h.FromStatus, &h.ToStatus, &h.Note, &h.Round, &h.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		history = append(history, h)
+	}
+
+	var total int
+	if err := r.DB.QueryRow("SELECT COUNT(*) FROM achievement_status_history WHERE achievement_ref_id = $1", refID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	return history, total, nil
+}
+
+// Kembalikan ke mahasiswa dengan komentar per field (status, history & komentar dalam satu transaksi).
+// Komentar dicatat pada ronde review yang sedang berjalan. Reviewer & waktunya tercatat di history
+// dan komentar revisi; verified_by / verified_at hanya untuk hasil akhir (verify / reject)
+func (r *AchievementRepoPG) RequestRevision(id, fromStatus, reviewerID string, note *string, comments []postgres.RevisionCommentInput) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockExpectedStatus(tx, id, fromStatus); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE achievement_references SET status = 'needs_revision', updated_at = NOW() WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if err := insertStatusHistory(tx, id, reviewerID, &fromStatus, "needs_revision", note); err != nil {
+		return err
+	}
+	if err := insertTransitionEmailEvent(tx, id, reviewerID, "needs_revision", note); err != nil {
+		return err
+	}
+
+	insert := `
+		INSERT INTO achievement_revision_comments (achievement_ref_id, round, field, comment, created_by, created_at)
+		SELECT id, review_round, $2, $3, $4, NOW() FROM achievement_references WHERE id = $1
+	`
+	for _, cm := range comments {
+		if _, err := tx.Exec(insert, id, cm.Field, cm.Comment, reviewerID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Semua komentar revisi sebuah prestasi (urut per ronde)
+func (r *AchievementRepoPG) GetRevisionComments(refID string) ([]postgres.RevisionComment, error) {
+	query := `
+		SELECT id, achievement_ref_id, round, field, comment, created_by, created_at
+		FROM achievement_revision_comments
+		WHERE achievement_ref_id = $1
+		ORDER BY round ASC, created_at ASC, id ASC
+	`
+	rows, err := r.DB.Query(query, refID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var achievements []postgres.AchievementReference
+	var comments []postgres.RevisionComment
 	for rows.Next() {
-		var ar postgres.AchievementReference
-		if err := rows.Scan(&ar.ID, &ar.StudentID, &ar.MongoAchievementID, &ar.Status, &ar.CreatedAt, &ar.UpdatedAt); err != nil {
+		var rc postgres.RevisionComment
+		if err := rows.Scan(&rc.ID, &rc.AchievementRefID, &rc.Round, &rc.Field, &rc.Comment, &rc.CreatedBy, &rc.CreatedAt); err != nil {
 			return nil, err
 		}
-		achievements = append(achievements, ar)
+		comments = append(comments, rc)
 	}
-	return achievements, nil
+	return comments, rows.Err()
 }
 
-// Update status Verify/Reject
-func (r *AchievementRepoPG) UpdateVerification(id string, status string, verifiedBy string, rejectionNote *string) error {
+// Kunci baris reference dan kembalikan status saat ini
+func lockStatus(tx *sql.Tx, id string) (string, error) {
+	var status string
+	err := tx.QueryRow(`SELECT status FROM achievement_references WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	return status, err
+}
+
+// Kunci baris reference; gagal dengan StatusConflictError jika status bukan expected
+func lockExpectedStatus(tx *sql.Tx, id, expected string) error {
+	status, err := lockStatus(tx, id)
+	if err != nil {
+		return err
+	}
+	if status != expected {
+		return &StatusConflictError{Expected: expected, Actual: status}
+	}
+	return nil
+}
+
+// Catat transisi status ke tabel history (dalam transaksi yang sama)
+func insertStatusHistory(tx *sql.Tx, refID, actorID string, fromStatus *string, toStatus string, note *string) error {
+	var actor *string
+	if actorID != "" {
+		actor = &actorID
+	}
 	query := `
-		UPDATE achievement_references 
-		SET status = $1, verified_by = $2, rejection_note = $3, verified_at = NOW(), updated_at = NOW() 
-		WHERE id = $4
+		INSERT INTO achievement_status_history (achievement_ref_id, actor_id, from_status, to_status, note, round, created_at)
+		VALUES ($1, $2, $3, $4, $5, (SELECT review_round FROM achievement_references WHERE id = $1), NOW())
 	`
-	_, err := r.DB.Exec(query, status, verifiedBy, rejectionNote, id)
+	_, err := tx.Exec(query, refID, actor, fromStatus, toStatus, note)
 	return err
 }
 
-func (r *AchievementRepoPG) GetAllAchievements(limit, offset int) ([]postgres.AchievementReference, int, error) {
-	// Ambil Data
+// Daftar reference (id, mongo id, status) berurutan per id untuk dipindai rekonsiliasi
+func (r *AchievementRepoPG) ListReferenceLinks(afterID string, limit int) ([]postgres.AchievementReference, error) {
 	query := `
-		SELECT id, student_id, mongo_achievement_id, status, created_at, updated_at
+		SELECT id, mongo_achievement_id, status
 		FROM achievement_references
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE ($1 = '' OR id::text > $1)
+		ORDER BY id::text
+		LIMIT $2
 	`
-	rows, err := r.DB.Query(query, limit, offset)
+	rows, err := r.DB.Query(query, afterID, limit)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []postgres.AchievementReference
+	for rows.Next() {
+		var ref postgres.AchievementReference
+		if err := rows.Scan(&ref.ID, &ref.MongoAchievementID, &ref.Status); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// Mongo ID mana saja yang masih dirujuk oleh achievement_references
+func (r *AchievementRepoPG) FindReferencedMongoIDs(mongoIDs []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(mongoIDs) == 0 {
+		return found, nil
+	}
+
+	rows, err := r.DB.Query(`SELECT mongo_achievement_id FROM achievement_references WHERE mongo_achievement_id = ANY($1)`, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found[id] = true
+	}
+	return found, rows.Err()
+}
+
+// Tandai reference terhapus oleh sistem (detail di MongoDB hilang), tercatat di history tanpa aktor
+func (r *AchievementRepoPG) MarkReferenceDeleted(id, note string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fromStatus, err := lockStatus(tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE achievement_references SET status = 'deleted', updated_at = NOW() WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if err := insertStatusHistory(tx, id, "", &fromStatus, "deleted", &note); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const referenceListSelect = `
+	SELECT ar.id, ar.student_id, ar.mongo_achievement_id, ar.status, ar.created_at, ar.updated_at
+	FROM achievement_references ar
+	JOIN students s ON ar.student_id = s.id
+	LEFT JOIN lecturers l ON s.advisor_id = l.id
+`
+
+// Satu halaman listing prestasi, urut berdasarkan field sort PostgreSQL (keyset pagination).
+// Return cursor halaman berikutnya (kosong jika sudah halaman terakhir)
+func (r *AchievementRepoPG) QueryAchievements(q listing.Query) ([]postgres.AchievementReference, string, error) {
+	column := "ar.created_at"
+	if q.SortBy == listing.SortUpdatedAt {
+		column = "ar.updated_at"
+	}
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	conds, args := referenceFilterConditions(q.Filter)
+	if q.After != nil {
+		after, err := time.Parse(time.RFC3339Nano, q.After.Value)
+		if err != nil {
+			return nil, "", listing.ErrInvalidCursor
+		}
+		if _, err := uuid.Parse(q.After.ID); err != nil {
+			return nil, "", listing.ErrInvalidCursor
+		}
+		args = append(args, after, q.After.ID)
+		conds = append(conds, fmt.Sprintf("(%s, ar.id) %s ($%d, $%d::uuid)", column, cmp, len(args)-1, len(args)))
+	}
+	args = append(args, q.Limit+1)
+
+	query := referenceListSelect + whereClause(conds) +
+		fmt.Sprintf(" ORDER BY %s %s, ar.id %s LIMIT $%d", column, dir, dir, len(args))
+	refs, err := r.scanReferences(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(refs) <= q.Limit {
+		return refs, "", nil
+	}
+	refs = refs[:q.Limit]
+	last := refs[len(refs)-1]
+	value := last.CreatedAt
+	if q.SortBy == listing.SortUpdatedAt {
+		value = last.UpdatedAt
+	}
+	next := listing.Cursor{SortBy: q.SortBy, Desc: q.Desc, Value: value.Format(time.RFC3339Nano), ID: last.ID}
+	return refs, next.Encode(), nil
+}
+
+// Semua reference yang cocok dengan filter (tanpa paging), dipakai saat sort dijalankan di MongoDB
+func (r *AchievementRepoPG) FindReferencesByFilter(f listing.Filter) ([]postgres.AchievementReference, error) {
+	conds, args := referenceFilterConditions(f)
+	return r.scanReferences(referenceListSelect+whereClause(conds), args...)
+}
+
+// Sama dengan FindReferencesByFilter, tapi paling banyak limit baris (urut id)
+func (r *AchievementRepoPG) FindReferencesByFilterLimit(f listing.Filter, limit int) ([]postgres.AchievementReference, error) {
+	conds, args := referenceFilterConditions(f)
+	args = append(args, limit)
+	query := referenceListSelect + whereClause(conds) + fmt.Sprintf(" ORDER BY ar.id LIMIT $%d", len(args))
+	return r.scanReferences(query, args...)
+}
+
+func (r *AchievementRepoPG) scanReferences(query string, args ...interface{}) ([]postgres.AchievementReference, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
 
-	var achievements []postgres.AchievementReference
+	var refs []postgres.AchievementReference
 	for rows.Next() {
 		var ar postgres.AchievementReference
-		rows.Scan(&ar.ID, &ar.StudentID, &ar.MongoAchievementID, &ar.Status, &ar.CreatedAt, &ar.UpdatedAt)
-		achievements = append(achievements, ar)
+		if err := rows.Scan(&ar.ID, &ar.StudentID, &ar.MongoAchievementID, &ar.Status, &ar.CreatedAt, &ar.UpdatedAt); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ar)
 	}
+	return refs, rows.Err()
+}
 
-	// Hitung Total Data (Untuk metadata pagination)
-	var total int
-	r.DB.QueryRow("SELECT COUNT(*) FROM achievement_references").Scan(&total)
-
-	return achievements, total, nil
-}
-
-func (r *AchievementRepoPG) GetAchievementsByStudentID(studentID string) ([]postgres.AchievementReference, error) {
-    query := `
-        SELECT id, student_id, mongo_achievement_id, status, created_at, updated_at
-        FROM achievement_references
-        WHERE student_id = $1
-        ORDER BY created_at DESC
-    `
-    rows, err := r.DB.Query(query, studentID)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
-
-    var achievements []postgres.AchievementReference
-    for rows.Next() {
-        var ar postgres.AchievementReference
-        if err := rows.Scan(&ar.ID, &ar.StudentID, &ar.MongoAchievementID, &ar.Status, &ar.CreatedAt, &ar.UpdatedAt); err != nil {
-            return nil, err
-        }
-        achievements = append(achievements, ar)
-    }
-    return achievements, nil
-}
\ No newline at end of file
+// Terjemahkan filter sisi PostgreSQL menjadi kondisi WHERE + argumen ($1, $2, ...)
+func referenceFilterConditions(f listing.Filter) ([]string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if len(f.Statuses) > 0 {
+		add("ar.status = ANY($%d)", pq.Array(f.Statuses))
+	}
+	if len(f.HiddenStatuses) > 0 {
+		add("NOT (ar.status = ANY($%d))", pq.Array(f.HiddenStatuses))
+	}
+	if f.CreatedFrom != nil {
+		add("ar.created_at >= $%d", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		add("ar.created_at <= $%d", *f.CreatedTo)
+	}
+	if f.StudentID != "" {
+		add("ar.student_id::text = $%d", f.StudentID)
+	}
+	if f.ProgramStudy != "" {
+		add("s.program_study = $%d", f.ProgramStudy)
+	}
+	if f.AdvisorID != "" {
+		add("s.advisor_id::text = $%d", f.AdvisorID)
+	}
+	if f.AdvisorUserID != "" {
+		add("l.user_id::text = $%d", f.AdvisorUserID)
+	}
+	if f.MongoIDs != nil {
+		add("ar.mongo_achievement_id = ANY($%d)", pq.Array(f.MongoIDs))
+	}
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+)
+
+type IAchievementTypeRepoPG interface {
+	GetAllTypes(includeInactive bool) ([]postgres.AchievementType, error)
+	GetTypeByCode(code string) (*postgres.AchievementType, error)
+	CreateType(t postgres.AchievementType) error
+	UpdateType(t postgres.AchievementType) error
+	DeactivateType(code string) error
+}
+
+type AchievementTypeRepoPG struct {
+	DB *sql.DB
+}
+
+func NewAchievementTypeRepoPG(db *sql.DB) IAchievementTypeRepoPG {
+	return &AchievementTypeRepoPG{DB: db}
+}
+
+const achievementTypeColumns = `code, name, COALESCE(description, ''), details_schema, default_points, is_active, created_at, updated_at`
+
+func scanAchievementType(row interface{ Scan(...interface{}) error }) (*postgres.AchievementType, error) {
+	t := &postgres.AchievementType{}
+	var schema []byte
+	err := row.Scan(&t.Code, &t.Name, &t.Description, &schema, &t.DefaultPoints, &t.IsActive, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.DetailsSchema = schema
+	return t, nil
+}
+
+func (r *AchievementTypeRepoPG) GetAllTypes(includeInactive bool) ([]postgres.AchievementType, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types WHERE $1 OR is_active ORDER BY name`
+	rows, err := r.DB.Query(query, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []postgres.AchievementType
+	for rows.Next() {
+		t, err := scanAchievementType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, *t)
+	}
+	return types, rows.Err()
+}
+
+func (r *AchievementTypeRepoPG) GetTypeByCode(code string) (*postgres.AchievementType, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types WHERE code = $1`
+	return scanAchievementType(r.DB.QueryRow(query, code))
+}
+
+func (r *AchievementTypeRepoPG) CreateType(t postgres.AchievementType) error {
+	query := `
+		INSERT INTO achievement_types (code, name, description, details_schema, default_points, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.DB.Exec(query, t.Code, t.Name, t.Description, []byte(t.DetailsSchema), t.DefaultPoints, t.IsActive)
+	return err
+}
+
+func (r *AchievementTypeRepoPG) UpdateType(t postgres.AchievementType) error {
+	query := `
+		UPDATE achievement_types
+		SET name = $1, description = $2, details_schema = $3, default_points = $4, is_active = $5, updated_at = NOW()
+		WHERE code = $6
+	`
+	res, err := r.DB.Exec(query, t.Name, t.Description, []byte(t.DetailsSchema), t.DefaultPoints, t.IsActive, t.Code)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// Jenis yang sudah dipakai prestasi tidak dihapus, hanya dinonaktifkan (tidak bisa dipilih lagi)
+func (r *AchievementTypeRepoPG) DeactivateType(code string) error {
+	res, err := r.DB.Exec(`UPDATE achievement_types SET is_active = FALSE, updated_at = NOW() WHERE code = $1`, code)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
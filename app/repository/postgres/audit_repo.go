@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+	"time"
+)
+
+type IAuditRepoPG interface {
+	CreateAuthAudit(log postgres.AuthAuditLog) error
+	GetRecentFailuresByIP(ip string, since time.Time) (int, *time.Time, error)
+	GetAuthAuditByUserID(userID string, limit int) ([]postgres.AuthAuditLog, error)
+}
+
+type AuditRepoPG struct {
+	DB *sql.DB
+}
+
+func NewAuditRepoPG(db *sql.DB) IAuditRepoPG {
+	return &AuditRepoPG{DB: db}
+}
+
+func (r *AuditRepoPG) CreateAuthAudit(log postgres.AuthAuditLog) error {
+	query := `
+		INSERT INTO auth_audit_logs (user_id, actor_id, username, ip_address, event, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`
+	_, err := r.DB.Exec(query, log.UserID, log.ActorID, log.Username, log.IPAddress, log.Event, log.Detail)
+	return err
+}
+
+// Jumlah login gagal dari satu IP sejak waktu tertentu + waktu gagal terakhir
+func (r *AuditRepoPG) GetRecentFailuresByIP(ip string, since time.Time) (int, *time.Time, error) {
+	query := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM auth_audit_logs
+		WHERE ip_address = $1 AND event = $2 AND created_at >= $3
+	`
+	var count int
+	var last sql.NullTime
+	if err := r.DB.QueryRow(query, ip, postgres.AuditLoginFailed, since).Scan(&count, &last); err != nil {
+		return 0, nil, err
+	}
+	if !last.Valid {
+		return count, nil, nil
+	}
+	return count, &last.Time, nil
+}
+
+func (r *AuditRepoPG) GetAuthAuditByUserID(userID string, limit int) ([]postgres.AuthAuditLog, error) {
+	query := `
+		SELECT id, user_id, actor_id, COALESCE(username, ''), COALESCE(ip_address, ''), event, COALESCE(detail, ''), created_at
+		FROM auth_audit_logs
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	rows, err := r.DB.Query(query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []postgres.AuthAuditLog
+	for rows.Next() {
+		var l postgres.AuthAuditLog
+		if err := rows.Scan(&l.ID, &l.UserID, &l.ActorID, &l.Username, &l.IPAddress, &l.Event, &l.Detail, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, nil
+}
@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type ICommentRepoPG interface {
+	ListComments(refID string) ([]postgres.AchievementComment, error)
+	GetComment(refID, id string) (*postgres.AchievementComment, error)
+	CreateComment(cm postgres.AchievementComment, mentionIDs []string) (*postgres.AchievementComment, error)
+	UpdateComment(id, body string, mentionIDs []string) error
+	DeleteComment(id string) error
+	FindMentionableUsers(refID string, usernames []string) ([]postgres.CommentMention, error)
+}
+
+type CommentRepoPG struct {
+	DB *sql.DB
+}
+
+func NewCommentRepoPG(db *sql.DB) ICommentRepoPG {
+	return &CommentRepoPG{DB: db}
+}
+
+const commentSelect = `
+	SELECT c.id, c.achievement_ref_id, c.parent_id, c.author_id, u.full_name, r.name,
+		c.body, c.created_at, c.updated_at, c.edited_at, c.deleted_at
+	FROM achievement_comments c
+	LEFT JOIN users u ON c.author_id = u.id
+	LEFT JOIN roles r ON u.role_id = r.id
+`
+
+func scanComment(row interface{ Scan(...interface{}) error }) (*postgres.AchievementComment, error) {
+	cm := &postgres.AchievementComment{Mentions: []postgres.CommentMention{}}
+	err := row.Scan(&cm.ID, &cm.AchievementRefID, &cm.ParentID, &cm.AuthorID, &cm.AuthorName, &cm.AuthorRole,
+		&cm.Body, &cm.CreatedAt, &cm.UpdatedAt, &cm.EditedAt, &cm.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return cm, nil
+}
+
+// Semua komentar prestasi (urut dari yang paling lama) beserta mention-nya
+func (r *CommentRepoPG) ListComments(refID string) ([]postgres.AchievementComment, error) {
+	rows, err := r.DB.Query(commentSelect+` WHERE c.achievement_ref_id = $1 ORDER BY c.created_at ASC, c.id ASC`, refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []postgres.AchievementComment
+	index := map[string]int{}
+	for rows.Next() {
+		cm, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		index[cm.ID] = len(comments)
+		comments = append(comments, *cm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mentions, err := r.DB.Query(`
+		SELECT m.comment_id, u.id, u.username, u.full_name
+		FROM achievement_comment_mentions m
+		JOIN achievement_comments c ON m.comment_id = c.id
+		JOIN users u ON m.user_id = u.id
+		WHERE c.achievement_ref_id = $1
+		ORDER BY u.username
+	`, refID)
+	if err != nil {
+		return nil, err
+	}
+	defer mentions.Close()
+	for mentions.Next() {
+		var commentID string
+		var m postgres.CommentMention
+		if err := mentions.Scan(&commentID, &m.UserID, &m.Username, &m.FullName); err != nil {
+			return nil, err
+		}
+		if i, ok := index[commentID]; ok {
+			comments[i].Mentions = append(comments[i].Mentions, m)
+		}
+	}
+	return comments, mentions.Err()
+}
+
+// Satu komentar milik prestasi tertentu (sql.ErrNoRows jika tidak ada / milik prestasi lain)
+func (r *CommentRepoPG) GetComment(refID, id string) (*postgres.AchievementComment, error) {
+	return scanComment(r.DB.QueryRow(commentSelect+` WHERE c.id::text = $1 AND c.achievement_ref_id::text = $2`, id, refID))
+}
+
+func (r *CommentRepoPG) CreateComment(cm postgres.AchievementComment, mentionIDs []string) (*postgres.AchievementComment, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO achievement_comments (achievement_ref_id, parent_id, author_id, body, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING id
+	`
+	if err := tx.QueryRow(query, cm.AchievementRefID, cm.ParentID, cm.AuthorID, cm.Body, cm.CreatedAt).Scan(&cm.ID); err != nil {
+		return nil, err
+	}
+	if err := insertMentions(tx, cm.ID, mentionIDs); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	cm.UpdatedAt = cm.CreatedAt
+	return &cm, nil
+}
+
+// Ubah isi komentar; daftar mention diganti sesuai isi baru
+func (r *CommentRepoPG) UpdateComment(id, body string, mentionIDs []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	res, err := tx.Exec(`
+		UPDATE achievement_comments SET body = $1, edited_at = $2, updated_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+	`, body, now, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM achievement_comment_mentions WHERE comment_id = $1`, id); err != nil {
+		return err
+	}
+	if err := insertMentions(tx, id, mentionIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Soft delete: isi & mention dihapus, baris tetap ada agar balasan tidak yatim
+func (r *CommentRepoPG) DeleteComment(id string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	res, err := tx.Exec(`
+		UPDATE achievement_comments SET body = '', deleted_at = $1, updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`, now, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM achievement_comment_mentions WHERE comment_id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// User aktif dengan username tersebut yang boleh melihat prestasi: mahasiswa pemilik, dosen walinya,
+// atau role dengan akses penuh (user:manage)
+func (r *CommentRepoPG) FindMentionableUsers(refID string, usernames []string) ([]postgres.CommentMention, error) {
+	query := `
+		SELECT u.id, u.username, u.full_name
+		FROM users u
+		JOIN achievement_references ar ON ar.id = $1
+		JOIN students s ON s.id = ar.student_id
+		LEFT JOIN lecturers l ON l.id = s.advisor_id
+		WHERE u.username = ANY($2) AND u.is_active
+			AND (u.id = s.user_id OR u.id = l.user_id OR EXISTS (
+				SELECT 1 FROM role_permissions rp JOIN permissions p ON rp.permission_id = p.id
+				WHERE rp.role_id = u.role_id AND p.name = 'user:manage'
+			))
+		ORDER BY u.username
+	`
+	rows, err := r.DB.Query(query, refID, pq.Array(usernames))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []postgres.CommentMention
+	for rows.Next() {
+		var m postgres.CommentMention
+		if err := rows.Scan(&m.UserID, &m.Username, &m.FullName); err != nil {
+			return nil, err
+		}
+		users = append(users, m)
+	}
+	return users, rows.Err()
+}
+
+func insertMentions(tx *sql.Tx, commentID string, userIDs []string) error {
+	for _, userID := range userIDs {
+		_, err := tx.Exec(`
+			INSERT INTO achievement_comment_mentions (comment_id, user_id) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, commentID, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
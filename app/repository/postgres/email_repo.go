@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Lama lease email yang sedang dikirim sebelum boleh diklaim worker lain.
+// Satu batch pengiriman harus selesai sebelum lease habis
+const EmailLease = 2 * time.Minute
+
+type IEmailRepoPG interface {
+	GetEmailRecipients(userIDs []string, eventType string, excludeDigest bool) ([]postgres.EmailRecipient, error)
+	ClaimEmails(limit int) ([]postgres.Email, error)
+	MarkEmailSent(id string) error
+	MarkEmailFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error
+	ReleaseEmails(ids []string) error
+
+	// Event yang menunggu dirender menjadi email
+	ClaimEmailEvents(limit int) ([]postgres.EmailEvent, error)
+	CompleteEmailEvent(id string, emails []postgres.Email) error
+	MarkEmailEventFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error
+
+	GetDigestRecipients(cutoff time.Time) ([]postgres.EmailRecipient, error)
+	GetPendingVerifications(advisorUserID string) ([]postgres.PendingVerification, error)
+	ClaimDigest(userID string, cutoff, at time.Time, digest *postgres.Email) (bool, error)
+}
+
+type EmailRepoPG struct {
+	DB *sql.DB
+}
+
+func NewEmailRepoPG(db *sql.DB) IEmailRepoPG {
+	return &EmailRepoPG{DB: db}
+}
+
+// User aktif yang punya alamat email & tidak menonaktifkan email untuk jenis event ini.
+// excludeDigest: lewati user yang memilih ringkasan harian
+func (r *EmailRepoPG) GetEmailRecipients(userIDs []string, eventType string, excludeDigest bool) ([]postgres.EmailRecipient, error) {
+	query := `
+		SELECT u.id, u.email, u.full_name, COALESCE(ns.locale, 'id')
+		FROM users u
+		LEFT JOIN notification_preferences p ON p.user_id = u.id AND p.event_type = $2
+		LEFT JOIN notification_settings ns ON ns.user_id = u.id
+		WHERE u.id::text = ANY($1) AND u.is_active AND COALESCE(u.email, '') <> ''
+			AND COALESCE(p.email, TRUE)
+			AND NOT ($3 AND COALESCE(ns.email_digest, FALSE))
+	`
+	return r.queryRecipients(query, pq.Array(userIDs), eventType, excludeDigest)
+}
+
+func (r *EmailRepoPG) queryRecipients(query string, args ...interface{}) ([]postgres.EmailRecipient, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []postgres.EmailRecipient
+	for rows.Next() {
+		var rc postgres.EmailRecipient
+		if err := rows.Scan(&rc.UserID, &rc.Email, &rc.FullName, &rc.Locale); err != nil {
+			return nil, err
+		}
+		list = append(list, rc)
+	}
+	return list, rows.Err()
+}
+
+func insertEmails(tx *sql.Tx, emails []postgres.Email) error {
+	query := `
+		INSERT INTO email_outbox (user_id, to_address, event_type, subject, text_body, html_body, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', NOW(), NOW())
+	`
+	for _, e := range emails {
+		if _, err := tx.Exec(query, e.UserID, e.To, e.EventType, e.Subject, e.Text, e.HTML); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Klaim email yang sudah jatuh tempo; yang sedang dipegang worker lain dilewati (SKIP LOCKED)
+func (r *EmailRepoPG) ClaimEmails(limit int) ([]postgres.Email, error) {
+	query := `
+		UPDATE email_outbox
+		SET status = 'processing', attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE status IN ('pending', 'processing') AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, to_address, event_type, subject, text_body, html_body, status, attempts, created_at
+	`
+	rows, err := r.DB.Query(query, limit, EmailLease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emails []postgres.Email
+	for rows.Next() {
+		var e postgres.Email
+		if err := rows.Scan(&e.ID, &e.UserID, &e.To, &e.EventType, &e.Subject, &e.Text, &e.HTML, &e.Status, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		emails = append(emails, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(emails, func(i, j int) bool { return emails[i].CreatedAt.Before(emails[j].CreatedAt) })
+	return emails, nil
+}
+
+func (r *EmailRepoPG) MarkEmailSent(id string) error {
+	_, err := r.DB.Exec(`UPDATE email_outbox SET status = 'sent', last_error = NULL, sent_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// Jadwalkan retry, atau tandai gagal permanen (dead = true)
+func (r *EmailRepoPG) MarkEmailFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := postgres.OutboxPending
+	if dead {
+		status = postgres.OutboxFailed
+	}
+	query := `UPDATE email_outbox SET status = $1, last_error = $2, next_attempt_at = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, status, lastError, nextAttemptAt, id)
+	return err
+}
+
+// Lepas email yang sudah diklaim tapi belum sempat dikirim (tidak dihitung sebagai percobaan)
+func (r *EmailRepoPG) ReleaseEmails(ids []string) error {
+	query := `
+		UPDATE email_outbox
+		SET status = 'pending', attempts = attempts - 1, next_attempt_at = NOW()
+		WHERE id::text = ANY($1) AND status = 'processing'
+	`
+	_, err := r.DB.Exec(query, pq.Array(ids))
+	return err
+}
+
+// Tulis event email di dalam transaksi milik perubahan data
+func insertEmailEvent(tx *sql.Tx, ev postgres.NotificationEvent) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO email_events (event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, 'pending', NOW(), NOW())
+	`
+	_, err = tx.Exec(query, ev.Type, payload)
+	return err
+}
+
+// Klaim event email yang jatuh tempo; yang sedang dipegang worker lain dilewati (SKIP LOCKED)
+func (r *EmailRepoPG) ClaimEmailEvents(limit int) ([]postgres.EmailEvent, error) {
+	query := `
+		UPDATE email_events
+		SET status = 'processing', attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM email_events
+			WHERE status IN ('pending', 'processing') AND next_attempt_at <= NOW()
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, attempts, created_at
+	`
+	rows, err := r.DB.Query(query, limit, EmailLease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []postgres.EmailEvent
+	for rows.Next() {
+		var e postgres.EmailEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &payload, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &e.Event); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].CreatedAt.Before(events[j].CreatedAt) })
+	return events, nil
+}
+
+// Simpan email hasil render & tandai event selesai dalam satu transaksi
+func (r *EmailRepoPG) CompleteEmailEvent(id string, emails []postgres.Email) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertEmails(tx, emails); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE email_events SET status = 'done', last_error = NULL, processed_at = NOW() WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Jadwalkan retry render, atau tandai gagal permanen (dead = true)
+func (r *EmailRepoPG) MarkEmailEventFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := postgres.OutboxPending
+	if dead {
+		status = postgres.OutboxFailed
+	}
+	query := `UPDATE email_events SET status = $1, last_error = $2, next_attempt_at = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, status, lastError, nextAttemptAt, id)
+	return err
+}
+
+// Dosen Wali dengan mode ringkasan yang belum menerima ringkasan sejak cutoff
+func (r *EmailRepoPG) GetDigestRecipients(cutoff time.Time) ([]postgres.EmailRecipient, error) {
+	query := `
+		SELECT u.id, u.email, u.full_name, ns.locale
+		FROM notification_settings ns
+		JOIN users u ON u.id = ns.user_id
+		JOIN lecturers l ON l.user_id = u.id
+		LEFT JOIN notification_preferences p ON p.user_id = u.id AND p.event_type = $2
+		WHERE ns.email_digest AND u.is_active AND COALESCE(u.email, '') <> ''
+			AND COALESCE(p.email, TRUE)
+			AND (ns.last_digest_at IS NULL OR ns.last_digest_at < $1)
+	`
+	return r.queryRecipients(query, cutoff, postgres.NotifAchievementSubmitted)
+}
+
+func (r *EmailRepoPG) GetPendingVerifications(advisorUserID string) ([]postgres.PendingVerification, error) {
+	query := `
+		SELECT ar.id, u.full_name, s.student_id, ar.submitted_at
+		FROM achievement_references ar
+		JOIN students s ON ar.student_id = s.id
+		JOIN users u ON s.user_id = u.id
+		JOIN lecturers l ON s.advisor_id = l.id
+		WHERE l.user_id = $1 AND ar.status = 'submitted'
+		ORDER BY ar.submitted_at
+	`
+	rows, err := r.DB.Query(query, advisorUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []postgres.PendingVerification
+	for rows.Next() {
+		var p postgres.PendingVerification
+		var submittedAt sql.NullTime
+		if err := rows.Scan(&p.AchievementRefID, &p.StudentName, &p.StudentNIM, &submittedAt); err != nil {
+			return nil, err
+		}
+		p.SubmittedAt = submittedAt.Time
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// Klaim ringkasan hari ini untuk dosen (hanya jika belum dikirim sejak cutoff) dan simpan emailnya
+// (nil = tanpa prestasi tertunda, hanya ditandai) dalam satu transaksi.
+// false jika sudah diklaim replika lain
+func (r *EmailRepoPG) ClaimDigest(userID string, cutoff, at time.Time, digest *postgres.Email) (bool, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE notification_settings SET last_digest_at = $2
+		WHERE user_id = $1 AND (last_digest_at IS NULL OR last_digest_at < $3)
+		RETURNING user_id
+	`
+	var id string
+	err = tx.QueryRow(query, userID, at, cutoff).Scan(&id)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if digest != nil {
+		if err := insertEmails(tx, []postgres.Email{*digest}); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
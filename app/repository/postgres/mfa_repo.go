@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+)
+
+type IMFARepoPG interface {
+	GetTOTPState(userID string) (*postgres.TOTPState, error)
+	SetPendingTOTPSecret(userID, secret string) error
+	EnableTOTP(userID string, recoveryHashes []string) error
+	DisableTOTP(userID string) error
+	ReplaceRecoveryCodes(userID string, recoveryHashes []string) error
+	UseRecoveryCode(userID, codeHash string) (bool, error)
+	MarkTOTPStepUsed(userID string, step int64) (bool, error)
+}
+
+type MFARepoPG struct {
+	DB *sql.DB
+}
+
+func NewMFARepoPG(db *sql.DB) IMFARepoPG {
+	return &MFARepoPG{DB: db}
+}
+
+func (r *MFARepoPG) GetTOTPState(userID string) (*postgres.TOTPState, error) {
+	query := `
+		SELECT COALESCE(u.totp_secret, ''), u.totp_enabled, u.totp_last_step,
+			(SELECT COUNT(*) FROM totp_recovery_codes rc WHERE rc.user_id = u.id AND rc.used_at IS NULL)
+		FROM users u
+		WHERE u.id = $1
+	`
+	state := &postgres.TOTPState{}
+	err := r.DB.QueryRow(query, userID).Scan(&state.Secret, &state.Enabled, &state.LastStep, &state.RecoveryCodesRemaining)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Simpan secret yang belum diaktifkan (hanya jika 2FA belum aktif)
+func (r *MFARepoPG) SetPendingTOTPSecret(userID, secret string) error {
+	res, err := r.DB.Exec(`UPDATE users SET totp_secret = $1 WHERE id = $2 AND totp_enabled = FALSE`, secret, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// Aktifkan 2FA dan simpan recovery code baru
+func (r *MFARepoPG) EnableTOTP(userID string, recoveryHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE users SET totp_enabled = TRUE, updated_at = NOW() WHERE id = $1 AND totp_secret IS NOT NULL`, userID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if err := insertRecoveryCodes(tx, userID, recoveryHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepoPG) DisableTOTP(userID string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = 0, updated_at = NOW() WHERE id = $1`
+	res, err := tx.Exec(query, userID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepoPG) ReplaceRecoveryCodes(userID string, recoveryHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertRecoveryCodes(tx, userID, recoveryHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Tandai recovery code terpakai. false jika kode tidak ada / sudah dipakai
+func (r *MFARepoPG) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	query := `
+		UPDATE totp_recovery_codes SET used_at = NOW() 
+		WHERE id = (
+			SELECT id FROM totp_recovery_codes 
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL 
+			LIMIT 1 FOR UPDATE
+		)
+	`
+	res, err := r.DB.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Catat time step terakhir yang dipakai. false jika step ini (atau yang lebih baru) sudah pernah dipakai
+func (r *MFARepoPG) MarkTOTPStepUsed(userID string, step int64) (bool, error) {
+	res, err := r.DB.Exec(`UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1`, step, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// Ganti seluruh recovery code user dengan yang baru
+func insertRecoveryCodes(tx *sql.Tx, userID string, hashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, h := range hashes {
+		if _, err := tx.Exec(`INSERT INTO totp_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, NOW())`, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type INotificationRepoPG interface {
+	CreateNotifications(ns []postgres.Notification) error
+	GetAchievementParticipants(refID string) (*postgres.NotificationParticipants, error)
+	GetStudentParticipants(studentID string) (*postgres.NotificationParticipants, error)
+
+	ListNotifications(userID string, unreadOnly bool, limit, offset int) ([]postgres.Notification, int, error)
+	CountUnread(userID string) (int, error)
+	MarkRead(userID string, ids []string) (int64, error)
+	MarkAllRead(userID string) (int64, error)
+	GetPreferences(userID string) ([]postgres.NotificationPreference, error)
+	SetPreferences(userID string, inApp, email map[string]bool) error
+	GetSettings(userID string) (*postgres.NotificationSettings, error)
+	SetSettings(userID string, locale *string, emailDigest *bool) error
+}
+
+type NotificationRepoPG struct {
+	DB *sql.DB
+}
+
+func NewNotificationRepoPG(db *sql.DB) INotificationRepoPG {
+	return &NotificationRepoPG{DB: db}
+}
+
+// Simpan notifikasi; penerima yang menonaktifkan jenis event tersebut dilewati
+func (r *NotificationRepoPG) CreateNotifications(ns []postgres.Notification) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notifications (user_id, event_type, title, message, achievement_ref_id, actor_id, data, created_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, NOW()
+		WHERE NOT EXISTS (
+			SELECT 1 FROM notification_preferences
+			WHERE user_id = $1 AND event_type = $2 AND NOT in_app
+		)
+	`
+	for _, n := range ns {
+		data := []byte(n.Data)
+		if len(data) == 0 {
+			data = []byte("{}")
+		}
+		if _, err := tx.Exec(query, n.UserID, n.EventType, n.Title, n.Message, n.AchievementRefID, n.ActorID, data); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+const participantsSelect = `
+	SELECT COALESCE(s.user_id::text, ''), COALESCE(l.user_id::text, ''), COALESCE(su.full_name, ''), COALESCE(s.student_id, '')
+	FROM students s
+	LEFT JOIN users su ON s.user_id = su.id
+	LEFT JOIN lecturers l ON s.advisor_id = l.id
+`
+
+func (r *NotificationRepoPG) GetAchievementParticipants(refID string) (*postgres.NotificationParticipants, error) {
+	p := &postgres.NotificationParticipants{}
+	query := participantsSelect + ` JOIN achievement_references ar ON ar.student_id = s.id WHERE ar.id = $1`
+	if err := r.DB.QueryRow(query, refID).Scan(&p.StudentUserID, &p.AdvisorUserID, &p.StudentName, &p.StudentNIM); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *NotificationRepoPG) GetStudentParticipants(studentID string) (*postgres.NotificationParticipants, error) {
+	p := &postgres.NotificationParticipants{}
+	if err := r.DB.QueryRow(participantsSelect+` WHERE s.id = $1`, studentID).Scan(&p.StudentUserID, &p.AdvisorUserID, &p.StudentName, &p.StudentNIM); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Notifikasi milik user (terbaru dulu) beserta total untuk paging
+func (r *NotificationRepoPG) ListNotifications(userID string, unreadOnly bool, limit, offset int) ([]postgres.Notification, int, error) {
+	query := `
+		SELECT n.id, n.user_id, n.event_type, n.title, n.message, n.achievement_ref_id, n.actor_id, u.full_name,
+			n.data, n.read_at, n.created_at
+		FROM notifications n
+		LEFT JOIN users u ON n.actor_id = u.id
+		WHERE n.user_id = $1 AND (NOT $2 OR n.read_at IS NULL)
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.DB.Query(query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var list []postgres.Notification
+	for rows.Next() {
+		var n postgres.Notification
+		var data []byte
+		if err := rows.Scan(&n.ID, &n.UserID, &n.EventType, &n.Title, &n.Message, &n.AchievementRefID, &n.ActorID, &n.ActorName,
+			&data, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		n.Data = data
+		list = append(list, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+	err = r.DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)`, userID, unreadOnly).Scan(&total)
+	return list, total, err
+}
+
+func (r *NotificationRepoPG) CountUnread(userID string) (int, error) {
+	var n int
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&n)
+	return n, err
+}
+
+// Tandai dibaca; ID milik user lain diabaikan
+func (r *NotificationRepoPG) MarkRead(userID string, ids []string) (int64, error) {
+	res, err := r.DB.Exec(`
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND id::text = ANY($2) AND read_at IS NULL
+	`, userID, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *NotificationRepoPG) MarkAllRead(userID string) (int64, error) {
+	res, err := r.DB.Exec(`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Preferensi yang pernah diubah user (jenis lain memakai default aktif)
+func (r *NotificationRepoPG) GetPreferences(userID string) ([]postgres.NotificationPreference, error) {
+	rows, err := r.DB.Query(`SELECT event_type, in_app, email FROM notification_preferences WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prefs []postgres.NotificationPreference
+	for rows.Next() {
+		var p postgres.NotificationPreference
+		var email bool
+		if err := rows.Scan(&p.EventType, &p.InApp, &email); err != nil {
+			return nil, err
+		}
+		p.Email = &email
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+// Ubah preferensi in-app / email; kanal yang tidak disebut untuk suatu event tidak berubah
+func (r *NotificationRepoPG) SetPreferences(userID string, inApp, email map[string]bool) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_preferences (user_id, event_type, in_app, email, updated_at)
+		VALUES ($1, $2, COALESCE($3, TRUE), COALESCE($4, TRUE), NOW())
+		ON CONFLICT (user_id, event_type) DO UPDATE SET
+			in_app = COALESCE($3, notification_preferences.in_app),
+			email = COALESCE($4, notification_preferences.email),
+			updated_at = NOW()
+	`
+	types := map[string]bool{}
+	for t := range inApp {
+		types[t] = true
+	}
+	for t := range email {
+		types[t] = true
+	}
+	for eventType := range types {
+		if _, err := tx.Exec(query, userID, eventType, optionalBool(inApp, eventType), optionalBool(email, eventType)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func optionalBool(m map[string]bool, key string) sql.NullBool {
+	v, ok := m[key]
+	return sql.NullBool{Bool: v, Valid: ok}
+}
+
+// Pengaturan email (default: bahasa Indonesia, tanpa ringkasan)
+func (r *NotificationRepoPG) GetSettings(userID string) (*postgres.NotificationSettings, error) {
+	st := &postgres.NotificationSettings{Locale: "id"}
+	err := r.DB.QueryRow(`SELECT locale, email_digest FROM notification_settings WHERE user_id = $1`, userID).Scan(&st.Locale, &st.EmailDigest)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (r *NotificationRepoPG) SetSettings(userID string, locale *string, emailDigest *bool) error {
+	query := `
+		INSERT INTO notification_settings (user_id, locale, email_digest, updated_at)
+		VALUES ($1, COALESCE($2, 'id'), COALESCE($3, FALSE), NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			locale = COALESCE($2, notification_settings.locale),
+			email_digest = COALESCE($3, notification_settings.email_digest),
+			updated_at = NOW()
+	`
+	_, err := r.DB.Exec(query, userID, locale, emailDigest)
+	return err
+}
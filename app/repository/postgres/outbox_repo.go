@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// Lama lease event yang sedang diproses sebelum boleh diklaim worker lain
+const outboxLease = time.Minute
+
+type IOutboxRepoPG interface {
+	ClaimEvents(aggregateID string, limit int) ([]postgres.OutboxEvent, error)
+	MarkEventDone(id string) error
+	MarkEventFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error
+	Enqueue(aggregateID, eventType string, payload interface{}) error
+}
+
+type OutboxRepoPG struct {
+	DB *sql.DB
+}
+
+func NewOutboxRepoPG(db *sql.DB) IOutboxRepoPG {
+	return &OutboxRepoPG{DB: db}
+}
+
+// Klaim event yang sudah jatuh tempo (aggregateID kosong = semua aggregate).
+// Event yang sedang dipegang worker lain dilewati (SKIP LOCKED)
+func (r *OutboxRepoPG) ClaimEvents(aggregateID string, limit int) ([]postgres.OutboxEvent, error) {
+	query := `
+		UPDATE achievement_outbox 
+		SET status = 'processing', attempts = attempts + 1, next_attempt_at = NOW() + make_interval(secs => $3)
+		WHERE id IN (
+			SELECT id FROM achievement_outbox
+			WHERE status IN ('pending', 'processing') AND next_attempt_at <= NOW()
+				AND ($1 = '' OR aggregate_id::text = $1)
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, aggregate_id, event_type, payload, status, attempts, created_at
+	`
+	rows, err := r.DB.Query(query, aggregateID, limit, outboxLease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []postgres.OutboxEvent
+	for rows.Next() {
+		var e postgres.OutboxEvent
+		if err := rows.Scan(&e.ID, &e.AggregateID, &e.EventType, &e.Payload, &e.Status, &e.Attempts, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING tidak menjamin urutan, event diproses sesuai urutan dibuat
+	sort.Slice(events, func(i, j int) bool { return events[i].CreatedAt.Before(events[j].CreatedAt) })
+	return events, nil
+}
+
+func (r *OutboxRepoPG) MarkEventDone(id string) error {
+	_, err := r.DB.Exec(`UPDATE achievement_outbox SET status = 'done', last_error = NULL, processed_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// Jadwalkan retry, atau tandai gagal permanen (dead = true)
+func (r *OutboxRepoPG) MarkEventFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error {
+	status := postgres.OutboxPending
+	if dead {
+		status = postgres.OutboxFailed
+	}
+	query := `UPDATE achievement_outbox SET status = $1, last_error = $2, next_attempt_at = $3 WHERE id = $4`
+	_, err := r.DB.Exec(query, status, lastError, nextAttemptAt, id)
+	return err
+}
+
+// Catat event yang tidak terikat transaksi status (mis. GC file lampiran)
+func (r *OutboxRepoPG) Enqueue(aggregateID, eventType string, payload interface{}) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := insertOutboxEvent(tx, aggregateID, eventType, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Tulis event outbox di dalam transaksi milik perubahan data
+func insertOutboxEvent(tx *sql.Tx, aggregateID, eventType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO achievement_outbox (aggregate_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, 'pending', NOW(), NOW())
+	`
+	_, err = tx.Exec(query, aggregateID, eventType, body)
+	return err
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+)
+
+type IPointRuleRepoPG interface {
+	GetRuleSets() ([]postgres.PointRuleSet, error)
+	GetRuleSetByVersion(version int) (*postgres.PointRuleSet, error)
+	GetActiveRuleSet() (*postgres.PointRuleSet, error)
+	CreateRuleSet(rs postgres.PointRuleSet) (int, error)
+	ActivateRuleSet(version int) error
+}
+
+type PointRuleRepoPG struct {
+	DB *sql.DB
+}
+
+func NewPointRuleRepoPG(db *sql.DB) IPointRuleRepoPG {
+	return &PointRuleRepoPG{DB: db}
+}
+
+const pointRuleColumns = `version, COALESCE(description, ''), rules, is_active, created_by, created_at, activated_at`
+
+func scanPointRuleSet(row interface{ Scan(...interface{}) error }) (*postgres.PointRuleSet, error) {
+	rs := &postgres.PointRuleSet{}
+	var rules []byte
+	if err := row.Scan(&rs.Version, &rs.Description, &rules, &rs.IsActive, &rs.CreatedBy, &rs.CreatedAt, &rs.ActivatedAt); err != nil {
+		return nil, err
+	}
+	rs.Rules = rules
+	return rs, nil
+}
+
+func (r *PointRuleRepoPG) GetRuleSets() ([]postgres.PointRuleSet, error) {
+	rows, err := r.DB.Query(`SELECT ` + pointRuleColumns + ` FROM point_rule_sets ORDER BY version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sets []postgres.PointRuleSet
+	for rows.Next() {
+		rs, err := scanPointRuleSet(rows)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, *rs)
+	}
+	return sets, rows.Err()
+}
+
+func (r *PointRuleRepoPG) GetRuleSetByVersion(version int) (*postgres.PointRuleSet, error) {
+	return scanPointRuleSet(r.DB.QueryRow(`SELECT `+pointRuleColumns+` FROM point_rule_sets WHERE version = $1`, version))
+}
+
+// sql.ErrNoRows jika belum ada aturan aktif
+func (r *PointRuleRepoPG) GetActiveRuleSet() (*postgres.PointRuleSet, error) {
+	return scanPointRuleSet(r.DB.QueryRow(`SELECT ` + pointRuleColumns + ` FROM point_rule_sets WHERE is_active`))
+}
+
+// Versi baru selalu dibuat nonaktif; aktifkan terpisah setelah dicek (preview)
+func (r *PointRuleRepoPG) CreateRuleSet(rs postgres.PointRuleSet) (int, error) {
+	var version int
+	query := `INSERT INTO point_rule_sets (description, rules, created_by) VALUES ($1, $2, $3) RETURNING version`
+	err := r.DB.QueryRow(query, rs.Description, []byte(rs.Rules), rs.CreatedBy).Scan(&version)
+	return version, err
+}
+
+func (r *PointRuleRepoPG) ActivateRuleSet(version int) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE point_rule_sets SET is_active = FALSE WHERE is_active AND version <> $1`, version); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`UPDATE point_rule_sets SET is_active = TRUE, activated_at = NOW() WHERE version = $1`, version)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,210 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+)
+
+type IRoleRepoPG interface {
+	GetAllRoles() ([]postgres.Role, error)
+	GetRoleByID(id string) (*postgres.Role, error)
+	CreateRole(role postgres.Role) (string, error)
+	UpdateRole(id string, req postgres.UpdateRoleRequest) error
+	DeleteRole(id string) error
+
+	GetAllPermissions() ([]postgres.Permission, error)
+	CreatePermission(perm postgres.Permission) (string, error)
+	DeletePermission(id string) error
+
+	GetPermissionsByRoleID(roleID string) ([]string, error)
+	AssignPermission(roleID, permissionID string) error
+	RevokePermission(roleID, permissionID string) error
+}
+
+type RoleRepoPG struct {
+	DB *sql.DB
+}
+
+func NewRoleRepoPG(db *sql.DB) IRoleRepoPG {
+	return &RoleRepoPG{DB: db}
+}
+
+func (r *RoleRepoPG) GetAllRoles() ([]postgres.Role, error) {
+	rows, err := r.DB.Query(`SELECT id, name, COALESCE(description, ''), require_2fa, created_at FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []postgres.Role
+	for rows.Next() {
+		var role postgres.Role
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.Require2FA, &role.CreatedAt); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	// Lengkapi permissions per role
+	for i := range roles {
+		perms, err := r.GetPermissionsByRoleID(roles[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		roles[i].Permissions = perms
+	}
+	return roles, nil
+}
+
+func (r *RoleRepoPG) GetRoleByID(id string) (*postgres.Role, error) {
+	role := &postgres.Role{}
+	err := r.DB.QueryRow(`SELECT id, name, COALESCE(description, ''), require_2fa, created_at FROM roles WHERE id = $1`, id).
+		Scan(&role.ID, &role.Name, &role.Description, &role.Require2FA, &role.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	role.Permissions, err = r.GetPermissionsByRoleID(role.ID)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (r *RoleRepoPG) CreateRole(role postgres.Role) (string, error) {
+	var id string
+	query := `INSERT INTO roles (name, description, require_2fa, created_at) VALUES ($1, $2, $3, NOW()) RETURNING id`
+	err := r.DB.QueryRow(query, role.Name, role.Description, role.Require2FA).Scan(&id)
+	return id, err
+}
+
+// require_2fa hanya diubah jika dikirim (nil = tetap)
+func (r *RoleRepoPG) UpdateRole(id string, req postgres.UpdateRoleRequest) error {
+	query := `UPDATE roles SET name = $1, description = $2, require_2fa = COALESCE($3, require_2fa) WHERE id = $4`
+	res, err := r.DB.Exec(query, req.Name, req.Description, req.Require2FA, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func (r *RoleRepoPG) DeleteRole(id string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id = $1`, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM roles WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RoleRepoPG) GetAllPermissions() ([]postgres.Permission, error) {
+	query := `
+		SELECT id, name, COALESCE(resource, ''), COALESCE(action, ''), COALESCE(description, '')
+		FROM permissions
+		ORDER BY name
+	`
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var perms []postgres.Permission
+	for rows.Next() {
+		var p postgres.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		perms = append(perms, p)
+	}
+	return perms, nil
+}
+
+func (r *RoleRepoPG) CreatePermission(perm postgres.Permission) (string, error) {
+	var id string
+	query := `INSERT INTO permissions (name, resource, action, description) VALUES ($1, $2, $3, $4) RETURNING id`
+	err := r.DB.QueryRow(query, perm.Name, perm.Resource, perm.Action, perm.Description).Scan(&id)
+	return id, err
+}
+
+func (r *RoleRepoPG) DeletePermission(id string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE permission_id = $1`, id); err != nil {
+		return err
+	}
+	res, err := tx.Exec(`DELETE FROM permissions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RoleRepoPG) GetPermissionsByRoleID(roleID string) ([]string, error) {
+	query := `
+		SELECT p.name 
+		FROM permissions p
+		JOIN role_permissions rp ON p.id = rp.permission_id
+		WHERE rp.role_id = $1
+		ORDER BY p.name
+	`
+	rows, err := r.DB.Query(query, roleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, name)
+	}
+	return permissions, nil
+}
+
+func (r *RoleRepoPG) AssignPermission(roleID, permissionID string) error {
+	query := `INSERT INTO role_permissions (role_id, permission_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+	_, err := r.DB.Exec(query, roleID, permissionID)
+	return err
+}
+
+func (r *RoleRepoPG) RevokePermission(roleID, permissionID string) error {
+	res, err := r.DB.Exec(`DELETE FROM role_permissions WHERE role_id = $1 AND permission_id = $2`, roleID, permissionID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// sql.ErrNoRows jika tidak ada baris yang terpengaruh (untuk 404)
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
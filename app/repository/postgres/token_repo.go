@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"be_uas/app/model/postgres"
+	"database/sql"
+	"time"
+)
+
+type ITokenRepoPG interface {
+	RevokeToken(jti, userID string, expiresAt time.Time) error
+	RevokeAllUserSessions(userID string) error
+	GetSessionState(userID, jti string) (*postgres.SessionState, error)
+	DeleteExpiredTokens() (int64, error)
+
+	CreateRefreshToken(rt postgres.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*postgres.RefreshToken, error)
+	MarkRefreshTokenUsed(id string) (bool, error)
+	RevokeRefreshTokenFamily(familyID string) error
+
+	ChangePassword(userID, newPasswordHash string) error
+	CreatePasswordResetToken(t postgres.PasswordResetToken) error
+	ConsumePasswordResetToken(tokenHash, newPasswordHash string) (string, error)
+}
+
+type TokenRepoPG struct {
+	DB *sql.DB
+}
+
+func NewTokenRepoPG(db *sql.DB) ITokenRepoPG {
+	return &TokenRepoPG{DB: db}
+}
+
+// Masukkan jti ke denylist sampai token kedaluwarsa
+func (r *TokenRepoPG) RevokeToken(jti, userID string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at) 
+		VALUES ($1, $2, $3, NOW()) 
+		ON CONFLICT (jti) DO NOTHING
+	`
+	_, err := r.DB.Exec(query, jti, userID, expiresAt)
+	return err
+}
+
+// Naikkan token_version (access token lama tidak berlaku) dan cabut semua refresh token user
+func (r *TokenRepoPG) RevokeAllUserSessions(userID string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1`, userID); err != nil {
+		return err
+	}
+	if err := revokeRefreshTokensTx(tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func revokeRefreshTokensTx(tx *sql.Tx, userID string) error {
+	_, err := tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
+
+// sql.ErrNoRows jika user sudah dihapus
+func (r *TokenRepoPG) GetSessionState(userID, jti string) (*postgres.SessionState, error) {
+	query := `
+		SELECT u.is_active, u.token_version, EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $2)
+		FROM users u
+		WHERE u.id = $1
+	`
+	state := &postgres.SessionState{}
+	err := r.DB.QueryRow(query, userID, jti).Scan(&state.IsActive, &state.TokenVersion, &state.TokenRevoked)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (r *TokenRepoPG) DeleteExpiredTokens() (int64, error) {
+	res, err := r.DB.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	revoked, _ := res.RowsAffected()
+
+	res, err = r.DB.Exec(`DELETE FROM refresh_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return revoked, err
+	}
+	refresh, _ := res.RowsAffected()
+
+	return revoked + refresh, nil
+}
+
+func (r *TokenRepoPG) CreateRefreshToken(rt postgres.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`
+	_, err := r.DB.Exec(query, rt.UserID, rt.FamilyID, rt.TokenHash, rt.ExpiresAt)
+	return err
+}
+
+func (r *TokenRepoPG) GetRefreshTokenByHash(hash string) (*postgres.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+	rt := &postgres.RefreshToken{}
+	err := r.DB.QueryRow(query, hash).Scan(
+		&rt.ID, &rt.UserID, &rt.FamilyID, &rt.TokenHash,
+		&rt.ExpiresAt, &rt.UsedAt, &rt.RevokedAt, &rt.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rt, nil
+}
+
+// Tandai token sudah dipakai. false jika ternyata sudah dipakai request lain (reuse)
+func (r *TokenRepoPG) MarkRefreshTokenUsed(id string) (bool, error) {
+	res, err := r.DB.Exec(`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *TokenRepoPG) RevokeRefreshTokenFamily(familyID string) error {
+	_, err := r.DB.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	return err
+}
+
+// Simpan password baru sekaligus cabut semua sesi user dalam satu transaksi.
+// sql.ErrNoRows jika user tidak ada
+func (r *TokenRepoPG) ChangePassword(userID, newPasswordHash string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET password_hash = $1, must_change_password = FALSE, password_changed_at = NOW(),
+			token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $2
+	`
+	res, err := tx.Exec(query, newPasswordHash, userID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if err := revokeRefreshTokensTx(tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Simpan token reset baru: token lama yang belum dipakai dibatalkan, semua sesi user dicabut
+// dan user wajib ganti password
+func (r *TokenRepoPG) CreatePasswordResetToken(t postgres.PasswordResetToken) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET must_change_password = TRUE, token_version = token_version + 1, updated_at = NOW()
+		WHERE id = $1
+	`
+	res, err := tx.Exec(query, t.UserID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res); err != nil {
+		return err
+	}
+	if err := revokeRefreshTokensTx(tx, t.UserID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, t.UserID); err != nil {
+		return err
+	}
+	query = `
+		INSERT INTO password_reset_tokens (user_id, created_by, token_hash, expires_at, created_at)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, NOW())
+	`
+	if _, err := tx.Exec(query, t.UserID, t.CreatedBy, t.TokenHash, t.ExpiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Pakai token reset (sekali pakai) untuk set password baru, sekaligus cabut semua sesi user.
+// Return user ID, atau sql.ErrNoRows jika token tidak ada / sudah dipakai / kedaluwarsa
+func (r *TokenRepoPG) ConsumePasswordResetToken(tokenHash, newPasswordHash string) (string, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var id, userID string
+	query := `
+		SELECT id, user_id FROM password_reset_tokens 
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW() 
+		FOR UPDATE
+	`
+	if err := tx.QueryRow(query, tokenHash).Scan(&id, &userID); err != nil {
+		return "", err
+	}
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1`, id); err != nil {
+		return "", err
+	}
+
+	query = `
+		UPDATE users 
+		SET password_hash = $1, must_change_password = FALSE, password_changed_at = NOW(), 
+			token_version = token_version + 1, updated_at = NOW() 
+		WHERE id = $2
+	`
+	if _, err := tx.Exec(query, newPasswordHash, userID); err != nil {
+		return "", err
+	}
+	if err := revokeRefreshTokensTx(tx, userID); err != nil {
+		return "", err
+	}
+	return userID, tx.Commit()
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"be_uas/app/model/postgres"
 	"database/sql"
+	"time"
 )
 
 type IUserRepo interface {
@@ -14,6 +15,10 @@ type IUserRepo interface {
 	DeleteUser(id string) error
 	GetRoleIDByName(roleName string) (string, error)
 	UpdateUserRole(userID, roleID string) error 
+	RecordFailedLogin(userID string) (int, error)
+	LockUser(userID string, until time.Time) error
+	ResetFailedLogins(userID string) error
+	GetPasswordHash(userID string) (string, error)
 }
 
 type UserRepo struct {
@@ -28,7 +33,9 @@ func NewUserRepo(db *sql.DB) IUserRepo {
 func (r *UserRepo) GetByUsername(username string) (*postgres.User, error) {
     // 1. Ambil Data User Dasar
     queryUser := `
-        SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, u.is_active, r.name as role_name
+        SELECT u.id, u.username, u.email, u.password_hash, u.full_name, u.role_id, u.is_active, u.token_version, 
+               u.failed_login_attempts, u.locked_until, u.must_change_password, 
+               u.totp_enabled, r.require_2fa, r.name as role_name
         FROM users u
         JOIN roles r ON u.role_id = r.id
         WHERE u.username = $1
@@ -36,7 +43,9 @@ func (r *UserRepo) GetByUsername(username string) (*postgres.User, error) {
     user := &postgres.User{}
     err := r.DB.QueryRow(queryUser, username).Scan(
         &user.ID, &user.Username, &user.Email, &user.PasswordHash,
-        &user.FullName, &user.RoleID, &user.IsActive, &user.RoleName,
+        &user.FullName, &user.RoleID, &user.IsActive, &user.TokenVersion,
+        &user.FailedLoginAttempts, &user.LockedUntil, &user.MustChangePassword, 
+        &user.TOTPEnabled, &user.Require2FA, &user.RoleName,
     )
     if err != nil {
         return nil, err
@@ -105,7 +114,9 @@ func (r *UserRepo) GetAllUsers() ([]postgres.User, error) {
 func (r *UserRepo) GetUserByID(id string) (*postgres.User, error) {
 	// 1. Ambil Data User Dasar
 	query := `
-		SELECT u.id, u.username, u.email, u.full_name, u.role_id, u.is_active, r.name as role_name
+		SELECT u.id, u.username, u.email, u.full_name, u.role_id, u.is_active, u.token_version, 
+			u.failed_login_attempts, u.locked_until, u.must_change_password, 
+			u.totp_enabled, r.require_2fa, r.name as role_name
 		FROM users u
 		JOIN roles r ON u.role_id = r.id
 		WHERE u.id = $1
@@ -113,7 +124,9 @@ func (r *UserRepo) GetUserByID(id string) (*postgres.User, error) {
 	user := &postgres.User{}
 	err := r.DB.QueryRow(query, id).Scan(
 		&user.ID, &user.Username, &user.Email, &user.FullName, 
-		&user.RoleID, &user.IsActive, &user.RoleName,
+		&user.RoleID, &user.IsActive, &user.TokenVersion,
+		&user.FailedLoginAttempts, &user.LockedUntil, &user.MustChangePassword, 
+		&user.TOTPEnabled, &user.Require2FA, &user.RoleName,
 	)
 	if err != nil {
 		return nil, err
@@ -165,4 +178,32 @@ func (r *UserRepo) GetRoleIDByName(roleName string) (string, error) {
 func (r *UserRepo) UpdateUserRole(userID, roleID string) error {
     _, err := r.DB.Exec("UPDATE users SET role_id = $1, updated_at = NOW() WHERE id = $2", roleID, userID)
     return err
-}
\ No newline at end of file
+}
+
+// Tambah counter gagal login, return jumlah percobaan gagal berturut-turut
+func (r *UserRepo) RecordFailedLogin(userID string) (int, error) {
+	var attempts int
+	query := `UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE id = $1 RETURNING failed_login_attempts`
+	err := r.DB.QueryRow(query, userID).Scan(&attempts)
+	return attempts, err
+}
+
+func (r *UserRepo) LockUser(userID string, until time.Time) error {
+	_, err := r.DB.Exec("UPDATE users SET locked_until = $1, updated_at = NOW() WHERE id = $2", until, userID)
+	return err
+}
+
+// Reset counter & lockout (login berhasil atau di-unlock admin)
+func (r *UserRepo) ResetFailedLogins(userID string) error {
+	res, err := r.DB.Exec("UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1", userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func (r *UserRepo) GetPasswordHash(userID string) (string, error) {
+	var hash string
+	err := r.DB.QueryRow("SELECT password_hash FROM users WHERE id = $1", userID).Scan(&hash)
+	return hash, err
+}
@@ -0,0 +1,100 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// Ukuran chunk INSTREAM (di bawah StreamMaxLength default clamd)
+const clamChunkSize = 64 * 1024
+
+var ErrScanFailed = errors.New("scanner: scan failed")
+
+// Client protokol clamd (perintah INSTREAM), lewat TCP atau unix socket
+type ClamAV struct {
+	Network string // "tcp" / "unix"
+	Address string
+	Timeout time.Duration
+}
+
+// addr: tcp://host:port, unix:///path/clamd.sock, atau host:port
+func NewClamAV(addr string, timeout time.Duration) (*ClamAV, error) {
+	c := &ClamAV{Network: "tcp", Address: addr, Timeout: timeout}
+	switch {
+	case strings.HasPrefix(addr, "tcp://"):
+		c.Address = strings.TrimPrefix(addr, "tcp://")
+	case strings.HasPrefix(addr, "unix://"):
+		c.Network, c.Address = "unix", strings.TrimPrefix(addr, "unix://")
+	}
+	if c.Address == "" {
+		return nil, fmt.Errorf("invalid CLAMAV_ADDR %q", addr)
+	}
+	return c, nil
+}
+
+func (c *ClamAV) Scan(ctx context.Context, body io.Reader) (Result, error) {
+	dialer := net.Dialer{Timeout: c.Timeout}
+	conn, err := dialer.DialContext(ctx, c.Network, c.Address)
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if c.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.Timeout))
+	}
+
+	// Format: "zINSTREAM\0", lalu chunk <panjang uint32 big-endian><data>, diakhiri chunk panjang 0
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Result{}, err
+	}
+	buf := make([]byte, clamChunkSize+4)
+	for {
+		n, readErr := body.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return Result{}, err
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return Result{}, readErr
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Result{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(err == io.EOF && reply != "") {
+		return Result{}, err
+	}
+	return parseReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// Balasan clamd: "stream: OK", "stream: <signature> FOUND", atau "<pesan> ERROR"
+func parseReply(reply string) (Result, error) {
+	switch {
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(reply, " FOUND")
+		if i := strings.Index(signature, ": "); i >= 0 {
+			signature = signature[i+2:]
+		}
+		return Result{Infected: true, Signature: signature}, nil
+	case strings.HasSuffix(reply, ": OK"):
+		return Result{}, nil
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrScanFailed, reply)
+	}
+}
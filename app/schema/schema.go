@@ -0,0 +1,255 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Subset JSON-Schema (draft-07) yang dipakai untuk details prestasi:
+// type, properties, required, additionalProperties, enum, minLength, maxLength,
+// pattern, minimum, maximum, format (date, date-time, uri), items, minItems, maxItems
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Error validasi per field (path dipisah titik, index array dalam kurung siku)
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var ErrInvalidSchema = errors.New("invalid schema")
+
+var knownTypes = map[string]bool{"": true, "object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true}
+
+// Parse dan cek schema (type dikenal, pattern valid). Schema details harus bertipe object
+func Parse(raw []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	if s.Type != "object" {
+		return nil, fmt.Errorf("%w: root type must be 'object'", ErrInvalidSchema)
+	}
+	if err := s.compile("$"); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *Schema) compile(path string) error {
+	if !knownTypes[s.Type] {
+		return fmt.Errorf("%w: unknown type '%s' at %s", ErrInvalidSchema, s.Type, path)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%w: bad pattern at %s", ErrInvalidSchema, path)
+		}
+		s.pattern = re
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok && s.AdditionalProperties != nil && !*s.AdditionalProperties {
+			return fmt.Errorf("%w: required property '%s' is not defined at %s", ErrInvalidSchema, name, path)
+		}
+	}
+	for name, prop := range s.Properties {
+		if prop == nil {
+			return fmt.Errorf("%w: empty property '%s' at %s", ErrInvalidSchema, name, path)
+		}
+		if err := prop.compile(path + "." + name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile(path + "[]")
+	}
+	return nil
+}
+
+// Validasi nilai (hasil decode JSON / BSON) terhadap schema. Error diurutkan per field
+func (s *Schema) Validate(value interface{}, field string) []FieldError {
+	var errs []FieldError
+	s.validate(value, field, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func (s *Schema) validate(value interface{}, field string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !matchesType(s.Type, value) {
+		fail("must be of type %s", s.Type)
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of: %s", enumList(s.Enum))
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if child, ok := v[name]; !ok || child == nil || child == "" {
+				*errs = append(*errs, FieldError{Field: join(field, name), Message: "is required"})
+			}
+		}
+		for name, child := range v {
+			if prop, ok := s.Properties[name]; ok {
+				if child != nil {
+					prop.validate(child, join(field, name), errs)
+				}
+			} else if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				*errs = append(*errs, FieldError{Field: join(field, name), Message: "is not allowed"})
+			}
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("does not match the required format")
+		}
+		if msg := checkFormat(s.Format, v); msg != "" {
+			fail("%s", msg)
+		}
+	default:
+		if n, ok := toFloat(value); ok {
+			if s.Minimum != nil && n < *s.Minimum {
+				fail("must be >= %v", *s.Minimum)
+			}
+			if s.Maximum != nil && n > *s.Maximum {
+				fail("must be <= %v", *s.Maximum)
+			}
+		}
+	}
+}
+
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	}
+	return true
+}
+
+// Angka dari JSON (float64) maupun BSON (int32/int64)
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+		if a, ok := toFloat(e); ok {
+			if b, ok := toFloat(value); ok && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func enumList(enum []interface{}) string {
+	parts := make([]string, len(enum))
+	for i, e := range enum {
+		parts[i] = fmt.Sprint(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func checkFormat(format, v string) string {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			return "must be an RFC3339 date-time"
+		}
+	case "uri":
+		if !strings.HasPrefix(v, "http://") && !strings.HasPrefix(v, "https://") {
+			return "must be an http(s) URL"
+		}
+	}
+	return ""
+}
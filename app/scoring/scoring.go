@@ -0,0 +1,197 @@
+package scoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Aturan poin satu versi. Contoh:
+//
+//	{
+//	  "types": {
+//	    "competition": {
+//	      "components":  [{"field": "competitionLevel", "values": {"international": 50, "national": 30}}],
+//	      "multipliers": [{"field": "rank", "ranges": [{"max": 1, "value": 1}, {"min": 2, "max": 3, "value": 0.8}], "default": 0.5}]
+//	    }
+//	  }
+//	}
+//
+// Poin = (base + jumlah components) × hasil kali multipliers, dibatasi min/max lalu dibulatkan.
+// Jenis yang tidak punya aturan memakai poin default dari katalog jenis prestasi
+type RuleSet struct {
+	Types map[string]*TypeRule `json:"types"`
+}
+
+type TypeRule struct {
+	Base        float64     `json:"base"`
+	Components  []Component `json:"components,omitempty"`
+	Multipliers []Component `json:"multipliers,omitempty"`
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
+}
+
+// Nilai dari satu field details: lookup nilai (values) atau rentang angka (ranges)
+type Component struct {
+	Field   string             `json:"field"`
+	Values  map[string]float64 `json:"values,omitempty"`
+	Ranges  []Range            `json:"ranges,omitempty"`
+	Default float64            `json:"default"`
+}
+
+// Rentang inklusif, batas nil berarti terbuka
+type Range struct {
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+	Value float64  `json:"value"`
+}
+
+// Hasil perhitungan beserta rinciannya (ditampilkan ke user / admin)
+type Result struct {
+	Points    int    `json:"points"`
+	Rule      string `json:"rule"` // "type" atau "default"
+	Breakdown []Line `json:"breakdown"`
+}
+
+type Line struct {
+	Kind  string      `json:"kind"` // base, component, multiplier, clamp
+	Field string      `json:"field,omitempty"`
+	Input interface{} `json:"input,omitempty"`
+	Value float64     `json:"value"`
+}
+
+var ErrInvalidRules = errors.New("invalid point rules")
+
+// Parse dan cek aturan poin
+func Parse(raw []byte) (*RuleSet, error) {
+	var rs RuleSet
+	if err := json.Unmarshal(raw, &rs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+	for code, rule := range rs.Types {
+		if rule == nil {
+			return nil, fmt.Errorf("%w: empty rule for type '%s'", ErrInvalidRules, code)
+		}
+		for _, c := range append(append([]Component{}, rule.Components...), rule.Multipliers...) {
+			if c.Field == "" {
+				return nil, fmt.Errorf("%w: component without field in type '%s'", ErrInvalidRules, code)
+			}
+			if len(c.Values) == 0 && len(c.Ranges) == 0 {
+				return nil, fmt.Errorf("%w: component '%s' in type '%s' needs values or ranges", ErrInvalidRules, c.Field, code)
+			}
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+			return nil, fmt.Errorf("%w: min > max in type '%s'", ErrInvalidRules, code)
+		}
+	}
+	return &rs, nil
+}
+
+// Hitung poin dari jenis & details prestasi. RuleSet nil (belum ada aturan aktif)
+// atau jenis tanpa aturan memakai poin default jenis
+func (rs *RuleSet) Compute(achievementType string, details map[string]interface{}, defaultPoints int) Result {
+	var rule *TypeRule
+	if rs != nil {
+		rule = rs.Types[achievementType]
+	}
+	if rule == nil {
+		return Result{
+			Points:    defaultPoints,
+			Rule:      "default",
+			Breakdown: []Line{{Kind: "base", Value: float64(defaultPoints)}},
+		}
+	}
+
+	res := Result{Rule: "type", Breakdown: []Line{{Kind: "base", Value: rule.Base}}}
+	total := rule.Base
+	for _, c := range rule.Components {
+		input, v := c.evaluate(details)
+		total += v
+		res.Breakdown = append(res.Breakdown, Line{Kind: "component", Field: c.Field, Input: input, Value: v})
+	}
+	for _, c := range rule.Multipliers {
+		input, v := c.evaluate(details)
+		total *= v
+		res.Breakdown = append(res.Breakdown, Line{Kind: "multiplier", Field: c.Field, Input: input, Value: v})
+	}
+
+	if rule.Min != nil && total < *rule.Min {
+		total = *rule.Min
+		res.Breakdown = append(res.Breakdown, Line{Kind: "clamp", Value: total})
+	}
+	if rule.Max != nil && total > *rule.Max {
+		total = *rule.Max
+		res.Breakdown = append(res.Breakdown, Line{Kind: "clamp", Value: total})
+	}
+	if total < 0 {
+		total = 0
+	}
+	res.Points = int(math.Round(total))
+	return res
+}
+
+// Ambil nilai field (boleh path bertitik, mis. "indexing.tier") lalu cocokkan ke values / ranges
+func (c Component) evaluate(details map[string]interface{}) (interface{}, float64) {
+	input := lookup(details, c.Field)
+	if input == nil {
+		return nil, c.Default
+	}
+
+	if len(c.Values) > 0 {
+		if v, ok := c.Values[strings.ToLower(fmt.Sprint(input))]; ok {
+			return input, v
+		}
+		if v, ok := c.Values[fmt.Sprint(input)]; ok {
+			return input, v
+		}
+	}
+	if n, ok := toFloat(input); ok {
+		for _, r := range c.Ranges {
+			if (r.Min == nil || n >= *r.Min) && (r.Max == nil || n <= *r.Max) {
+				return input, r.Value
+			}
+		}
+	}
+	return input, c.Default
+}
+
+func lookup(details map[string]interface{}, path string) interface{} {
+	var current interface{} = details
+	for _, key := range strings.Split(path, ".") {
+		switch m := current.(type) {
+		case map[string]interface{}:
+			current = m[key]
+		case primitive.M:
+			current = m[key]
+		case primitive.D: // Sub-dokumen hasil decode BSON
+			current = nil
+			for _, e := range m {
+				if e.Key == key {
+					current = e.Value
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// Angka dari JSON (float64) maupun BSON (int32/int64)
+func toFloat(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
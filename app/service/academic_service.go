@@ -7,6 +7,8 @@ import (
 
 type AcademicService struct {
 	Repo repoPG.IAcademicRepoPG
+
+	Notifier *NotificationService // Opsional (nil = tanpa notifikasi)
 }
 
 func NewAcademicService(repo repoPG.IAcademicRepoPG) *AcademicService {
@@ -51,7 +53,7 @@ func (s *AcademicService) GetStudentByID(c *fiber.Ctx) error {
 
 // UpdateStudentAdvisor godoc
 // @Summary      Update Student Advisor
-// @Description  Admin menugaskan atau mengubah Dosen Wali untuk mahasiswa tertentu
+// @Description  Admin menugaskan atau mengubah Dosen Wali untuk mahasiswa tertentu. Mahasiswa, dosen wali baru & lama dinotifikasi; jika dosen wali sama tidak ada notifikasi
 // @Tags         Academic
 // @Security     BearerAuth
 // @Accept       json
@@ -60,6 +62,7 @@ func (s *AcademicService) GetStudentByID(c *fiber.Ctx) error {
 // @Param        body  body      map[string]string  true  "Request Body: { \"advisor_id\": \"UUID_DOSEN\" }"
 // @Success      200   {object}  map[string]interface{}
 // @Failure      400   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
 // @Failure      500   {object}  map[string]interface{}
 // @Router       /students/{id}/advisor [put]
 func (s *AcademicService) UpdateStudentAdvisor(c *fiber.Ctx) error {
@@ -71,9 +74,16 @@ func (s *AcademicService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid input"})
 	}
 	
-	if err := s.Repo.UpdateStudentAdvisor(c.Params("id"), r.AdvisorID); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update advisor"})
+	actorID, _ := c.Locals("user_id").(string)
+	ev, err := s.Repo.UpdateStudentAdvisor(c.Params("id"), r.AdvisorID, actorID)
+	if err != nil {
+		return notFoundOr500(c, err, "Student not found", "Failed to update advisor")
+	}
+	// Dosen wali sama dengan sebelumnya: tidak ada notifikasi
+	if ev == nil {
+		return c.JSON(fiber.Map{"message": "Advisor unchanged"})
 	}
+	s.Notifier.Publish(*ev)
 	return c.JSON(fiber.Map{"message": "Advisor updated successfully"})
 }
 
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"be_uas/app/listing"
+	modelMongo "be_uas/app/model/mongodb"
+	modelPG "be_uas/app/model/postgres"
+	repoMongo "be_uas/app/repository/mongodb"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/workflow"
+	"be_uas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var listableStatuses = map[string]bool{
+	workflow.StatusDraft: true, workflow.StatusSubmitted: true, workflow.StatusVerified: true,
+	workflow.StatusRejected: true, workflow.StatusDeleted: true, workflow.StatusNeedsRevision: true,
+}
+
+// Parse query string listing prestasi (filter, sort & cursor). Scope tiap endpoint diisi oleh handler
+func parseListingQuery(c *fiber.Ctx) (listing.Query, error) {
+	q := listing.Query{
+		SortBy: c.Query("sort", listing.SortCreatedAt),
+		Limit:  listing.DefaultLimit,
+	}
+	f := &q.Filter
+
+	if !listing.ValidSort(q.SortBy) {
+		return q, fmt.Errorf("sort must be one of: %s, %s, %s, %s", listing.SortCreatedAt, listing.SortUpdatedAt, listing.SortPoints, listing.SortTitle)
+	}
+	switch c.Query("order", "desc") {
+	case "desc":
+		q.Desc = true
+	case "asc":
+	default:
+		return q, errors.New("order must be 'asc' or 'desc'")
+	}
+
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return q, errors.New("limit must be a positive number")
+		}
+		if n > listing.MaxLimit {
+			n = listing.MaxLimit
+		}
+		q.Limit = n
+	}
+
+	if v := c.Query("status"); v != "" {
+		for _, st := range strings.Split(v, ",") {
+			st = strings.TrimSpace(st)
+			if !listableStatuses[st] {
+				return q, fmt.Errorf("unknown status '%s'", st)
+			}
+			f.Statuses = append(f.Statuses, st)
+		}
+	}
+
+	var err error
+	if f.CreatedFrom, err = parseDateParam(c.Query("from"), false); err != nil {
+		return q, errors.New("from must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+	}
+	if f.CreatedTo, err = parseDateParam(c.Query("to"), true); err != nil {
+		return q, errors.New("to must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+	}
+	if f.MinPoints, err = parseIntParam(c.Query("min_points")); err != nil {
+		return q, errors.New("min_points must be a number")
+	}
+	if f.MaxPoints, err = parseIntParam(c.Query("max_points")); err != nil {
+		return q, errors.New("max_points must be a number")
+	}
+
+	f.AchievementType = c.Query("type")
+	f.Tag = c.Query("tag")
+	f.StudentID = c.Query("student_id")
+	f.ProgramStudy = c.Query("program_study")
+	f.AdvisorID = c.Query("advisor_id")
+
+	if v := c.Query("cursor"); v != "" {
+		if q.After, err = listing.DecodeCursor(v, q.SortBy, q.Desc); err != nil {
+			return q, err
+		}
+	}
+	return q, nil
+}
+
+// Tanggal saja (YYYY-MM-DD) untuk batas akhir berarti sampai akhir hari tersebut
+func parseDateParam(v string, endOfDay bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+func parseIntParam(v string) (*int, error) {
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Batas jumlah reference dalam scope yang dikirim ke MongoDB sebagai daftar ID ($in).
+// Scope yang lebih besar di-paging dari sisi MongoDB per batch lalu dicocokkan ke PostgreSQL
+const (
+	mongoSortScopeMax  = 2000
+	mongoSortBatchSize = 200
+)
+
+// Jalankan query listing di kedua store dan gabungkan hasilnya sesuai urutan sort.
+// Sort field PostgreSQL: filter konten dijalankan dulu di MongoDB (daftar ID), lalu PostgreSQL yang mem-paging.
+// Sort field MongoDB: filter PostgreSQL (scope, status, dll) dijalankan dulu, lalu MongoDB yang mem-paging
+func listAchievements(ctx context.Context, pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchievementRepoMongo, q listing.Query, base func(ref modelPG.AchievementReference) map[string]interface{}) ([]map[string]interface{}, string, error) {
+	if q.SortInMongo() {
+		refs, err := pg.FindReferencesByFilterLimit(q.Filter, mongoSortScopeMax+1)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(refs) > mongoSortScopeMax {
+			return scanSortedAchievements(ctx, pg, mongo, q, base)
+		}
+		results := []map[string]interface{}{}
+		if len(refs) == 0 {
+			return results, "", nil
+		}
+
+		byMongoID := make(map[string]modelPG.AchievementReference, len(refs))
+		ids := make([]string, len(refs))
+		for i, ref := range refs {
+			byMongoID[ref.MongoAchievementID] = ref
+			ids[i] = ref.MongoAchievementID
+		}
+		docs, next, err := mongo.QueryAchievements(ctx, q, ids)
+		if err != nil {
+			return nil, "", err
+		}
+		for i := range docs {
+			item := base(byMongoID[docs[i].ID.Hex()])
+			item["detail"] = &docs[i]
+			results = append(results, item)
+		}
+		return results, next, nil
+	}
+
+	if q.Filter.HasMongoFilter() {
+		ids, err := mongo.FindIDsByFilter(ctx, q.Filter)
+		if err != nil {
+			return nil, "", err
+		}
+		q.Filter.MongoIDs = ids
+	}
+	refs, next, err := pg.QueryAchievements(q)
+	if err != nil {
+		return nil, "", err
+	}
+	results, err := hydrateDetails(ctx, mongo, refs, base)
+	if err != nil {
+		return nil, "", err
+	}
+	return results, next, nil
+}
+
+// Scope besar dengan sort field MongoDB: telusuri dokumen sesuai urutan sort (keyset) per batch,
+// ambil hanya yang reference-nya masuk scope sampai satu halaman terisi
+func scanSortedAchievements(ctx context.Context, pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchievementRepoMongo, q listing.Query, base func(ref modelPG.AchievementReference) map[string]interface{}) ([]map[string]interface{}, string, error) {
+	results := []map[string]interface{}{}
+	var last *modelMongo.Achievement
+	batch := q
+	batch.Limit = max(q.Limit+1, mongoSortBatchSize)
+	for {
+		docs, next, err := mongo.QueryAchievements(ctx, batch, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(docs) == 0 {
+			return results, "", nil
+		}
+
+		scope := q.Filter
+		scope.MongoIDs = make([]string, len(docs))
+		for i, doc := range docs {
+			scope.MongoIDs[i] = doc.ID.Hex()
+		}
+		refs, err := pg.FindReferencesByFilter(scope)
+		if err != nil {
+			return nil, "", err
+		}
+		byMongoID := make(map[string]modelPG.AchievementReference, len(refs))
+		for _, ref := range refs {
+			byMongoID[ref.MongoAchievementID] = ref
+		}
+
+		for i := range docs {
+			ref, ok := byMongoID[docs[i].ID.Hex()]
+			if !ok {
+				continue
+			}
+			// Masih ada item setelah halaman ini: cursor menunjuk item terakhir yang dikembalikan
+			if len(results) == q.Limit {
+				return results, repoMongo.SortCursor(q, *last).Encode(), nil
+			}
+			last = &docs[i]
+			item := base(ref)
+			item["detail"] = last
+			results = append(results, item)
+		}
+
+		if next == "" {
+			return results, "", nil
+		}
+		if batch.After, err = listing.DecodeCursor(next, q.SortBy, q.Desc); err != nil {
+			return nil, "", err
+		}
+	}
+}
+
+// Response listing standar: data + metadata cursor
+func listingResponse(c *fiber.Ctx, results []map[string]interface{}, q listing.Query, next string, err error) error {
+	if err != nil {
+		if errors.Is(err, listing.ErrInvalidCursor) {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievements"})
+	}
+	return c.JSON(fiber.Map{
+		"data": results,
+		"meta": fiber.Map{
+			"limit":       q.Limit,
+			"sort":        q.SortBy,
+			"next_cursor": next,
+		},
+	})
+}
+
+var errNotStudent = errors.New("user is not a student")
+
+// Scope visibilitas prestasi sesuai permission: akses penuh semua, hak verifikasi (Dosen Wali)
+// prestasi mahasiswa bimbingan yang sudah diajukan, selain itu hanya milik sendiri (harus mahasiswa)
+func visibilityScope(c *fiber.Ctx, pg repoPG.IAchievementRepoPG) (listing.Filter, bool, error) {
+	userID, _ := c.Locals("user_id").(string)
+	switch {
+	case middleware.HasFullAccess(c):
+		return listing.Filter{HiddenStatuses: []string{workflow.StatusDeleted}}, true, nil
+	case middleware.HasPermission(c, workflow.PermVerify):
+		return listing.Filter{
+			AdvisorUserID:  userID,
+			HiddenStatuses: []string{workflow.StatusDraft, workflow.StatusDeleted},
+		}, false, nil
+	default:
+		studentID, err := pg.GetStudentIDByUserID(userID)
+		if err != nil {
+			return listing.Filter{}, false, errNotStudent
+		}
+		return listing.Filter{StudentID: studentID, HiddenStatuses: []string{workflow.StatusDeleted}}, false, nil
+	}
+}
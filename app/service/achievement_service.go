@@ -1,14 +1,29 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"be_uas/app/listing"
 	modelMongo "be_uas/app/model/mongodb"
 	modelPG "be_uas/app/model/postgres"
 	repoMongo "be_uas/app/repository/mongodb"
 	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/schema"
+	"be_uas/app/storage"
+	"be_uas/app/workflow"
+	"be_uas/utils"
+	"be_uas/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -17,12 +32,22 @@ import (
 type AchievementService struct {
 	RepoPG    repoPG.IAchievementRepoPG
 	RepoMongo repoMongo.IAchievementRepoMongo
+	Outbox    repoPG.IOutboxRepoPG
+	Types     repoPG.IAchievementTypeRepoPG
+	Rules     repoPG.IPointRuleRepoPG
+	Storage   storage.Storage
+
+	Notifier *NotificationService // Opsional (nil = tanpa notifikasi)
 }
 
-func NewAchievementService(pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchievementRepoMongo) *AchievementService {
+func NewAchievementService(pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchievementRepoMongo, outbox repoPG.IOutboxRepoPG, types repoPG.IAchievementTypeRepoPG, rules repoPG.IPointRuleRepoPG, files storage.Storage) *AchievementService {
 	return &AchievementService{
 		RepoPG:    pg,
 		RepoMongo: mongo,
+		Outbox:    outbox,
+		Types:     types,
+		Rules:     rules,
+		Storage:   files,
 	}
 }
 
@@ -32,7 +57,19 @@ func NewAchievementService(pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchiev
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Produce      json
-// @Success      200  {object} map[string]interface{} "Data berisi array prestasi: id, status, detail (dari mongo)"
+// @Param        status         query  string  false  "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)"
+// @Param        type           query  string  false  "Filter achievement type"
+// @Param        tag            query  string  false  "Filter tag"
+// @Param        from           query  string  false  "Dibuat sejak (YYYY-MM-DD / RFC3339)"
+// @Param        to             query  string  false  "Dibuat sampai (YYYY-MM-DD / RFC3339)"
+// @Param        min_points     query  int     false  "Poin minimum"
+// @Param        max_points     query  int     false  "Poin maksimum"
+// @Param        sort           query  string  false  "created_at | updated_at | points | title" default(created_at)
+// @Param        order          query  string  false  "asc | desc" default(desc)
+// @Param        limit          query  int     false  "Jumlah item per halaman (maks 100)" default(20)
+// @Param        cursor         query  string  false  "Cursor dari meta.next_cursor halaman sebelumnya"
+// @Success      200  {object} map[string]interface{} "Data berisi array prestasi: id, status, detail (dari mongo), atau error jika detail tidak ditemukan. meta.next_cursor untuk halaman berikutnya"
+// @Failure      400  {object} map[string]interface{} "Error: Query tidak valid"
 // @Failure      403  {object} map[string]interface{} "Error: User is not a student"
 // @Failure      500  {object} map[string]interface{} "Error: Failed to fetch achievements"
 // @Router       /achievements [get]
@@ -45,30 +82,171 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"error": "User is not a student"})
 	}
 
-	refs, err := s.RepoPG.GetAchievementsByStudentID(studentID)
+	q, err := parseListingQuery(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievements"})
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	// Scope: hanya prestasi milik sendiri yang belum dihapus
+	q.Filter.StudentID = studentID
+	q.Filter.HiddenStatuses = []string{workflow.StatusDeleted}
 
-	var results []map[string]interface{}
-	ctx := context.Background()
+	results, next, err := listAchievements(context.Background(), s.RepoPG, s.RepoMongo, q, referenceItem)
+	return listingResponse(c, results, q, next, err)
+}
+
+// Pengaturan pencarian full-text
+const (
+	SearchDefaultLimit  = 20
+	SearchMaxLimit      = 50
+	SearchMaxQueryLen   = 200
+	SearchSnippetRadius = 60
+)
+
+// SearchAchievements godoc
+// @Summary      Search Achievements
+// @Description  Pencarian full-text pada judul, deskripsi, tag, dan beberapa field details (urut berdasarkan relevansi, dengan potongan teks yang di-highlight). Hasil mengikuti visibilitas role: Mahasiswa milik sendiri, Dosen Wali mahasiswa bimbingan, Admin semua.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        q      query  string  true   "Kata kunci (mendukung frasa dalam tanda kutip dan -pengecualian)"
+// @Param        page   query  int     false  "Page Number" default(1)
+// @Param        limit  query  int     false  "Items per Page (maks 50)" default(20)
+// @Success      200  {object} map[string]interface{} "Format: { data: [{ id, student_id, status, score, highlights: {field: snippet}, detail }], meta: {page, limit} }"
+// @Failure      400  {object} map[string]interface{} "Error: Query kosong / terlalu panjang"
+// @Failure      403  {object} map[string]interface{} "Error: User is not a student"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /achievements/search [get]
+func (s *AchievementService) SearchAchievements(c *fiber.Ctx) error {
+	text := strings.TrimSpace(c.Query("q"))
+	if text == "" || len(text) > SearchMaxQueryLen {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("q is required (max %d characters)", SearchMaxQueryLen)})
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(SearchDefaultLimit)))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > SearchMaxLimit {
+		limit = SearchDefaultLimit
+	}
 
+	scope, all, err := visibilityScope(c, s.RepoPG)
+	if err != nil {
+		return c.Status(403).JSON(fiber.Map{"error": "User is not a student"})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Role terbatas: cari hanya di dalam scope-nya. Admin: cari dulu, lalu cocokkan dengan reference
+	var hits []modelMongo.AchievementSearchHit
+	var refs []modelPG.AchievementReference
+	if all {
+		hits, refs, err = s.searchVisible(ctx, text, scope, page, limit)
+	} else if refs, err = s.RepoPG.FindReferencesByFilter(scope); err == nil && len(refs) > 0 {
+		ids := make([]string, len(refs))
+		for i, ref := range refs {
+			ids[i] = ref.MongoAchievementID
+		}
+		hits, err = s.RepoMongo.SearchAchievements(ctx, text, ids, (page-1)*limit, limit)
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to search achievements"})
+	}
+
+	byMongoID := make(map[string]modelPG.AchievementReference, len(refs))
 	for _, ref := range refs {
-		detail, _ := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
-		item := map[string]interface{}{
+		byMongoID[ref.MongoAchievementID] = ref
+	}
+	terms := utils.SearchTerms(text)
+	results := []map[string]interface{}{}
+	for i := range hits {
+		ref, ok := byMongoID[hits[i].ID.Hex()]
+		if !ok {
+			continue // Tidak terlihat oleh role ini (mis. reference sudah dihapus)
+		}
+		results = append(results, map[string]interface{}{
 			"id":         ref.ID,
+			"student_id": ref.StudentID,
 			"status":     ref.Status,
-			"created_at": ref.CreatedAt,
-			"detail":     detail,
+			"score":      hits[i].Score,
+			"highlights": searchHighlights(&hits[i].Achievement, terms),
+			"detail":     &hits[i].Achievement,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": results,
+		"meta": fiber.Map{"page": page, "limit": limit},
+	})
+}
+
+// Batas hit yang diambil per putaran saat mencari tanpa batasan ID
+const searchBatchMax = 200
+
+// Cari di semua dokumen lalu buang hit yang reference-nya tersembunyi (mis. sudah dihapus).
+// Halaman dihitung dari hit yang terlihat, sehingga hit tersembunyi tidak membuat halaman terpotong
+func (s *AchievementService) searchVisible(ctx context.Context, text string, scope listing.Filter, page, limit int) ([]modelMongo.AchievementSearchHit, []modelPG.AchievementReference, error) {
+	want := page * limit
+	batch := min(want, searchBatchMax)
+	var visible []modelMongo.AchievementSearchHit
+	var refs []modelPG.AchievementReference
+	for skip := 0; len(visible) < want; skip += batch {
+		hits, err := s.RepoMongo.SearchAchievements(ctx, text, nil, skip, batch)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(hits) == 0 {
+			break
+		}
+
+		scope.MongoIDs = make([]string, len(hits))
+		for i, h := range hits {
+			scope.MongoIDs[i] = h.ID.Hex()
+		}
+		found, err := s.RepoPG.FindReferencesByFilter(scope)
+		if err != nil {
+			return nil, nil, err
+		}
+		known := make(map[string]bool, len(found))
+		for _, ref := range found {
+			known[ref.MongoAchievementID] = true
+		}
+		for _, h := range hits {
+			if known[h.ID.Hex()] {
+				visible = append(visible, h)
+			}
+		}
+		refs = append(refs, found...)
+		if len(hits) < batch {
+			break
 		}
-		results = append(results, item)
 	}
 
-	if results == nil {
-		results = []map[string]interface{}{}
+	start := (page - 1) * limit
+	if start >= len(visible) {
+		return nil, refs, nil
 	}
+	return visible[start:min(start+limit, len(visible))], refs, nil
+}
 
-	return c.JSON(fiber.Map{"data": results})
+// Potongan teks ter-highlight per field yang mengandung kata kunci
+func searchHighlights(a *modelMongo.Achievement, terms []string) map[string]string {
+	highlights := map[string]string{}
+	add := func(field, text string) {
+		if snippet, ok := utils.Highlight(text, terms, SearchSnippetRadius); ok {
+			highlights[field] = snippet
+		}
+	}
+
+	add("title", a.Title)
+	add("description", a.Description)
+	add("tags", strings.Join(a.Tags, ", "))
+	for _, f := range repoMongo.SearchableDetailFields {
+		if v, ok := a.Details[f].(string); ok {
+			add("details."+f, v)
+		}
+	}
+	return highlights
 }
 
 // GetAchievementByID godoc
@@ -79,7 +257,7 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 // @Accept       json
 // @Produce      json
 // @Param        id   path      string  true  "Achievement Reference ID (UUID)"
-// @Success      200  {object}  map[string]interface{} "Structure: {data: {ref: ReferenceObj, detail: MongoObj}}"
+// @Success      200  {object}  map[string]interface{} "Structure: {data: {ref: ReferenceObj, detail: MongoObj, actions: [aksi yang tersedia], revision_comments: [komentar ronde terakhir jika needs_revision]}}"
 // @Failure      404  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /achievements/{id} [get]
@@ -92,77 +270,94 @@ func (s *AchievementService) GetAchievementByID(c *fiber.Ctx) error {
 	}
 
 	detail, err := s.RepoMongo.FindAchievementByID(context.Background(), ref.MongoAchievementID)
-	
-	return c.JSON(fiber.Map{
-		"data": fiber.Map{
-			"ref":    ref,
-			"detail": detail,
-		},
-	})
+	permissions, _ := c.Locals("permissions").([]string)
+
+	data := fiber.Map{
+		"ref":     ref,
+		"detail":  detail,
+		"actions": workflow.AvailableActions(ref.Status, permissions),
+	}
+	// Mahasiswa perlu melihat komentar yang harus diperbaiki pada ronde ini
+	if ref.Status == workflow.StatusNeedsRevision {
+		comments, err := s.RepoPG.GetRevisionComments(ref.ID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch revision comments"})
+		}
+		current := []modelPG.RevisionComment{}
+		for _, cm := range comments {
+			if cm.Round == ref.ReviewRound {
+				current = append(current, cm)
+			}
+		}
+		data["revision_comments"] = current
+	}
+
+	return c.JSON(fiber.Map{"data": data})
 }
 
 // GetAchievementHistory godoc
 // @Summary      Get Status History
-// @Description  Melihat timeline riwayat perubahan status prestasi (Draft -> Submitted -> Verified/Rejected)
+// @Description  Melihat timeline riwayat perubahan status prestasi dari log transisi (termasuk resubmit & penolakan berulang). Tiap entri membawa ronde review; entri needs_revision menyertakan komentar per field ronde tersebut
 // @Tags         Achievements
 // @Security     BearerAuth
-// @Param        id   path      string  true  "Achievement Ref ID"
+// @Param        id     path      string  true   "Achievement Ref ID"
+// @Param        page   query     int     false  "Page Number" default(1)
+// @Param        limit  query     int     false  "Items per Page" default(20)
 // @Produce      json
-// @Success      200  {object} map[string]interface{} "Response format: {data: [{status, timestamp, note}]}"
+// @Success      200  {object} map[string]interface{} "Response format: {data: [{from_status, to_status, actor_id, actor_name, note, round, comments, created_at}], meta: {page, limit, total_data, total_page}}"
 // @Failure      404  {object} map[string]interface{}
+// @Failure      500  {object} map[string]interface{}
 // @Router       /achievements/{id}/history [get]
 func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
 	id := c.Params("id")
-	ref, err := s.RepoPG.GetReferenceByID(id)
-	if err != nil { 
+	if _, err := s.RepoPG.GetReferenceByID(id); err != nil { 
 		return c.Status(404).JSON(fiber.Map{"error": "Not found"}) 
 	}
 
-	var history []map[string]interface{}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
 
-	// 1. Created
-	history = append(history, map[string]interface{}{
-		"status":    "draft",
-		"timestamp": ref.CreatedAt,
-		"note":      "Achievement created",
-	})
+	history, total, err := s.RepoPG.GetStatusHistory(id, limit, offset)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch history"})
+	}
 
-	// 2. Submitted
-	if ref.SubmittedAt != nil { 
-		history = append(history, map[string]interface{}{
-			"status":    "submitted",
-			"timestamp": ref.SubmittedAt,
-			"note":      "Submitted for verification",
-		})
+	if history == nil {
+		history = []modelPG.AchievementStatusHistory{}
 	}
-	
-	// 3. Final Status
-	if ref.Status == "verified" && ref.VerifiedAt != nil {
-		history = append(history, map[string]interface{}{
-			"status":    "verified",
-			"timestamp": ref.VerifiedAt,
-			"note":      "Verified by Lecturer",
-		})
-	} else if ref.Status == "rejected" && ref.VerifiedAt != nil {
-		history = append(history, map[string]interface{}{
-			"status":    "rejected",
-			"timestamp": ref.VerifiedAt,
-			"note":      "Rejected: " + getString(ref.RejectionNote),
-		})
+	if err := s.attachRevisionComments(id, history); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch revision comments"})
 	}
 
-	return c.JSON(fiber.Map{"data": history})
+	return c.JSON(fiber.Map{
+		"data": history,
+		"meta": fiber.Map{
+			"page":       page,
+			"limit":      limit,
+			"total_data": total,
+			"total_page": (total + limit - 1) / limit,
+		},
+	})
 }
 
 // CreateAchievement godoc
 // @Summary      Create Achievement (Draft)
-// @Description  Mahasiswa input prestasi, atau Admin inputkan untuk mahasiswa
+// @Description  Mahasiswa input prestasi, atau Admin inputkan untuk mahasiswa. Poin dihitung server dari aturan poin aktif (field points diabaikan)
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        body body mongodb.Achievement true "Achievement Data"
 // @Success      201  {object} map[string]interface{}
+// @Failure      403  {object} map[string]interface{} "Error: Bukan mahasiswa / bukan mahasiswa bimbingan"
+// @Failure      422  {object} map[string]interface{} "Error: Validasi gagal, fields: [{field, message}]"
 // @Router       /achievements [post]
 func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 	var req modelMongo.Achievement
@@ -170,12 +365,13 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid Input"})
 	}
 
-	userRole := c.Locals("role").(string)
 	userID := c.Locals("user_id").(string)
+	fullAccess := middleware.HasFullAccess(c)
 	var studentID string
 	var err error
 
-	if userRole == "Mahasiswa" {
+	// Tanpa akses penuh / hak verifikasi: prestasi untuk diri sendiri (mahasiswa)
+	if !fullAccess && !middleware.HasPermission(c, workflow.PermVerify) {
 		studentID, err = s.RepoPG.GetStudentIDByUserID(userID)
 		if err != nil {
 			return c.Status(403).JSON(fiber.Map{"error": "User is not registered as a student"})
@@ -185,14 +381,33 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 			return c.Status(400).JSON(fiber.Map{"error": "Admin/Dosen must provide studentId in body"})
 		}
 		studentID = req.StudentID
+
+		// Dosen hanya boleh menginput prestasi untuk mahasiswa bimbingannya
+		if !fullAccess {
+			ok, err := s.RepoPG.IsStudentAdvisedBy(studentID, userID)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{"error": "Failed to check advisor scope"})
+			}
+			if !ok {
+				return c.Status(403).JSON(fiber.Map{"error": "Forbidden: student is not your advisee"})
+			}
+		}
 	}
 
-	if req.Attachments == nil {
-		req.Attachments = []modelMongo.Attachment{}
+	// Lampiran hanya lewat endpoint upload (file harus tervalidasi & tersimpan di storage)
+	req.Attachments = []modelMongo.Attachment{}
+
+	achievementType, fieldErrs, err := s.validateContent(&req, false)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to validate achievement"})
+	}
+	if len(fieldErrs) > 0 {
+		return validationFailed(c, fieldErrs)
 	}
 
-	if req.Points == 0 {
-		req.Points = 10 
+	// Poin dihitung server dari aturan aktif, nilai dari client diabaikan
+	if err := s.applyPoints(&req, achievementType.DefaultPoints); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to compute points"})
 	}
 
 	req.StudentID = studentID
@@ -210,12 +425,17 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		ID:                 refID,
 		StudentID:          studentID,
 		MongoAchievementID: mongoID,
-		Status:             "draft",
+		Status:             workflow.StatusDraft,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
 
-	if err := s.RepoPG.CreateReference(ref); err != nil {
+	if err := s.RepoPG.CreateReference(ref, userID); err != nil {
+		// Kompensasi: hapus dokumen MongoDB agar tidak menjadi yatim.
+		// Jika kompensasi juga gagal, dokumen dibersihkan oleh job rekonsiliasi
+		if cerr := s.RepoMongo.DeleteAchievement(ctx, mongoID); cerr != nil {
+			log.Printf("Failed to compensate mongo achievement %s: %v\n", mongoID, cerr)
+		}
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to save reference to PostgreSQL"})
 	}
 
@@ -238,9 +458,11 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 // @Param        id    path      string               true  "Achievement Ref ID (UUID)"
 // @Param        body  body      mongodb.Achievement  true  "Data Update (Title, Description, Details, etc)"
 // @Success      200   {object}  map[string]interface{}  "message: Achievement updated"
-// @Failure      400   {object}  map[string]interface{}  "Error: Invalid Input atau Status bukan Draft"
+// @Failure      400   {object}  map[string]interface{}  "Error: Invalid Input"
 // @Failure      401   {object}  map[string]interface{}  "Error: Unauthorized"
 // @Failure      404   {object}  map[string]interface{}  "Error: Not found"
+// @Failure      409   {object}  map[string]interface{}  "Error: Status bukan Draft"
+// @Failure      422   {object}  map[string]interface{}  "Error: Validasi gagal, fields: [{field, message}]"
 // @Failure      500   {object}  map[string]interface{}  "Error: Internal Server Error"
 // @Router       /achievements/{id} [put]
 func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
@@ -248,18 +470,33 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 	ref, err := s.RepoPG.GetReferenceByID(id)
 	if err != nil { return c.Status(404).JSON(fiber.Map{"error": "Not found"}) }
 
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{"error": "Cannot update. Status is not draft"})
+	if err := s.transition(c, ref, workflow.ActionEdit, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to update achievement")
 	}
 
 	var req modelMongo.Achievement
 	if err := c.BodyParser(&req); err != nil { return c.Status(400).JSON(fiber.Map{"error": "Invalid Input"}) }
 
+	// Jenis yang sudah dinonaktifkan tetap boleh dipakai oleh prestasi lama
+	achievementType, fieldErrs, err := s.validateContent(&req, true)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to validate achievement"})
+	}
+	if len(fieldErrs) > 0 {
+		return validationFailed(c, fieldErrs)
+	}
+	if err := s.applyPoints(&req, achievementType.DefaultPoints); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to compute points"})
+	}
+
 	req.UpdatedAt = time.Now()
 
 	ctx := context.Background()
-	if err := s.RepoMongo.UpdateAchievement(ctx, ref.MongoAchievementID, req); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update content"})
+	err = s.RepoPG.LockForEdit(ref.ID, ref.Status, func() error {
+		return s.RepoMongo.UpdateAchievement(ctx, ref.MongoAchievementID, req)
+	})
+	if err != nil {
+		return transitionErrorResponse(c, err, "Failed to update content")
 	}
 
 	return c.JSON(fiber.Map{"message": "Achievement updated"})
@@ -273,9 +510,9 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "Achievement Ref ID"
 // @Produce      json
 // @Success      200  {object} map[string]interface{}
-// @Failure      400  {object} map[string]interface{} "Error: Only draft can be deleted"
 // @Failure      401  {object} map[string]interface{} "Error: Unauthorized"
 // @Failure      404  {object} map[string]interface{} "Error: Not found"
+// @Failure      409  {object} map[string]interface{} "Error: Only draft can be deleted"
 // @Failure      500  {object} map[string]interface{} "Error: Server failure"
 // @Router       /achievements/{id} [delete]
 func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
@@ -285,18 +522,16 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{"error": "Only draft achievements can be deleted"})
+	if err := s.transition(c, ref, workflow.ActionDelete, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to update status")
 	}
 
+	// Soft delete MongoDB sudah tercatat di outbox bersama perubahan status.
+	// Dijalankan langsung di sini; jika gagal, worker outbox akan mengulanginya
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	if err := s.RepoMongo.SoftDeleteAchievement(ctx, ref.MongoAchievementID); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete details"})
-	}
-
-	if err := s.RepoPG.UpdateStatus(id, "deleted"); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update status"})
+	if _, err := processOutbox(ctx, s.Outbox, s.RepoMongo, s.Storage, ref.ID, OutboxBatchSize); err != nil {
+		log.Println("Failed to dispatch achievement outbox:", err)
 	}
 
 	return c.JSON(fiber.Map{"message": "Achievement deleted successfully"})
@@ -304,14 +539,14 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 
 // SubmitAchievement godoc
 // @Summary      Submit to Advisor
-// @Description  Mengubah status prestasi dari 'draft' menjadi 'submitted' untuk diverifikasi Dosen Wali.
+// @Description  Mengubah status prestasi dari 'draft' / 'needs_revision' menjadi 'submitted' untuk diverifikasi Dosen Wali. Setiap pengajuan membuka ronde review baru
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement Ref ID"
 // @Produce      json
 // @Success      200  {object} map[string]interface{}
-// @Failure      400  {object} map[string]interface{}
 // @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{} "Error: Only draft achievements can be submitted / lampiran belum lolos scan antivirus"
 // @Failure      500  {object} map[string]interface{}
 // @Router       /achievements/{id}/submit [post]
 func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
@@ -321,20 +556,52 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	if ref.Status != "draft" {
-		return c.Status(400).JSON(fiber.Map{"error": "Only draft achievements can be submitted"})
+	if err := s.transition(c, ref, workflow.ActionSubmit, nil); err != nil {
+		var ue *unscannedAttachmentsError
+		if errors.As(err, &ue) {
+			return c.Status(409).JSON(fiber.Map{
+				"error":       "All attachments must pass the virus scan before submission",
+				"attachments": ue.Attachments,
+			})
+		}
+		if errors.Is(err, errAchievementDetailNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Achievement detail not found"})
+		}
+		return transitionErrorResponse(c, err, "Failed to update status")
+	}
+
+	return c.JSON(fiber.Map{"message": "Achievement submitted successfully"})
+}
+
+// ReviseAchievement godoc
+// @Summary      Revise Rejected Achievement
+// @Description  Mengembalikan prestasi yang ditolak ke status 'draft' agar bisa diperbaiki dan diajukan ulang.
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Achievement Ref ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{} "Error: Only rejected achievements can be revised"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /achievements/{id}/revise [post]
+func (s *AchievementService) ReviseAchievement(c *fiber.Ctx) error {
+	id := c.Params("id")
+	ref, err := s.RepoPG.GetReferenceByID(id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	if err := s.RepoPG.UpdateStatus(id, "submitted"); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update status"})
+	if err := s.transition(c, ref, workflow.ActionRevise, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to update status")
 	}
 
-	return c.JSON(fiber.Map{"message": "Achievement submitted successfully"})
+	return c.JSON(fiber.Map{"message": "Achievement returned to draft for revision"})
 }
 
 // UploadAttachment godoc
 // @Summary      Upload File Bukti
-// @Description  Upload gambar/pdf untuk prestasi
+// @Description  Upload bukti prestasi (pdf, png, jpg, webp; maks 10 MB). Tipe file dicek dari isinya, checksum SHA-256 disimpan di attachment. Lampiran berstatus pending sampai lolos scan antivirus
 // @Tags         Achievements
 // @Security     BearerAuth
 // @Accept       multipart/form-data
@@ -342,6 +609,10 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 // @Param        id   path      string  true  "Achievement Ref ID"
 // @Param        file formData  file    true  "File Bukti"
 // @Success      200  {object} map[string]interface{}
+// @Failure      400  {object} map[string]interface{} "Error: File is required"
+// @Failure      409  {object} map[string]interface{} "Error: Status bukan Draft"
+// @Failure      413  {object} map[string]interface{} "Error: File terlalu besar"
+// @Failure      415  {object} map[string]interface{} "Error: Tipe file tidak diizinkan / tidak sesuai isi"
 // @Router       /achievements/{id}/attachments [post]
 func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -350,36 +621,433 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	file, err := c.FormFile("file")
+	if err := s.transition(c, ref, workflow.ActionEdit, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to upload attachment")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	attachment, status, err := s.storeUpload(ctx, c, ref)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "File is required"})
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	uniqueName := fmt.Sprintf("%s_%s", uuid.New().String(), file.Filename)
-	filePath := fmt.Sprintf("./uploads/%s", uniqueName)
-	
-	if err := c.SaveFile(file, filePath); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to save file to server"})
+	err = s.RepoPG.LockForEdit(ref.ID, ref.Status, func() error {
+		return s.RepoMongo.AddAttachment(ctx, ref.MongoAchievementID, *attachment)
+	})
+	if err != nil {
+		// Kompensasi: file tanpa record tidak boleh tertinggal di storage
+		s.collectFiles(ctx, ref.ID, attachment.StorageKey)
+		return transitionErrorResponse(c, err, "Failed to update database record")
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "File uploaded and linked successfully",
+		"data":    attachment,
+	})
+}
+
+// ListAttachments godoc
+// @Summary      List File Bukti
+// @Description  Daftar lampiran prestasi. Akses sama dengan detail prestasi
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Achievement Ref ID"
+// @Success      200  {object} map[string]interface{} "Format: {data: [Attachment]}"
+// @Failure      404  {object} map[string]interface{}
+// @Router       /achievements/{id}/attachments [get]
+func (s *AchievementService) ListAttachments(c *fiber.Ctx) error {
+	ref, err := s.RepoPG.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+	doc, err := s.RepoMongo.FindAchievementByID(context.Background(), ref.MongoAchievementID)
+	if err != nil || doc == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement detail not found"})
 	}
 
-	attachment := modelMongo.Attachment{
-		FileName:   file.Filename,
-		FileURL:    "/uploads/" + uniqueName, 
-		FileType:   file.Header.Get("Content-Type"),
-		UploadedAt: time.Now(),
+	attachments := doc.Attachments
+	if attachments == nil {
+		attachments = []modelMongo.Attachment{}
 	}
+	return c.JSON(fiber.Map{"data": attachments})
+}
 
-	ctx := context.Background()
-	if err := s.RepoMongo.AddAttachment(ctx, ref.MongoAchievementID, attachment); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update database record", "details": err.Error()})
+// DeleteAttachment godoc
+// @Summary      Delete File Bukti
+// @Description  Menghapus lampiran selama prestasi masih bisa diedit (draft). File di storage ikut dihapus
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id             path  string  true  "Achievement Ref ID"
+// @Param        attachmentId   path  string  true  "Attachment ID"
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{} "Error: Status bukan Draft"
+// @Router       /achievements/{id}/attachments/{attachmentId} [delete]
+func (s *AchievementService) DeleteAttachment(c *fiber.Ctx) error {
+	ref, err := s.RepoPG.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+	if err := s.transition(c, ref, workflow.ActionEdit, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to delete attachment")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	old, status, err := s.editableAttachment(ctx, ref, c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = s.RepoPG.LockForEdit(ref.ID, ref.Status, func() error {
+		return s.RepoMongo.RemoveAttachment(ctx, ref.MongoAchievementID, old.ID)
+	})
+	if err != nil {
+		if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+		}
+		return transitionErrorResponse(c, err, "Failed to delete attachment")
+	}
+	s.collectFiles(ctx, ref.ID, attachmentFiles(old)...)
+
+	return c.JSON(fiber.Map{"message": "Attachment deleted"})
+}
+
+// ReplaceAttachment godoc
+// @Summary      Replace File Bukti
+// @Description  Mengganti file lampiran selama prestasi masih draft (validasi sama dengan upload). Lampiran baru mendapat ID baru di posisi yang sama; file lama dihapus dari storage
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        id             path      string  true  "Achievement Ref ID"
+// @Param        attachmentId   path      string  true  "Attachment ID"
+// @Param        file           formData  file    true  "File Bukti Pengganti"
+// @Success      200  {object} map[string]interface{} "Format: {data: Attachment, replaced_id}"
+// @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{} "Error: Status bukan Draft"
+// @Failure      413  {object} map[string]interface{}
+// @Failure      415  {object} map[string]interface{}
+// @Router       /achievements/{id}/attachments/{attachmentId} [put]
+func (s *AchievementService) ReplaceAttachment(c *fiber.Ctx) error {
+	ref, err := s.RepoPG.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+	if err := s.transition(c, ref, workflow.ActionEdit, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to replace attachment")
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	old, status, err := s.editableAttachment(ctx, ref, c.Params("attachmentId"))
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	attachment, status, err := s.storeUpload(ctx, c, ref)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	err = s.RepoPG.LockForEdit(ref.ID, ref.Status, func() error {
+		return s.RepoMongo.ReplaceAttachment(ctx, ref.MongoAchievementID, old.ID, *attachment)
+	})
+	if err != nil {
+		s.collectFiles(ctx, ref.ID, attachment.StorageKey)
+		if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+		}
+		return transitionErrorResponse(c, err, "Failed to replace attachment")
+	}
+	s.collectFiles(ctx, ref.ID, attachmentFiles(old)...)
+
 	return c.JSON(fiber.Map{
-		"message": "File uploaded and linked successfully",
-		"data":    attachment,
+		"message":     "Attachment replaced",
+		"data":        attachment,
+		"replaced_id": old.ID,
 	})
 }
 
+// Validasi file dari form "file" lalu simpan ke storage. Return attachment (belum tercatat di MongoDB)
+func (s *AchievementService) storeUpload(ctx context.Context, c *fiber.Ctx, ref *modelPG.AchievementReference) (*modelMongo.Attachment, int, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, 400, errors.New("File is required")
+	}
+	if file.Size > storage.MaxUploadSize {
+		return nil, 413, storage.ErrTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return nil, 400, errors.New("Failed to read file")
+	}
+	defer src.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, 400, errors.New("Failed to read file")
+	}
+	ext, contentType, err := storage.Inspect(file.Filename, head[:n])
+	if err != nil {
+		return nil, 415, err
+	}
+
+	// Key dibuat server (tidak memakai nama file dari client)
+	attachmentID := uuid.New().String()
+	key := fmt.Sprintf("achievements/%s/%s%s", ref.ID, attachmentID, ext)
+	hash := sha256.New()
+	body := io.TeeReader(io.MultiReader(bytes.NewReader(head[:n]), src), hash)
+
+	if err := s.Storage.Put(ctx, key, body, file.Size, contentType); err != nil {
+		log.Printf("Failed to store attachment %s: %v\n", key, err)
+		return nil, 500, errors.New("Failed to store file")
+	}
+
+	return &modelMongo.Attachment{
+		ID:             attachmentID,
+		FileName:       storage.SanitizeFileName(file.Filename),
+		FileURL:        fmt.Sprintf("/api/v1/achievements/%s/attachments/%s", ref.ID, attachmentID),
+		FileType:       contentType,
+		Size:           file.Size,
+		ChecksumSHA256: hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:     key,
+		UploadedAt:     time.Now(),
+		ScanStatus:     modelMongo.ScanPending, // Discan worker secara asinkron
+		Preview:        &modelMongo.AttachmentPreview{Status: modelMongo.PreviewPending},
+	}, 200, nil
+}
+
+// Lampiran yang akan dihapus / diganti (harus ada di dokumen prestasi ini)
+func (s *AchievementService) editableAttachment(ctx context.Context, ref *modelPG.AchievementReference, attachmentID string) (*modelMongo.Attachment, int, error) {
+	doc, err := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
+	if err != nil || doc == nil {
+		return nil, 404, errors.New("Achievement detail not found")
+	}
+	attachment := findAttachment(doc, attachmentID)
+	if attachment == nil {
+		return nil, 404, errors.New("Attachment not found")
+	}
+	return attachment, 200, nil
+}
+
+// Hapus file lewat outbox agar tetap terhapus walau storage sedang gagal (retry oleh worker)
+func (s *AchievementService) collectFiles(ctx context.Context, refID string, keys ...string) {
+	queued := false
+	for _, key := range keys {
+		if key == "" || strings.HasPrefix(key, QuarantinePrefix) {
+			continue // Lampiran lama sebelum storage abstraction / file karantina disimpan untuk audit
+		}
+		if err := s.Outbox.Enqueue(refID, modelPG.EventAttachmentFileDelete, modelPG.StoredFilePayload{StorageKey: key}); err != nil {
+			log.Printf("Failed to queue attachment GC for %s: %v\n", key, err)
+			if err := s.Storage.Delete(ctx, key); err != nil {
+				log.Printf("Failed to delete attachment %s: %v\n", key, err)
+			}
+			continue
+		}
+		queued = true
+	}
+	if !queued {
+		return
+	}
+	if _, err := processOutbox(ctx, s.Outbox, s.RepoMongo, s.Storage, refID, OutboxBatchSize); err != nil {
+		log.Println("Failed to dispatch achievement outbox:", err)
+	}
+}
+
+// File lampiran beserta thumbnail / preview-nya
+func attachmentFiles(a *modelMongo.Attachment) []string {
+	keys := []string{a.StorageKey}
+	if a.Preview != nil {
+		keys = append(keys, a.Preview.ThumbnailKey, a.Preview.PreviewKey)
+	}
+	return keys
+}
+
+// DownloadAttachment godoc
+// @Summary      Download File Bukti
+// @Description  Mengunduh lampiran prestasi. Akses sama dengan detail prestasi (pemilik, dosen wali, atau Admin)
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      octet-stream
+// @Param        id             path  string  true  "Achievement Ref ID"
+// @Param        attachmentId   path  string  true  "Attachment ID"
+// @Success      200  {file}   binary
+// @Failure      403  {object} map[string]interface{} "Error: Lampiran dikarantina"
+// @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{} "Error: Lampiran masih discan / gagal discan"
+// @Router       /achievements/{id}/attachments/{attachmentId} [get]
+func (s *AchievementService) DownloadAttachment(c *fiber.Ctx) error {
+	ref, err := s.RepoPG.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	ctx := context.Background()
+	doc, err := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
+	if err != nil || doc == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement detail not found"})
+	}
+	attachment := findAttachment(doc, c.Params("attachmentId"))
+	if attachment == nil || attachment.StorageKey == "" {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+	switch attachment.ScanStatus {
+	case modelMongo.ScanPending:
+		return c.Status(409).JSON(fiber.Map{"error": "Attachment is still being scanned", "scanStatus": attachment.ScanStatus})
+	case modelMongo.ScanInfected:
+		return c.Status(403).JSON(fiber.Map{"error": "Attachment is quarantined", "scanStatus": attachment.ScanStatus})
+	case modelMongo.ScanFailed:
+		return c.Status(409).JSON(fiber.Map{"error": "Attachment could not be scanned, please upload it again", "scanStatus": attachment.ScanStatus})
+	}
+
+	file, err := s.Storage.Open(ctx, attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment file not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read attachment"})
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.FileType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, storage.SanitizeFileName(attachment.FileName)))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	if attachment.ChecksumSHA256 != "" {
+		c.Set(fiber.HeaderETag, `"`+attachment.ChecksumSHA256+`"`)
+	}
+	size := -1
+	if attachment.Size > 0 {
+		size = int(attachment.Size)
+	}
+	return c.SendStream(file, size)
+}
+
+// DownloadAttachmentThumbnail godoc
+// @Summary      Thumbnail File Bukti
+// @Description  Thumbnail JPEG (maks 320px) dari lampiran gambar / halaman pertama PDF. Tersedia setelah preview.status = ready
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      jpeg
+// @Param        id             path  string  true  "Achievement Ref ID"
+// @Param        attachmentId   path  string  true  "Attachment ID"
+// @Success      200  {file}   binary
+// @Failure      404  {object} map[string]interface{}
+// @Router       /achievements/{id}/attachments/{attachmentId}/thumbnail [get]
+func (s *AchievementService) DownloadAttachmentThumbnail(c *fiber.Ctx) error {
+	return s.sendRendition(c, true)
+}
+
+// DownloadAttachmentPreview godoc
+// @Summary      Preview File Bukti
+// @Description  Preview JPEG (maks 1280px) dari lampiran gambar / halaman pertama PDF, untuk review tanpa mengunduh file asli
+// @Tags         Achievements
+// @Security     BearerAuth
+// @Produce      jpeg
+// @Param        id             path  string  true  "Achievement Ref ID"
+// @Param        attachmentId   path  string  true  "Attachment ID"
+// @Success      200  {file}   binary
+// @Failure      404  {object} map[string]interface{}
+// @Router       /achievements/{id}/attachments/{attachmentId}/preview [get]
+func (s *AchievementService) DownloadAttachmentPreview(c *fiber.Ctx) error {
+	return s.sendRendition(c, false)
+}
+
+func (s *AchievementService) sendRendition(c *fiber.Ctx, thumbnail bool) error {
+	ref, err := s.RepoPG.GetReferenceByID(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	ctx := context.Background()
+	doc, err := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
+	if err != nil || doc == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement detail not found"})
+	}
+	attachment := findAttachment(doc, c.Params("attachmentId"))
+	if attachment == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Attachment not found"})
+	}
+	// Preview hanya dibuat untuk lampiran yang lolos scan
+	if attachment.Preview == nil || attachment.Preview.Status != modelMongo.PreviewReady {
+		status := ""
+		if attachment.Preview != nil {
+			status = attachment.Preview.Status
+		}
+		return c.Status(404).JSON(fiber.Map{"error": "Preview not available", "previewStatus": status})
+	}
+
+	key := attachment.Preview.PreviewKey
+	if thumbnail {
+		key = attachment.Preview.ThumbnailKey
+	}
+	file, err := s.Storage.Open(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Preview file not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to read preview"})
+	}
+
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=86400") // Rendisi tidak pernah berubah (lampiran baru = ID baru)
+	return c.SendStream(file)
+}
+
+// Lampiran yang masih pending / terinfeksi (lampiran lama tanpa status dianggap lolos)
+// Lampiran belum lolos scan antivirus saat pengajuan
+type unscannedAttachmentsError struct {
+	Attachments []fiber.Map
+}
+
+func (e *unscannedAttachmentsError) Error() string {
+	return "attachments have not passed the virus scan"
+}
+
+var errAchievementDetailNotFound = errors.New("achievement detail not found")
+
+// Lampiran yang belum discan / terinfeksi tidak boleh sampai ke Dosen Wali. Dicek di dalam kunci
+// transisi, sehingga lampiran yang ditambahkan bersamaan (LockForEdit) tidak terlewat
+func (s *AchievementService) requireScannedAttachments(ref *modelPG.AchievementReference) func() error {
+	return func() error {
+		doc, err := s.RepoMongo.FindAchievementByID(context.Background(), ref.MongoAchievementID)
+		if err != nil || doc == nil {
+			return errAchievementDetailNotFound
+		}
+		if blocked := unscannedAttachments(doc); len(blocked) > 0 {
+			return &unscannedAttachmentsError{Attachments: blocked}
+		}
+		return nil
+	}
+}
+
+func unscannedAttachments(doc *modelMongo.Achievement) []fiber.Map {
+	blocked := []fiber.Map{}
+	for _, a := range doc.Attachments {
+		if a.ScanStatus == modelMongo.ScanPending || a.ScanStatus == modelMongo.ScanInfected || a.ScanStatus == modelMongo.ScanFailed {
+			blocked = append(blocked, fiber.Map{"id": a.ID, "fileName": a.FileName, "scanStatus": a.ScanStatus})
+		}
+	}
+	return blocked
+}
+
+func findAttachment(doc *modelMongo.Achievement, attachmentID string) *modelMongo.Attachment {
+	if attachmentID == "" {
+		return nil
+	}
+	for i := range doc.Attachments {
+		if doc.Attachments[i].ID == attachmentID {
+			return &doc.Attachments[i]
+		}
+	}
+	return nil
+}
+
 // GetAdviseesAchievements godoc
 // @Summary      Get Advisee Achievements (Dosen Wali)
 // @Description  Dosen Wali melihat daftar prestasi yang diajukan oleh mahasiswa bimbingannya untuk diverifikasi.
@@ -387,49 +1055,67 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object} map[string]interface{} "Response format: { data: [ {ref_id, status, detail...} ] }"
+// @Param        status         query  string  false  "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)"
+// @Param        type           query  string  false  "Filter achievement type"
+// @Param        tag            query  string  false  "Filter tag"
+// @Param        from           query  string  false  "Dibuat sejak (YYYY-MM-DD / RFC3339)"
+// @Param        to             query  string  false  "Dibuat sampai (YYYY-MM-DD / RFC3339)"
+// @Param        min_points     query  int     false  "Poin minimum"
+// @Param        max_points     query  int     false  "Poin maksimum"
+// @Param        student_id     query  string  false  "Filter student UUID"
+// @Param        program_study  query  string  false  "Filter program studi"
+// @Param        advisor_id     query  string  false  "Filter dosen wali (lecturer UUID)"
+// @Param        sort           query  string  false  "created_at | updated_at | points | title" default(created_at)
+// @Param        order          query  string  false  "asc | desc" default(desc)
+// @Param        limit          query  int     false  "Jumlah item per halaman (maks 100)" default(20)
+// @Param        cursor         query  string  false  "Cursor dari meta.next_cursor halaman sebelumnya"
+// @Success      200  {object} map[string]interface{} "Response format: { data: [ {ref_id, status, detail...} ], meta: {limit, sort, next_cursor} }"
+// @Failure      400  {object} map[string]interface{}
 // @Failure      500  {object} map[string]interface{}
 // @Router       /achievements/advisees [get]
 func (s *AchievementService) GetAdviseesAchievements(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(string)
-	refs, err := s.RepoPG.GetAchievementsByAdvisorID(userID)
+
+	q, err := parseListingQuery(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievements"})
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
+	// Scope: mahasiswa bimbingan sendiri, draft belum terlihat oleh dosen wali
+	q.Filter.AdvisorUserID = userID
+	q.Filter.HiddenStatuses = []string{workflow.StatusDraft, workflow.StatusDeleted}
 
-	var results []map[string]interface{}
-	ctx := context.Background()
-
-	for _, ref := range refs {
-		detail, _ := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
-		item := map[string]interface{}{
+	results, next, err := listAchievements(context.Background(), s.RepoPG, s.RepoMongo, q, func(ref modelPG.AchievementReference) map[string]interface{} {
+		return map[string]interface{}{
 			"ref_id":     ref.ID,
+			"student_id": ref.StudentID,
 			"status":     ref.Status,
 			"created_at": ref.CreatedAt,
-			"detail":     detail,
 		}
-		results = append(results, item)
-	}
-
-	return c.JSON(fiber.Map{"data": results})
+	})
+	return listingResponse(c, results, q, next, err)
 }
 
 // VerifyAchievement godoc
 // @Summary      Verify Achievement (Dosen)
-// @Description  Dosen Wali menyetujui prestasi mahasiswa bimbingannya (Status berubah menjadi 'verified')
+// @Description  Dosen Wali menyetujui prestasi mahasiswa bimbingannya (Status berubah menjadi 'verified'). Poin dihitung dengan aturan aktif lalu dibekukan pada reference
 // @Tags         Achievements (Dosen)
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Achievement Ref ID"
 // @Produce      json
 // @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{} "Error: Only submitted achievements can be verified"
 // @Failure      500  {object} map[string]interface{}
 // @Router       /achievements/{id}/verify [post]
 func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 	id := c.Params("id")
-	userID := c.Locals("user_id").(string)
+	ref, err := s.RepoPG.GetReferenceByID(id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
 
-	if err := s.RepoPG.UpdateVerification(id, "verified", userID, nil); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify achievement"})
+	if err := s.transition(c, ref, workflow.ActionVerify, nil); err != nil {
+		return transitionErrorResponse(c, err, "Failed to verify achievement")
 	}
 
 	return c.JSON(fiber.Map{"message": "Achievement verified successfully"})
@@ -446,11 +1132,16 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 // @Param        body  body      map[string]string  true  "Payload: { 'note': 'Alasan penolakan...' }"
 // @Success      200   {object}  map[string]interface{}
 // @Failure      400   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{}
+// @Failure      409   {object}  map[string]interface{} "Error: Only submitted achievements can be rejected"
 // @Failure      500   {object}  map[string]interface{}
 // @Router       /achievements/{id}/reject [post]
 func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 	id := c.Params("id")
-	userID := c.Locals("user_id").(string)
+	ref, err := s.RepoPG.GetReferenceByID(id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
 
 	type RejectReq struct {
 		Note string `json:"note"`
@@ -460,19 +1151,330 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Rejection note is required"})
 	}
 
-	if err := s.RepoPG.UpdateVerification(id, "rejected", userID, &req.Note); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to reject achievement"})
+	if err := s.transition(c, ref, workflow.ActionReject, &req.Note); err != nil {
+		return transitionErrorResponse(c, err, "Failed to reject achievement")
 	}
 
 	return c.JSON(fiber.Map{"message": "Achievement rejected"})
 }
 
-// Helper
-func getString(s *string) string {
-	if s == nil {
-		return ""
+// RequestRevision godoc
+// @Summary      Request Revision (Dosen)
+// @Description  Dosen Wali mengembalikan prestasi ke mahasiswa untuk diperbaiki (berbeda dengan penolakan) dengan komentar per field. Field: title, description, achievementType, tags, details, attachments, details.<key>, attachments.<id>. Mahasiswa bisa mengedit lalu mengajukan ulang sebagai ronde review berikutnya
+// @Tags         Achievements (Dosen)
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                    true  "Achievement Ref ID (UUID)"
+// @Param        body  body      postgres.RevisionRequest  true  "Payload: { note, comments: [{ field, comment }] }"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{} "Error: Komentar kosong / field tidak dikenal"
+// @Failure      404   {object}  map[string]interface{}
+// @Failure      409   {object}  map[string]interface{} "Error: Only submitted achievements can be returned for revision / status changed by another request"
+// @Failure      500   {object}  map[string]interface{}
+// @Router       /achievements/{id}/request-revision [post]
+func (s *AchievementService) RequestRevision(c *fiber.Ctx) error {
+	id := c.Params("id")
+	ref, err := s.RepoPG.GetReferenceByID(id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	var req modelPG.RevisionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if len(req.Comments) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "At least one field comment is required"})
+	}
+	if len(req.Comments) > modelPG.MaxRevisionComments {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("At most %d comments per request", modelPG.MaxRevisionComments)})
+	}
+	for i := range req.Comments {
+		cm := &req.Comments[i]
+		cm.Field, cm.Comment = strings.TrimSpace(cm.Field), strings.TrimSpace(cm.Comment)
+		if !validRevisionField(cm.Field) {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Unknown field '%s'", cm.Field)})
+		}
+		if cm.Comment == "" {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Comment for field '%s' is required", cm.Field)})
+		}
+	}
+
+	permissions, _ := c.Locals("permissions").([]string)
+	if _, err := workflow.Transition(ref.Status, workflow.ActionRequestRevision, permissions); err != nil {
+		return transitionErrorResponse(c, err, "Failed to request revision")
+	}
+
+	var note *string
+	if n := strings.TrimSpace(req.Note); n != "" {
+		note = &n
+	}
+	userID := c.Locals("user_id").(string)
+	if err := s.RepoPG.RequestRevision(ref.ID, ref.Status, userID, note, req.Comments); err != nil {
+		return transitionErrorResponse(c, err, "Failed to request revision")
+	}
+	s.Notifier.Publish(modelPG.NotificationEvent{
+		Type: modelPG.NotifAchievementNeedsRevision, ActorID: userID, AchievementRefID: ref.ID, Note: strings.TrimSpace(req.Note),
+	})
+
+	return c.JSON(fiber.Map{"message": "Achievement returned for revision", "round": ref.ReviewRound})
+}
+
+// Field konten yang bisa dikomentari (details.<key> / attachments.<id> untuk bagian tertentu)
+var revisionFields = map[string]bool{
+	"title": true, "description": true, "achievementType": true, "tags": true, "details": true, "attachments": true,
+}
+
+func validRevisionField(field string) bool {
+	if len(field) > 100 {
+		return false
+	}
+	if revisionFields[field] {
+		return true
 	}
-	return *s
+	for _, prefix := range []string{"details.", "attachments."} {
+		if strings.HasPrefix(field, prefix) && len(field) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sisipkan komentar revisi ke entri history needs_revision sesuai rondenya
+func (s *AchievementService) attachRevisionComments(refID string, history []modelPG.AchievementStatusHistory) error {
+	needed := false
+	for _, h := range history {
+		if h.ToStatus == workflow.StatusNeedsRevision {
+			needed = true
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	comments, err := s.RepoPG.GetRevisionComments(refID)
+	if err != nil {
+		return err
+	}
+	byRound := map[int][]modelPG.RevisionComment{}
+	for _, cm := range comments {
+		byRound[cm.Round] = append(byRound[cm.Round], cm)
+	}
+	for i := range history {
+		if history[i].ToStatus == workflow.StatusNeedsRevision {
+			history[i].Comments = byRound[history[i].Round]
+		}
+	}
+	return nil
+}
+
+// BulkReviewAchievements godoc
+// @Summary      Bulk Verify / Reject (Dosen)
+// @Description  Verifikasi / penolakan banyak prestasi sekaligus (maks 100). Tiap item diproses dalam transaksi sendiri dengan aturan yang sama seperti endpoint verify/reject (scope dosen wali & state machine); hasil dilaporkan per item
+// @Tags         Achievements (Dosen)
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      postgres.BulkReviewRequest  true  "Payload: { items: [{ id, action: verify|reject, note }] }"
+// @Success      200   {object}  map[string]interface{} "Format: { data: [BulkReviewResult], summary: {total, succeeded, failed} }"
+// @Failure      400   {object}  map[string]interface{}
+// @Router       /achievements/bulk-review [post]
+func (s *AchievementService) BulkReviewAchievements(c *fiber.Ctx) error {
+	var req modelPG.BulkReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if len(req.Items) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "At least one item is required"})
+	}
+	if len(req.Items) > modelPG.MaxBulkReviewItems {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("At most %d items per request", modelPG.MaxBulkReviewItems)})
+	}
+
+	results := make([]modelPG.BulkReviewResult, 0, len(req.Items))
+	seen := map[string]bool{}
+	succeeded := 0
+	for _, item := range req.Items {
+		result := modelPG.BulkReviewResult{ID: item.ID, Action: item.Action}
+		if seen[item.ID] {
+			result.Code, result.Error = 400, "Duplicate achievement in request"
+		} else {
+			seen[item.ID] = true
+			result.Status, result.Code, result.Error = s.reviewItem(c, item)
+		}
+		result.Success = result.Error == ""
+		if result.Success {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	return c.JSON(fiber.Map{
+		"data":    results,
+		"summary": fiber.Map{"total": len(results), "succeeded": succeeded, "failed": len(results) - succeeded},
+	})
+}
+
+// Satu item review massal: validasi, cek scope dosen wali, lalu transisi (transaksi sendiri)
+func (s *AchievementService) reviewItem(c *fiber.Ctx, item modelPG.BulkReviewItem) (string, int, string) {
+	var note *string
+	var to string
+	switch item.Action {
+	case workflow.ActionVerify:
+		to = workflow.StatusVerified
+	case workflow.ActionReject:
+		if strings.TrimSpace(item.Note) == "" {
+			return "", 400, "Rejection note is required"
+		}
+		note, to = &item.Note, workflow.StatusRejected
+	default:
+		return "", 400, "Action must be verify or reject"
+	}
+
+	ref, err := s.RepoPG.GetReferenceByID(item.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", 404, "Achievement not found"
+	}
+	if err != nil {
+		return "", 500, "Failed to load achievement"
+	}
+
+	// Scope sama dengan middleware AchievementAccess(ScopeAdvisor)
+	if !middleware.HasFullAccess(c) {
+		ok, err := s.RepoPG.IsStudentAdvisedBy(ref.StudentID, c.Locals("user_id").(string))
+		if err != nil {
+			return ref.Status, 500, "Failed to check access"
+		}
+		if !ok {
+			return ref.Status, 403, "Forbidden: you do not have access to this achievement"
+		}
+	}
+
+	if err := s.transition(c, ref, item.Action, note); err != nil {
+		var sc *repoPG.StatusConflictError
+		if errors.As(err, &sc) {
+			return sc.Actual, 409, statusConflictMessage
+		}
+		var te *workflow.TransitionError
+		if errors.As(err, &te) {
+			if errors.Is(err, workflow.ErrRoleNotAllowed) {
+				return ref.Status, 403, te.Error()
+			}
+			return ref.Status, 409, te.Error()
+		}
+		log.Printf("Bulk review of %s failed: %v\n", ref.ID, err)
+		return ref.Status, 500, "Failed to update status"
+	}
+	return to, 200, ""
+}
+
+// Jalankan aksi lewat state machine, lalu simpan status baru (tercatat di history)
+func (s *AchievementService) transition(c *fiber.Ctx, ref *modelPG.AchievementReference, action string, note *string) error {
+	userID := c.Locals("user_id").(string)
+	permissions, _ := c.Locals("permissions").([]string)
+
+	to, err := workflow.Transition(ref.Status, action, permissions)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case workflow.ActionEdit:
+		return nil // Status tidak berubah; dicek ulang & dikunci saat menyimpan (RepoPG.LockForEdit)
+	case workflow.ActionVerify:
+		// Poin dibekukan saat verifikasi; perubahan aturan berikutnya tidak berpengaruh
+		snapshot, doc, err := s.pointsSnapshot(ref)
+		if err != nil {
+			return err
+		}
+		if err := s.RepoPG.UpdateVerification(ref.ID, ref.Status, to, userID, note, snapshot); err != nil {
+			return err
+		}
+		if doc.Points != snapshot.Points || doc.PointsRuleVersion != snapshot.RuleVersion {
+			if err := s.RepoMongo.SetPoints(context.Background(), ref.MongoAchievementID, snapshot.Points, snapshot.RuleVersion); err != nil {
+				log.Printf("Failed to sync verified points of %s: %v\n", ref.ID, err)
+			}
+		}
+	case workflow.ActionReject:
+		if err := s.RepoPG.UpdateVerification(ref.ID, ref.Status, to, userID, note, nil); err != nil {
+			return err
+		}
+	default:
+		var check func() error
+		if to == workflow.StatusSubmitted {
+			check = s.requireScannedAttachments(ref)
+		}
+		if err := s.RepoPG.UpdateStatus(ref.ID, ref.Status, to, userID, check); err != nil {
+			return err
+		}
+	}
+
+	if eventType, ok := transitionEvents[to]; ok {
+		ev := modelPG.NotificationEvent{Type: eventType, ActorID: userID, AchievementRefID: ref.ID}
+		if note != nil {
+			ev.Note = *note
+		}
+		s.Notifier.Publish(ev)
+	}
+	return nil
+}
+
+const statusConflictMessage = "Achievement status was changed by another request"
+
+// Status tujuan yang memicu notifikasi
+var transitionEvents = map[string]string{
+	workflow.StatusSubmitted: modelPG.NotifAchievementSubmitted,
+	workflow.StatusVerified:  modelPG.NotifAchievementVerified,
+	workflow.StatusRejected:  modelPG.NotifAchievementRejected,
+}
+
+// Hitung poin konten dengan aturan aktif
+func (s *AchievementService) applyPoints(req *modelMongo.Achievement, defaultPoints int) error {
+	rules, version, err := activeRuleSet(s.Rules)
+	if err != nil {
+		return err
+	}
+	req.Points = rules.Compute(req.AchievementType, req.Details, defaultPoints).Points
+	req.PointsRuleVersion = version
+	return nil
+}
+
+// Poin final saat verifikasi, dihitung ulang dari dokumen terbaru
+func (s *AchievementService) pointsSnapshot(ref *modelPG.AchievementReference) (*modelPG.PointsSnapshot, *modelMongo.Achievement, error) {
+	doc, err := s.RepoMongo.FindAchievementByID(context.Background(), ref.MongoAchievementID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc == nil {
+		return nil, nil, fmt.Errorf("achievement detail %s not found", ref.MongoAchievementID)
+	}
+	defaultPoints, err := typeDefaultPoints(s.Types, doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, version, err := activeRuleSet(s.Rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	result := rules.Compute(doc.AchievementType, doc.Details, defaultPoints)
+	return &modelPG.PointsSnapshot{Points: result.Points, RuleVersion: version}, doc, nil
+}
+
+// Petakan error transisi: status tidak valid / sudah diubah request lain -> 409, permission kurang -> 403
+func transitionErrorResponse(c *fiber.Ctx, err error, fallback string) error {
+	var sc *repoPG.StatusConflictError
+	if errors.As(err, &sc) {
+		return c.Status(409).JSON(fiber.Map{"error": statusConflictMessage, "status": sc.Actual})
+	}
+	var te *workflow.TransitionError
+	if errors.As(err, &te) {
+		if errors.Is(err, workflow.ErrRoleNotAllowed) {
+			return c.Status(403).JSON(fiber.Map{"error": te.Error()})
+		}
+		return c.Status(409).JSON(fiber.Map{"error": te.Error(), "status": te.From, "action": te.Action})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": fallback})
 }
 
 // GetStudentAchievements godoc
@@ -481,34 +1483,98 @@ func getString(s *string) string {
 // @Tags         Academic
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Student UUID"
+// @Param        status         query  string  false  "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)"
+// @Param        type           query  string  false  "Filter achievement type"
+// @Param        tag            query  string  false  "Filter tag"
+// @Param        from           query  string  false  "Dibuat sejak (YYYY-MM-DD / RFC3339)"
+// @Param        to             query  string  false  "Dibuat sampai (YYYY-MM-DD / RFC3339)"
+// @Param        min_points     query  int     false  "Poin minimum"
+// @Param        max_points     query  int     false  "Poin maksimum"
+// @Param        sort           query  string  false  "created_at | updated_at | points | title" default(created_at)
+// @Param        order          query  string  false  "asc | desc" default(desc)
+// @Param        limit          query  int     false  "Jumlah item per halaman (maks 100)" default(20)
+// @Param        cursor         query  string  false  "Cursor dari meta.next_cursor halaman sebelumnya"
 // @Produce      json
-// @Success      200  {object} map[string]interface{} "Format: { data: [{ id, status, created_at, detail: {...} }] }"
+// @Success      200  {object} map[string]interface{} "Format: { data: [{ id, status, created_at, detail: {...} }], meta: {limit, sort, next_cursor} }"
+// @Failure      400  {object} map[string]interface{}
 // @Failure      500  {object} map[string]interface{}
 // @Router       /students/{id}/achievements [get]
 func (s *AchievementService) GetStudentAchievements(c *fiber.Ctx) error {
-	studentID := c.Params("id")
+	q, err := parseListingQuery(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	q.Filter.StudentID = c.Params("id")
+	q.Filter.HiddenStatuses = []string{workflow.StatusDeleted}
 
-	refs, err := s.RepoPG.GetAchievementsByStudentID(studentID)
+	results, next, err := listAchievements(context.Background(), s.RepoPG, s.RepoMongo, q, referenceItem)
+	return listingResponse(c, results, q, next, err)
+}
+
+// Validasi konten prestasi terhadap katalog jenis (field wajib + JSON-Schema details).
+// Field error dikembalikan untuk response 422, error kedua untuk kegagalan sistem
+func (s *AchievementService) validateContent(req *modelMongo.Achievement, allowInactive bool) (*modelPG.AchievementType, []schema.FieldError, error) {
+	var errs []schema.FieldError
+	if strings.TrimSpace(req.Title) == "" {
+		errs = append(errs, schema.FieldError{Field: "title", Message: "is required"})
+	}
+	if req.AchievementType == "" {
+		return nil, append(errs, schema.FieldError{Field: "achievementType", Message: "is required"}), nil
+	}
+
+	t, err := s.Types.GetTypeByCode(req.AchievementType)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !t.IsActive && !allowInactive) {
+		return nil, append(errs, schema.FieldError{Field: "achievementType", Message: "unknown achievement type"}), nil
+	}
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievements"})
+		return nil, nil, err
 	}
 
-	var results []map[string]interface{}
-	ctx := context.Background()
+	detailsSchema, err := schema.Parse(t.DetailsSchema)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.Details == nil {
+		req.Details = map[string]interface{}{}
+	}
+	errs = append(errs, detailsSchema.Validate(req.Details, "details")...)
+	return t, errs, nil
+}
+
+func validationFailed(c *fiber.Ctx, errs []schema.FieldError) error {
+	return c.Status(422).JSON(fiber.Map{"error": "Validation failed", "fields": errs})
+}
+
+// Item listing standar (id, status, created_at); detail ditambahkan saat digabung
+func referenceItem(ref modelPG.AchievementReference) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         ref.ID,
+		"status":     ref.Status,
+		"created_at": ref.CreatedAt,
+	}
+}
+
+// Gabungkan reference PostgreSQL dengan detail MongoDB lewat satu query $in, urutan mengikuti refs.
+// Detail yang tidak ditemukan dilaporkan per item lewat "error", bukan "detail": null
+func hydrateDetails(ctx context.Context, mongo repoMongo.IAchievementRepoMongo, refs []modelPG.AchievementReference, base func(ref modelPG.AchievementReference) map[string]interface{}) ([]map[string]interface{}, error) {
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = ref.MongoAchievementID
+	}
+	details, err := mongo.FindAchievementsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]map[string]interface{}, 0, len(refs))
 	for _, ref := range refs {
-		detail, _ := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
-		item := map[string]interface{}{
-			"id":         ref.ID,
-			"status":     ref.Status,
-			"created_at": ref.CreatedAt,
-			"detail":     detail,
+		item := base(ref)
+		if detail, ok := details[ref.MongoAchievementID]; ok {
+			item["detail"] = detail
+		} else {
+			item["error"] = "Achievement detail not found"
 		}
 		results = append(results, item)
 	}
-	
-	if results == nil {
-		results = []map[string]interface{}{}
-	}
-
-	return c.JSON(fiber.Map{"data": results})
-}
\ No newline at end of file
+	return results, nil
+}
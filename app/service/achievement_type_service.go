@@ -0,0 +1,164 @@
+package service
+
+import (
+	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/schema"
+	"be_uas/middleware"
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var typeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+type AchievementTypeService struct {
+	Repo repoPG.IAchievementTypeRepoPG
+}
+
+func NewAchievementTypeService(repo repoPG.IAchievementTypeRepoPG) *AchievementTypeService {
+	return &AchievementTypeService{Repo: repo}
+}
+
+// ListAchievementTypes godoc
+// @Summary      Get Achievement Types
+// @Description  Katalog jenis prestasi beserta JSON-Schema details (dipakai untuk membangun form). Admin dapat melihat jenis nonaktif dengan include_inactive=true
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Produce      json
+// @Param        include_inactive  query  bool  false  "Sertakan jenis nonaktif (Admin)"
+// @Success      200  {object} map[string]interface{} "Format: {data: [AchievementType]}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /achievement-types [get]
+func (s *AchievementTypeService) ListAchievementTypes(c *fiber.Ctx) error {
+	includeInactive := c.QueryBool("include_inactive") && middleware.HasFullAccess(c)
+	types, err := s.Repo.GetAllTypes(includeInactive)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievement types"})
+	}
+	if types == nil {
+		types = []postgres.AchievementType{}
+	}
+	return c.JSON(fiber.Map{"data": types})
+}
+
+// GetAchievementType godoc
+// @Summary      Get Achievement Type
+// @Description  Detail satu jenis prestasi beserta JSON-Schema details
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Produce      json
+// @Param        code  path  string  true  "Type Code"
+// @Success      200  {object} postgres.AchievementType
+// @Failure      404  {object} map[string]interface{}
+// @Router       /achievement-types/{code} [get]
+func (s *AchievementTypeService) GetAchievementType(c *fiber.Ctx) error {
+	t, err := s.Repo.GetTypeByCode(c.Params("code"))
+	if err != nil {
+		return notFoundOr500(c, err, "Achievement type not found", "Failed to fetch achievement type")
+	}
+	return c.JSON(fiber.Map{"data": t})
+}
+
+// CreateAchievementType godoc
+// @Summary      Create Achievement Type
+// @Description  Menambah jenis prestasi. details_schema adalah JSON-Schema bertipe object (type, properties, required, enum, format, dll)
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body      postgres.AchievementType true "Payload: { code, name, description, details_schema, default_points, is_active }"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{} "Error: Code sudah dipakai"
+// @Failure      422  {object}  map[string]interface{} "Error: Schema tidak valid"
+// @Router       /achievement-types [post]
+func (s *AchievementTypeService) CreateAchievementType(c *fiber.Ctx) error {
+	req := postgres.AchievementType{IsActive: true}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Input"})
+	}
+	if !typeCodePattern.MatchString(req.Code) {
+		return c.Status(400).JSON(fiber.Map{"error": "code must be lowercase letters, digits or underscores (2-50 characters)"})
+	}
+	if status, err := checkTypePayload(&req); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if _, err := s.Repo.GetTypeByCode(req.Code); err == nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Achievement type code already exists"})
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create achievement type"})
+	}
+
+	if err := s.Repo.CreateType(req); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create achievement type"})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Achievement type created", "code": req.Code})
+}
+
+// UpdateAchievementType godoc
+// @Summary      Update Achievement Type
+// @Description  Mengubah nama, deskripsi, schema details, poin default, atau status aktif jenis prestasi. Schema baru berlaku untuk create/update berikutnya
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        code  path      string                   true "Type Code"
+// @Param        body  body      postgres.AchievementType true "Payload: { name, description, details_schema, default_points, is_active }"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      422  {object}  map[string]interface{} "Error: Schema tidak valid"
+// @Router       /achievement-types/{code} [put]
+func (s *AchievementTypeService) UpdateAchievementType(c *fiber.Ctx) error {
+	req := postgres.AchievementType{IsActive: true}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Input"})
+	}
+	req.Code = c.Params("code")
+	if status, err := checkTypePayload(&req); err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := s.Repo.UpdateType(req); err != nil {
+		return notFoundOr500(c, err, "Achievement type not found", "Failed to update achievement type")
+	}
+	return c.JSON(fiber.Map{"message": "Achievement type updated"})
+}
+
+// DeleteAchievementType godoc
+// @Summary      Deactivate Achievement Type
+// @Description  Menonaktifkan jenis prestasi (tidak bisa dipilih untuk prestasi baru, prestasi lama tetap utuh)
+// @Tags         Achievement Types
+// @Security     BearerAuth
+// @Param        code  path  string  true  "Type Code"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Router       /achievement-types/{code} [delete]
+func (s *AchievementTypeService) DeleteAchievementType(c *fiber.Ctx) error {
+	if err := s.Repo.DeactivateType(c.Params("code")); err != nil {
+		return notFoundOr500(c, err, "Achievement type not found", "Failed to deactivate achievement type")
+	}
+	return c.JSON(fiber.Map{"message": "Achievement type deactivated"})
+}
+
+// Validasi payload jenis prestasi, return HTTP status + error jika tidak valid
+func checkTypePayload(req *postgres.AchievementType) (int, error) {
+	if req.Name == "" {
+		return 400, errors.New("name is required")
+	}
+	if req.DefaultPoints < 0 {
+		return 400, errors.New("default_points must not be negative")
+	}
+	if len(req.DetailsSchema) == 0 {
+		req.DetailsSchema = []byte(`{"type": "object"}`)
+	}
+	if _, err := schema.Parse(req.DetailsSchema); err != nil {
+		return 422, err
+	}
+	return 0, nil
+}
@@ -6,7 +6,7 @@ import (
 	repoPG "be_uas/app/repository/postgres"
 	"be_uas/app/model/postgres"
 	"be_uas/utils"
-	"strconv"
+	"log"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,13 +16,19 @@ type AdminService struct {
 	RepoPG    repoPG.IUserRepo
 	RepoAchPG repoPG.IAchievementRepoPG
 	RepoMongo repoMongo.IAchievementRepoMongo
+	TokenRepo repoPG.ITokenRepoPG
+	AuditRepo repoPG.IAuditRepoPG
+	MFARepo   repoPG.IMFARepoPG
 }
 
-func NewAdminService(userRepo repoPG.IUserRepo, achRepo repoPG.IAchievementRepoPG, mongoRepo repoMongo.IAchievementRepoMongo) *AdminService {
+func NewAdminService(userRepo repoPG.IUserRepo, achRepo repoPG.IAchievementRepoPG, mongoRepo repoMongo.IAchievementRepoMongo, tokenRepo repoPG.ITokenRepoPG, auditRepo repoPG.IAuditRepoPG, mfaRepo repoPG.IMFARepoPG) *AdminService {
 	return &AdminService{
 		RepoPG:    userRepo,
 		RepoAchPG: achRepo,
 		RepoMongo: mongoRepo,
+		TokenRepo: tokenRepo,
+		AuditRepo: auditRepo,
+		MFARepo:   mfaRepo,
 	}
 }
 
@@ -118,7 +124,7 @@ func (s *AdminService) GetUserDetail(c *fiber.Ctx) error {
 
 // UpdateUser godoc
 // @Summary      Update User Info
-// @Description  Update nama lengkap atau status aktif user
+// @Description  Update nama lengkap atau status aktif user. Menonaktifkan user akan mencabut semua sesinya.
 // @Tags         Users (Admin)
 // @Security     BearerAuth
 // @Param        id   path      string  true  "User ID"
@@ -146,6 +152,13 @@ func (s *AdminService) UpdateUser(c *fiber.Ctx) error {
     if err := s.RepoPG.UpdateUser(user); err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "Failed to update user"})
     }
+
+    // User dinonaktifkan: cabut semua token yang masih beredar
+    if !req.IsActive {
+        if err := s.TokenRepo.RevokeAllUserSessions(id); err != nil {
+            return c.Status(500).JSON(fiber.Map{"error": "User updated but failed to revoke sessions"})
+        }
+    }
     return c.JSON(fiber.Map{"message": "User updated"})
 }
 
@@ -153,7 +166,7 @@ func (s *AdminService) UpdateUser(c *fiber.Ctx) error {
 
 // UpdateRole godoc
 // @Summary      Update User Role
-// @Description  Mengubah role user
+// @Description  Mengubah role user (semua sesi user dicabut agar token memuat role baru)
 // @Tags         Users (Admin)
 // @Security     BearerAuth
 // @Param        id   path      string  true  "User ID"
@@ -177,14 +190,138 @@ func (s *AdminService) UpdateRole(c *fiber.Ctx) error {
     if err := s.RepoPG.UpdateUserRole(id, roleID); err != nil {
         return c.Status(500).JSON(fiber.Map{"error": "Failed to update role"})
     }
+    if err := s.TokenRepo.RevokeAllUserSessions(id); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Role updated but failed to revoke sessions"})
+    }
     return c.JSON(fiber.Map{"message": "Role updated successfully"})
 }
 
+// UnlockUser godoc
+// @Summary      Unlock User
+// @Description  Membuka kunci akun yang terkunci karena terlalu banyak gagal login dan mereset counter percobaan
+// @Tags         Users (Admin)
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Router       /users/{id}/unlock [post]
+func (s *AdminService) UnlockUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := s.RepoPG.ResetFailedLogins(id); err != nil {
+		return notFoundOr500(c, err, "User not found", "Failed to unlock user")
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+	entry := postgres.AuthAuditLog{UserID: &id, ActorID: &actorID, IPAddress: c.IP(), Event: postgres.AuditAccountUnlocked}
+	if err := s.AuditRepo.CreateAuthAudit(entry); err != nil {
+		log.Println("Failed to write auth audit log:", err)
+	}
+	return c.JSON(fiber.Map{"message": "User unlocked"})
+}
+
+// ResetUserPassword godoc
+// @Summary      Reset User Password
+// @Description  Admin menerbitkan token reset password sekali pakai (berlaku 24 jam). Semua sesi user dicabut dan user wajib set password baru lewat POST /auth/password/reset sebelum bisa login lagi.
+// @Tags         Users (Admin)
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: {reset_token, expires_at}}"
+// @Failure      404  {object} map[string]interface{}
+// @Router       /users/{id}/password-reset [post]
+func (s *AdminService) ResetUserPassword(c *fiber.Ctx) error {
+	id := c.Params("id")
+	actorID, _ := c.Locals("user_id").(string)
+
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate reset token"})
+	}
+	expiresAt := time.Now().Add(utils.PasswordResetTTL)
+
+	err = s.TokenRepo.CreatePasswordResetToken(postgres.PasswordResetToken{
+		UserID:    id,
+		CreatedBy: actorID,
+		TokenHash: hash,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return notFoundOr500(c, err, "User not found", "Failed to create reset token")
+	}
+
+	entry := postgres.AuthAuditLog{UserID: &id, ActorID: &actorID, IPAddress: c.IP(), Event: postgres.AuditPasswordReset}
+	if err := s.AuditRepo.CreateAuthAudit(entry); err != nil {
+		log.Println("Failed to write auth audit log:", err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password reset token issued",
+		"data": fiber.Map{
+			"reset_token": token,
+			"expires_at":  expiresAt,
+		},
+	})
+}
+
+// ResetUserTOTP godoc
+// @Summary      Reset User 2FA
+// @Description  Menghapus TOTP & recovery code user (misal HP hilang) dan mencabut semua sesinya. Jika role wajib 2FA, user akan diminta enrol ulang saat login.
+// @Tags         Users (Admin)
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Router       /users/{id}/2fa/reset [post]
+func (s *AdminService) ResetUserTOTP(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := s.MFARepo.DisableTOTP(id); err != nil {
+		return notFoundOr500(c, err, "User not found", "Failed to reset 2FA")
+	}
+	if err := s.TokenRepo.RevokeAllUserSessions(id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "2FA reset but failed to revoke sessions"})
+	}
+
+	actorID, _ := c.Locals("user_id").(string)
+	entry := postgres.AuthAuditLog{UserID: &id, ActorID: &actorID, IPAddress: c.IP(), Event: postgres.AuditMFADisabled, Detail: "reset by admin"}
+	if err := s.AuditRepo.CreateAuthAudit(entry); err != nil {
+		log.Println("Failed to write auth audit log:", err)
+	}
+	return c.JSON(fiber.Map{"message": "Two-factor authentication reset"})
+}
+
+// GetUserAuthLogs godoc
+// @Summary      Get User Auth Audit Logs
+// @Description  Riwayat audit autentikasi user (login gagal, terkunci, unlock)
+// @Tags         Users (Admin)
+// @Security     BearerAuth
+// @Param        id     path      string  true   "User ID"
+// @Param        limit  query     int     false  "Jumlah entri" default(50)
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: [AuthAuditLog]}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /users/{id}/auth-logs [get]
+func (s *AdminService) GetUserAuthLogs(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	logs, err := s.AuditRepo.GetAuthAuditByUserID(c.Params("id"), limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch audit logs"})
+	}
+	if logs == nil {
+		logs = []postgres.AuthAuditLog{}
+	}
+	return c.JSON(fiber.Map{"data": logs})
+}
+
 // Delete User
 
 // DeleteUser godoc
 // @Summary      Delete User
-// @Description  Menghapus user permanen
+// @Description  Menghapus user permanen (token user otomatis tidak berlaku karena user tidak ditemukan)
 // @Tags         Users (Admin)
 // @Security     BearerAuth
 // @Param        id   path      string  true  "User ID"
@@ -208,47 +345,37 @@ func (s *AdminService) DeleteUser(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
-// @Param        page   query     int     false  "Page Number" default(1)
-// @Param        limit  query     int     false  "Items per Page" default(10)
-// @Success      200  {object}  map[string]interface{} "Structure: {data: [], meta: {page, limit, total_data, total_page}}"
+// @Param        status         query  string  false  "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)"
+// @Param        type           query  string  false  "Filter achievement type"
+// @Param        tag            query  string  false  "Filter tag"
+// @Param        from           query  string  false  "Dibuat sejak (YYYY-MM-DD / RFC3339)"
+// @Param        to             query  string  false  "Dibuat sampai (YYYY-MM-DD / RFC3339)"
+// @Param        min_points     query  int     false  "Poin minimum"
+// @Param        max_points     query  int     false  "Poin maksimum"
+// @Param        student_id     query  string  false  "Filter student UUID"
+// @Param        program_study  query  string  false  "Filter program studi"
+// @Param        advisor_id     query  string  false  "Filter dosen wali (lecturer UUID)"
+// @Param        sort           query  string  false  "created_at | updated_at | points | title" default(created_at)
+// @Param        order          query  string  false  "asc | desc" default(desc)
+// @Param        limit          query  int     false  "Jumlah item per halaman (maks 100)" default(20)
+// @Param        cursor         query  string  false  "Cursor dari meta.next_cursor halaman sebelumnya"
+// @Success      200  {object}  map[string]interface{} "Structure: {data: [], meta: {limit, sort, next_cursor}}"
+// @Failure      400  {object}  map[string]interface{}
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /achievements/all [get]
 func (s *AdminService) GetAllAchievements(c *fiber.Ctx) error {
-	// Ambil Query params page & limit
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	offset := (page - 1) * limit
-
-	// Ambil Data dari Postgres
-	refs, total, err := s.RepoAchPG.GetAllAchievements(limit, offset)
+	q, err := parseListingQuery(c)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch achievements"})
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	// Merge dengan MongoDB Detail
-	var results []map[string]interface{}
-	ctx := context.Background()
-
-	for _, ref := range refs {
-		detail, _ := s.RepoMongo.FindAchievementByID(ctx, ref.MongoAchievementID)
-		item := map[string]interface{}{
+	results, next, err := listAchievements(context.Background(), s.RepoAchPG, s.RepoMongo, q, func(ref postgres.AchievementReference) map[string]interface{} {
+		return map[string]interface{}{
 			"id":         ref.ID,
 			"student_id": ref.StudentID,
 			"status":     ref.Status,
 			"created_at": ref.CreatedAt,
-			"detail":     detail,
 		}
-		results = append(results, item)
-	}
-
-	// Response dengan Metadata Pagination
-	return c.JSON(fiber.Map{
-		"data": results,
-		"meta": fiber.Map{
-			"page":       page,
-			"limit":      limit,
-			"total_data": total,
-			"total_page": (total + limit - 1) / limit,
-		},
 	})
-}
\ No newline at end of file
+	return listingResponse(c, results, q, next, err)
+}
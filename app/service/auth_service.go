@@ -4,32 +4,107 @@ import (
 	"be_uas/app/model/postgres"
 	repoPG "be_uas/app/repository/postgres"
 	"be_uas/utils"
-	"os"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	UserRepo repoPG.IUserRepo
+	UserRepo  repoPG.IUserRepo
+	TokenRepo repoPG.ITokenRepoPG
+	AuditRepo repoPG.IAuditRepoPG
+	MFARepo   repoPG.IMFARepoPG
+
+	// Pembanding password saat login, default utils.CheckPassword
+	CheckPassword func(hashedPassword, password string) bool
+}
+
+func NewAuthService(userRepo repoPG.IUserRepo, tokenRepo repoPG.ITokenRepoPG, auditRepo repoPG.IAuditRepoPG, mfaRepo repoPG.IMFARepoPG) *AuthService {
+	return &AuthService{UserRepo: userRepo, TokenRepo: tokenRepo, AuditRepo: auditRepo, MFARepo: mfaRepo, CheckPassword: utils.CheckPassword}
 }
 
-func NewAuthService(userRepo repoPG.IUserRepo) *AuthService {
-	return &AuthService{UserRepo: userRepo}
+// Batas percobaan login
+const (
+	MaxFailedLogins = 5           // Gagal berturut-turut sebelum akun dikunci
+	AccountLockBase = time.Minute // Lama kunci pertama, dobel tiap gagal berikutnya
+	AccountLockMax  = time.Hour
+	IPFailureWindow = 15 * time.Minute // Jendela hitung gagal per IP
+	MaxIPFailures   = 20               // Gagal per IP sebelum backoff berlaku
+	IPBackoffBase   = time.Second
+	IPBackoffMax    = 15 * time.Minute
+)
+
+// Durasi backoff eksponensial: base * 2^(n-1), dibatasi max
+func backoff(base, max time.Duration, n int) time.Duration {
+	if n < 1 {
+		return 0
+	}
+	d := time.Duration(float64(base) * math.Pow(2, float64(n-1)))
+	if d <= 0 || d > max {
+		return max
+	}
+	return d
+}
+
+// Catat event audit autentikasi (gagal simpan audit tidak menggagalkan request)
+func (s *AuthService) audit(c *fiber.Ctx, event string, user *postgres.User, username, detail string) {
+	entry := postgres.AuthAuditLog{
+		Username:  username,
+		IPAddress: c.IP(),
+		Event:     event,
+		Detail:    detail,
+	}
+	if user != nil {
+		entry.UserID = &user.ID
+	}
+	if err := s.AuditRepo.CreateAuthAudit(entry); err != nil {
+		log.Println("Failed to write auth audit log:", err)
+	}
+}
+
+// Respon 429/423 dengan header Retry-After
+func retryAfter(c *fiber.Ctx, status int, message string, wait time.Duration) error {
+	secs := int(math.Ceil(wait.Seconds()))
+	c.Set("Retry-After", fmt.Sprint(secs))
+	return c.Status(status).JSON(fiber.Map{"status": "fail", "message": message, "retry_after": secs})
+}
+
+// Bersihkan denylist token yang sudah kedaluwarsa secara berkala
+func StartRevokedTokenCleanup(repo repoPG.ITokenRepoPG, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := repo.DeleteExpiredTokens()
+			if err != nil {
+				log.Println("Failed to clean up revoked tokens:", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Cleaned up %d expired revoked tokens\n", n)
+			}
+		}
+	}()
 }
 
 // Login godoc
 // @Summary      Login User
-// @Description  Masuk sebagai Admin, Dosen, atau Mahasiswa
+// @Description  Masuk sebagai Admin, Dosen, atau Mahasiswa. Jika 2FA aktif, lanjutkan ke POST /auth/login/2fa dengan mfa_token dari response.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        request body postgres.LoginRequest true "Login Credentials"
-// @Success      200  {object} postgres.LoginResponse
+// @Success      200  {object} postgres.LoginResponse "Jika 2FA aktif/wajib: {status: mfa_required | mfa_enrollment_required, data: {mfa_token}}"
 // @Failure      400  {object} map[string]interface{}
-// @Failure      401  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{} "Kredensial salah atau akun terkunci sementara (respon sama)"
+// @Failure      403  {object} map[string]interface{} "User nonaktif atau wajib ganti password (code: password_change_required)"
+// @Failure      429  {object} map[string]interface{} "Terlalu banyak percobaan dari IP ini"
 // @Router       /auth/login [post]
 func (s *AuthService) Login(c *fiber.Ctx) error {
 	var req postgres.LoginRequest
@@ -37,14 +112,40 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"status": "fail", "message": "Invalid request body"})
 	}
 
+	// Backoff per IP: setelah MaxIPFailures gagal, tiap gagal berikutnya menggandakan waktu tunggu
+	wait, err := s.ipBackoff(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to check login attempts"})
+	}
+	if wait > 0 {
+		s.audit(c, postgres.AuditLoginIPBlocked, nil, req.Username, "")
+		return retryAfter(c, 429, "Too many failed login attempts, try again later", wait)
+	}
+
 	// Cek User
 	user, err := s.UserRepo.GetByUsername(req.Username)
 	if err != nil {
+		// Tetap jalankan bcrypt terhadap hash dummy agar waktu respon sama dengan password salah
+		s.CheckPassword(utils.DummyPasswordHash(), req.Password)
+		s.audit(c, postgres.AuditLoginFailed, nil, req.Username, "unknown username")
 		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid credentials"})
 	}
 
+	// Akun terkunci dicek setelah password dan dijawab sama dengan kredensial salah,
+	// agar status kunci tidak membocorkan bahwa username terdaftar
+	locked := user.LockedUntil != nil && time.Now().Before(*user.LockedUntil)
+
 	// Cek Password 
-	if !utils.CheckPassword(user.PasswordHash, req.Password) {
+	if !s.CheckPassword(user.PasswordHash, req.Password) {
+		if locked {
+			s.audit(c, postgres.AuditLoginLocked, user, req.Username, "wrong password")
+		} else {
+			s.recordFailedLogin(c, user, req.Username, "wrong password")
+		}
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid credentials"})
+	}
+	if locked {
+		s.audit(c, postgres.AuditLoginLocked, user, req.Username, "")
 		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid credentials"})
 	}
 
@@ -52,8 +153,37 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		return c.Status(403).JSON(fiber.Map{"status": "fail", "message": "User inactive"})
 	}
 
-	// Generate Tokens 
-	t, rt, err := utils.GenerateTokens(user.ID, user.Username, user.RoleName, user.RoleID)
+	// Password sudah di-reset admin: wajib set password baru pakai token reset dulu
+	if user.MustChangePassword {
+		return c.Status(403).JSON(fiber.Map{
+			"status":  "fail",
+			"code":    "password_change_required",
+			"message": "Password must be changed using the reset token before logging in",
+		})
+	}
+
+	// 2FA aktif: token asli baru diterbitkan setelah kode TOTP diverifikasi
+	if user.TOTPEnabled {
+		return s.mfaChallenge(c, user, utils.TokenTypeMFA, "mfa_required")
+	}
+	// Role wajib 2FA tapi user belum enrol: hanya boleh akses endpoint enrolment
+	if user.Require2FA {
+		return s.mfaChallenge(c, user, utils.TokenTypeMFAEnroll, "mfa_enrollment_required")
+	}
+
+	return s.completeLogin(c, user)
+}
+
+// Login berhasil: reset counter gagal lalu terbitkan access + refresh token
+func (s *AuthService) completeLogin(c *fiber.Ctx, user *postgres.User) error {
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.UserRepo.ResetFailedLogins(user.ID); err != nil {
+			log.Println("Failed to reset failed logins:", err)
+		}
+	}
+
+	// Generate Tokens (family baru untuk setiap login)
+	t, rt, err := s.issueTokens(user, uuid.New().String())
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to generate tokens"})
 	}
@@ -75,6 +205,143 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
+// Sisa waktu tunggu untuk IP ini (0 jika boleh mencoba login)
+func (s *AuthService) ipBackoff(c *fiber.Ctx) (time.Duration, error) {
+	failures, last, err := s.AuditRepo.GetRecentFailuresByIP(c.IP(), time.Now().Add(-IPFailureWindow))
+	if err != nil {
+		return 0, err
+	}
+	if failures < MaxIPFailures || last == nil {
+		return 0, nil
+	}
+	return time.Until(last.Add(backoff(IPBackoffBase, IPBackoffMax, failures-MaxIPFailures+1))), nil
+}
+
+// Catat gagal login (password / kode 2FA salah) dan kunci akun jika melewati batas
+func (s *AuthService) recordFailedLogin(c *fiber.Ctx, user *postgres.User, username, detail string) {
+	s.audit(c, postgres.AuditLoginFailed, user, username, detail)
+	attempts, err := s.UserRepo.RecordFailedLogin(user.ID)
+	if err != nil {
+		log.Println("Failed to record failed login:", err)
+	}
+	if attempts >= MaxFailedLogins {
+		lock := backoff(AccountLockBase, AccountLockMax, attempts-MaxFailedLogins+1)
+		if err := s.UserRepo.LockUser(user.ID, time.Now().Add(lock)); err != nil {
+			log.Println("Failed to lock user:", err)
+		}
+		s.audit(c, postgres.AuditAccountLocked, user, username, fmt.Sprintf("%d failed attempts, locked for %s", attempts, lock))
+	}
+}
+
+// ChangePassword godoc
+// @Summary      Change Password
+// @Description  Ganti password sendiri. Password lama wajib benar dan password baru harus memenuhi password policy. Semua sesi lain dicabut, response berisi token baru.
+// @Tags         Auth
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body postgres.ChangePasswordRequest true "Password lama & baru"
+// @Success      200  {object} map[string]interface{} "Format: {status, data: {token, refreshToken}}"
+// @Failure      400  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{}
+// @Failure      422  {object} map[string]interface{} "Password baru melanggar policy"
+// @Router       /auth/password [post]
+func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req postgres.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.CurrentPassword == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"status": "fail", "message": "current_password and new_password are required"})
+	}
+
+	hash, err := s.UserRepo.GetPasswordHash(userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"status": "fail", "message": "User not found"})
+	}
+	if !utils.CheckPassword(hash, req.CurrentPassword) {
+		s.audit(c, postgres.AuditLoginFailed, &postgres.User{ID: userID}, "", "wrong current password on password change")
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Current password is incorrect"})
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return policyViolation(c, []string{"must differ from the current password"})
+	}
+	if violations := utils.ValidatePassword(req.NewPassword); len(violations) > 0 {
+		return policyViolation(c, violations)
+	}
+
+	newHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to hash password"})
+	}
+	// Password baru dan pencabutan semua sesi lama (termasuk di perangkat lain) satu transaksi,
+	// sesi ini diganti token baru
+	if err := s.TokenRepo.ChangePassword(userID, newHash); err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to update password"})
+	}
+	s.audit(c, postgres.AuditPasswordChanged, &postgres.User{ID: userID}, "", "")
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Password updated, please login again"})
+	}
+	t, rt, err := s.issueTokens(user, uuid.New().String())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Password updated, please login again"})
+	}
+
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Password updated",
+		"data": fiber.Map{
+			"token":        t,
+			"refreshToken": rt,
+		},
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset Password with Token
+// @Description  Set password baru memakai token reset sekali pakai yang diterbitkan admin. Setelah berhasil, login dengan password baru.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body postgres.ResetPasswordRequest true "Token reset & password baru"
+// @Success      200  {object} map[string]interface{}
+// @Failure      400  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{} "Token tidak valid, sudah dipakai, atau kedaluwarsa"
+// @Failure      422  {object} map[string]interface{} "Password baru melanggar policy"
+// @Router       /auth/password/reset [post]
+func (s *AuthService) ResetPassword(c *fiber.Ctx) error {
+	var req postgres.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" || req.NewPassword == "" {
+		return c.Status(400).JSON(fiber.Map{"status": "fail", "message": "token and new_password are required"})
+	}
+	if violations := utils.ValidatePassword(req.NewPassword); len(violations) > 0 {
+		return policyViolation(c, violations)
+	}
+
+	newHash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to hash password"})
+	}
+
+	userID, err := s.TokenRepo.ConsumePasswordResetToken(utils.HashToken(req.Token), newHash)
+	if err == sql.ErrNoRows {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid or expired reset token"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to reset password"})
+	}
+	s.audit(c, postgres.AuditPasswordResetUsed, &postgres.User{ID: userID}, "", "")
+
+	return c.JSON(fiber.Map{"status": "success", "message": "Password updated, please login with the new password"})
+}
+
+// Respon 422 untuk password yang melanggar policy
+func policyViolation(c *fiber.Ctx, violations []string) error {
+	return c.Status(422).JSON(fiber.Map{"status": "fail", "message": "Password does not meet policy", "errors": violations})
+}
+
 // Seed Admin
 func (s *AuthService) SeedAdmin(c *fiber.Ctx) error {
 	var req postgres.SeedRequest
@@ -103,42 +370,128 @@ func (s *AuthService) SeedAdmin(c *fiber.Ctx) error {
 
 // RefreshToken godoc
 // @Summary      Refresh Access Token
-// @Description  Dapatkan token baru menggunakan Refresh Token (Mekanisme via Header Authorization saat ini)
+// @Description  Tukar refresh token dengan access token baru. Refresh token dirotasi setiap dipakai; token lama yang dipakai ulang akan mencabut seluruh rantai sesi.
 // @Tags         Auth
-// @Security     BearerAuth
+// @Accept       json
 // @Produce      json
-// @Success      200  {object} map[string]interface{}
+// @Param        request body postgres.RefreshRequest true "Refresh Token"
+// @Success      200  {object} map[string]interface{} "Format: {status, data: {token, refreshToken}}"
+// @Failure      400  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{}
+// @Failure      500  {object} map[string]interface{}
 // @Router       /auth/refresh [post]
 func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
-    userID := c.Locals("user_id").(string)
-    user, err := s.UserRepo.GetUserByID(userID) 
-    if err != nil {
-        return c.Status(401).JSON(fiber.Map{"error": "Please login again"})
-    }
+	var req postgres.RefreshRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(400).JSON(fiber.Map{"status": "fail", "message": "refreshToken is required"})
+	}
 
-    // Generate New Token (Logic sama dengan Login)
-    claims := jwt.MapClaims{
-        "user_id":   user.ID,
-        "username":  user.Username,
-        "role":      user.RoleName,
-        "role_id":   user.RoleID,
-        "exp":       time.Now().Add(time.Hour * 24).Unix(),
-    }
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    t, _ := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	rt, err := s.TokenRepo.GetRefreshTokenByHash(utils.HashToken(req.RefreshToken))
+	if err != nil || rt.RevokedAt != nil || time.Now().After(rt.ExpiresAt) {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid or expired refresh token"})
+	}
+
+	// Token yang sudah pernah dirotasi dipakai lagi -> kemungkinan dicuri, cabut satu family
+	if rt.UsedAt != nil {
+		return s.rejectRefreshFamily(c, rt.FamilyID, "Refresh token reuse detected, please login again")
+	}
+	ok, err := s.TokenRepo.MarkRefreshTokenUsed(rt.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to rotate refresh token"})
+	}
+	if !ok {
+		return s.rejectRefreshFamily(c, rt.FamilyID, "Refresh token reuse detected, please login again")
+	}
+
+	user, err := s.UserRepo.GetUserByID(rt.UserID)
+	if err != nil || !user.IsActive {
+		return s.rejectRefreshFamily(c, rt.FamilyID, "Please login again")
+	}
+
+	t, newRT, err := s.issueTokens(user, rt.FamilyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to generate tokens"})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data": fiber.Map{
+			"token":        t,
+			"refreshToken": newRT,
+		},
+	})
+}
 
-    return c.JSON(fiber.Map{"token": t})
+// Cabut satu family refresh token lalu tolak request (401). Jika pencabutan gagal -> 500,
+// agar family yang mungkin dicuri tidak dianggap sudah dicabut
+func (s *AuthService) rejectRefreshFamily(c *fiber.Ctx, familyID, message string) error {
+	if err := s.TokenRepo.RevokeRefreshTokenFamily(familyID); err != nil {
+		log.Println("Failed to revoke refresh token family:", err)
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to revoke refresh token"})
+	}
+	return c.Status(401).JSON(fiber.Map{"status": "fail", "message": message})
 }
 
 // Logout godoc
 // @Summary      Logout
-// @Description  Logout user (Client side clear token)
+// @Description  Mencabut access token yang sedang dipakai (dan refresh token jika dikirim di body). Gunakan ?all=true untuk logout dari semua sesi.
 // @Tags         Auth
 // @Security     BearerAuth
+// @Accept       json
+// @Param        all      query     bool                     false  "Cabut semua sesi user"
+// @Param        request  body      postgres.RefreshRequest  false  "Refresh Token (opsional)"
 // @Success      200  {object} map[string]interface{}
+// @Failure      500  {object} map[string]interface{}
 // @Router       /auth/logout [post]
 func (s *AuthService) Logout(c *fiber.Ctx) error {
-    return c.JSON(fiber.Map{"message": "Successfully logged out. Please remove token from client storage."})
+    userID := c.Locals("user_id").(string)
+
+    if c.QueryBool("all") {
+        if err := s.TokenRepo.RevokeAllUserSessions(userID); err != nil {
+            return c.Status(500).JSON(fiber.Map{"error": "Failed to revoke sessions"})
+        }
+        return c.JSON(fiber.Map{"message": "Successfully logged out from all sessions"})
+    }
+
+    jti, _ := c.Locals("jti").(string)
+    exp, ok := c.Locals("token_exp").(time.Time)
+    if !ok {
+        exp = time.Now().Add(utils.AccessTokenTTL)
+    }
+    if err := s.TokenRepo.RevokeToken(jti, userID, exp); err != nil {
+        return c.Status(500).JSON(fiber.Map{"error": "Failed to revoke token"})
+    }
+
+    // Refresh token milik user ini ikut dicabut (satu family)
+    var req postgres.RefreshRequest
+    if err := c.BodyParser(&req); err == nil && req.RefreshToken != "" {
+        rt, err := s.TokenRepo.GetRefreshTokenByHash(utils.HashToken(req.RefreshToken))
+        if err == nil && rt.UserID == userID {
+            if err := s.TokenRepo.RevokeRefreshTokenFamily(rt.FamilyID); err != nil {
+                return c.Status(500).JSON(fiber.Map{"error": "Failed to revoke refresh token"})
+            }
+        }
+    }
+
+    return c.JSON(fiber.Map{"message": "Successfully logged out"})
+}
+
+// JWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public key untuk memverifikasi access token secara offline (termasuk key lama selama masa rotasi)
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {keys: [JWK]}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /.well-known/jwks.json [get]
+func (s *AuthService) JWKS(c *fiber.Ctx) error {
+	jwks, err := utils.JWKS()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load signing keys"})
+	}
+
+	c.Set("Cache-Control", "public, max-age=300")
+	return c.JSON(jwks)
 }
 
 // GetProfile godoc
@@ -172,4 +525,28 @@ func (s *AuthService) GetProfile(c *fiber.Ctx) error {
 			},
 		},
 	})
-}
\ No newline at end of file
+}
+
+// Buat access token + refresh token baru (hash refresh token disimpan di DB)
+func (s *AuthService) issueTokens(user *postgres.User, familyID string) (string, string, error) {
+	t, err := utils.GenerateAccessToken(user.ID, user.Username, user.RoleName, user.RoleID, user.TokenVersion)
+	if err != nil {
+		return "", "", err
+	}
+
+	rt, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.TokenRepo.CreateRefreshToken(postgres.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return t, rt, nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	modelPG "be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/middleware"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batas waktu penulis mengubah / menghapus komentarnya sendiri (Admin bisa menghapus kapan saja)
+const (
+	CommentEditWindow   = 15 * time.Minute
+	CommentDeleteWindow = time.Hour
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_.]{3,50})`)
+
+type CommentService struct {
+	Repo repoPG.ICommentRepoPG
+
+	Notifier *NotificationService // Opsional (nil = tanpa notifikasi)
+}
+
+func NewCommentService(repo repoPG.ICommentRepoPG) *CommentService {
+	return &CommentService{Repo: repo}
+}
+
+// ListComments godoc
+// @Summary      Get Achievement Comments
+// @Description  Diskusi pada prestasi dalam bentuk thread (komentar utama + balasan). Hanya pemilik, dosen wali, dan Admin yang bisa melihat
+// @Tags         Achievement Comments
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id   path      string  true  "Achievement Ref ID"
+// @Success      200  {object} map[string]interface{} "Format: {data: [AchievementComment + replies]}"
+// @Failure      403  {object} map[string]interface{}
+// @Failure      500  {object} map[string]interface{}
+// @Router       /achievements/{id}/comments [get]
+func (s *CommentService) ListComments(c *fiber.Ctx) error {
+	comments, err := s.Repo.ListComments(c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch comments"})
+	}
+	return c.JSON(fiber.Map{"data": buildThreads(comments)})
+}
+
+// CreateComment godoc
+// @Summary      Post Comment
+// @Description  Menulis komentar atau membalas (parent_id). Mention ditulis sebagai @username dan hanya berlaku untuk pihak yang boleh melihat prestasi
+// @Tags         Achievement Comments
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string                   true  "Achievement Ref ID"
+// @Param        body  body      postgres.CommentRequest  true  "Payload: { body, parent_id }"
+// @Success      201   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      404   {object}  map[string]interface{} "Error: Komentar yang dibalas tidak ditemukan"
+// @Failure      409   {object}  map[string]interface{} "Error: Komentar yang dibalas sudah dihapus"
+// @Router       /achievements/{id}/comments [post]
+func (s *CommentService) CreateComment(c *fiber.Ctx) error {
+	refID := c.Params("id")
+	var req modelPG.CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	cm := modelPG.AchievementComment{AchievementRefID: refID, AuthorID: &userID, Body: body, CreatedAt: time.Now()}
+	if req.ParentID != "" {
+		parent, err := s.Repo.GetComment(refID, req.ParentID)
+		if err != nil {
+			return notFoundOr500(c, err, "Parent comment not found", "Failed to fetch parent comment")
+		}
+		if parent.DeletedAt != nil {
+			return c.Status(409).JSON(fiber.Map{"error": "Cannot reply to a deleted comment"})
+		}
+		// Balasan dari balasan tetap masuk ke thread yang sama
+		root := parent.ID
+		if parent.ParentID != nil {
+			root = *parent.ParentID
+		}
+		cm.ParentID = &root
+	}
+
+	mentions, err := s.resolveMentions(refID, body)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to resolve mentions"})
+	}
+	created, err := s.Repo.CreateComment(cm, mentionIDs(mentions))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save comment"})
+	}
+	created.Mentions = mentions
+	s.Notifier.Publish(modelPG.NotificationEvent{
+		Type: modelPG.NotifCommentCreated, ActorID: userID, AchievementRefID: refID,
+		CommentID: created.ID, Note: body, MentionedUserIDs: mentionIDs(mentions),
+	})
+	return c.Status(201).JSON(fiber.Map{"data": created})
+}
+
+// UpdateComment godoc
+// @Summary      Edit Comment
+// @Description  Penulis dapat mengubah komentarnya sendiri maksimal 15 menit setelah ditulis. Mention dihitung ulang dari isi baru
+// @Tags         Achievement Comments
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string                   true  "Achievement Ref ID"
+// @Param        commentId  path      string                   true  "Comment ID"
+// @Param        body       body      postgres.CommentRequest  true  "Payload: { body }"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{} "Error: Bukan penulis / batas waktu edit lewat"
+// @Failure      404   {object}  map[string]interface{}
+// @Router       /achievements/{id}/comments/{commentId} [patch]
+func (s *CommentService) UpdateComment(c *fiber.Ctx) error {
+	refID := c.Params("id")
+	var req modelPG.CommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	body, err := commentBody(req.Body)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	cm, err := s.Repo.GetComment(refID, c.Params("commentId"))
+	if err != nil {
+		return notFoundOr500(c, err, "Comment not found", "Failed to fetch comment")
+	}
+	if cm.DeletedAt != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Comment not found"})
+	}
+	if !isAuthor(c, cm) {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only edit your own comments"})
+	}
+	if time.Since(cm.CreatedAt) > CommentEditWindow {
+		return c.Status(403).JSON(fiber.Map{"error": fmt.Sprintf("Comments can only be edited within %s", CommentEditWindow)})
+	}
+
+	mentions, err := s.resolveMentions(refID, body)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to resolve mentions"})
+	}
+	if err := s.Repo.UpdateComment(cm.ID, body, mentionIDs(mentions)); err != nil {
+		return notFoundOr500(c, err, "Comment not found", "Failed to update comment")
+	}
+	return c.JSON(fiber.Map{"message": "Comment updated"})
+}
+
+// DeleteComment godoc
+// @Summary      Delete Comment
+// @Description  Penulis dapat menghapus komentarnya sendiri maksimal 1 jam setelah ditulis; Admin dapat menghapus kapan saja. Balasan tetap ditampilkan
+// @Tags         Achievement Comments
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path      string  true  "Achievement Ref ID"
+// @Param        commentId  path      string  true  "Comment ID"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      403   {object}  map[string]interface{} "Error: Bukan penulis / batas waktu hapus lewat"
+// @Failure      404   {object}  map[string]interface{}
+// @Router       /achievements/{id}/comments/{commentId} [delete]
+func (s *CommentService) DeleteComment(c *fiber.Ctx) error {
+	cm, err := s.Repo.GetComment(c.Params("id"), c.Params("commentId"))
+	if err != nil {
+		return notFoundOr500(c, err, "Comment not found", "Failed to fetch comment")
+	}
+	if cm.DeletedAt != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Comment not found"})
+	}
+
+	if !middleware.HasFullAccess(c) {
+		if !isAuthor(c, cm) {
+			return c.Status(403).JSON(fiber.Map{"error": "You can only delete your own comments"})
+		}
+		if time.Since(cm.CreatedAt) > CommentDeleteWindow {
+			return c.Status(403).JSON(fiber.Map{"error": fmt.Sprintf("Comments can only be deleted within %s", CommentDeleteWindow)})
+		}
+	}
+
+	if err := s.Repo.DeleteComment(cm.ID); err != nil {
+		return notFoundOr500(c, err, "Comment not found", "Failed to delete comment")
+	}
+	return c.JSON(fiber.Map{"message": "Comment deleted"})
+}
+
+func commentBody(raw string) (string, error) {
+	body := strings.TrimSpace(raw)
+	if body == "" {
+		return "", fmt.Errorf("body is required")
+	}
+	if len([]rune(body)) > modelPG.MaxCommentLength {
+		return "", fmt.Errorf("body must be at most %d characters", modelPG.MaxCommentLength)
+	}
+	return body, nil
+}
+
+func isAuthor(c *fiber.Ctx, cm *modelPG.AchievementComment) bool {
+	userID, _ := c.Locals("user_id").(string)
+	return cm.AuthorID != nil && *cm.AuthorID == userID
+}
+
+// Username yang di-mention (@username), tanpa duplikat
+func parseMentions(body string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.TrimRight(m[1], ".") // Titik di akhir kalimat bukan bagian username
+		if name != "" && !seen[name] {
+			seen[name] = true
+			usernames = append(usernames, name)
+		}
+	}
+	return usernames
+}
+
+// Mention ke user yang tidak bisa melihat prestasi diabaikan (tetap jadi teks biasa)
+func (s *CommentService) resolveMentions(refID, body string) ([]modelPG.CommentMention, error) {
+	usernames := parseMentions(body)
+	if len(usernames) == 0 {
+		return []modelPG.CommentMention{}, nil
+	}
+	users, err := s.Repo.FindMentionableUsers(refID, usernames)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []modelPG.CommentMention{}
+	}
+	return users, nil
+}
+
+func mentionIDs(mentions []modelPG.CommentMention) []string {
+	ids := make([]string, 0, len(mentions))
+	for _, m := range mentions {
+		ids = append(ids, m.UserID)
+	}
+	return ids
+}
+
+// Susun komentar (urut waktu) menjadi thread. Komentar utama yang dihapus
+// dan tidak punya balasan tidak ditampilkan lagi
+func buildThreads(comments []modelPG.AchievementComment) []modelPG.AchievementComment {
+	replies := map[string][]modelPG.AchievementComment{}
+	for _, cm := range comments {
+		if cm.ParentID != nil && cm.DeletedAt == nil {
+			replies[*cm.ParentID] = append(replies[*cm.ParentID], cm)
+		}
+	}
+
+	threads := []modelPG.AchievementComment{}
+	for _, cm := range comments {
+		if cm.ParentID != nil {
+			continue
+		}
+		cm.Replies = replies[cm.ID]
+		if cm.DeletedAt != nil && len(cm.Replies) == 0 {
+			continue
+		}
+		threads = append(threads, cm)
+	}
+	return threads
+}
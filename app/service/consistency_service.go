@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	modelPG "be_uas/app/model/postgres"
+	repoMongo "be_uas/app/repository/mongodb"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/storage"
+	"be_uas/app/workflow"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Pengaturan outbox worker & rekonsiliasi
+const (
+	OutboxBatchSize       = 50
+	MaxOutboxAttempts     = 10
+	OutboxRetryBase       = 5 * time.Second
+	OutboxRetryMax        = time.Hour
+	ReconcileBatchSize    = 500
+	OrphanGracePeriod     = 10 * time.Minute // Dokumen Mongo baru mungkin masih menunggu reference-nya dibuat
+	danglingReferenceNote = "Reconciliation: achievement detail missing in MongoDB"
+)
+
+var errUnknownOutboxEvent = errors.New("unknown outbox event type")
+
+// Hasil satu kali rekonsiliasi
+type ReconcileReport struct {
+	ReferencesScanned  int      `json:"references_scanned"`
+	DocumentsScanned   int      `json:"documents_scanned"`
+	DanglingReferences int      `json:"dangling_references"` // Reference tanpa dokumen -> ditandai deleted
+	OrphanDocuments    int      `json:"orphan_documents"`    // Dokumen tanpa reference -> di-soft-delete
+	DeletedInMongo     int      `json:"deleted_in_mongo"`    // Reference deleted tapi dokumen masih aktif
+	RestoredInMongo    int      `json:"restored_in_mongo"`   // Dokumen terhapus tapi reference masih aktif
+	Errors             []string `json:"errors"`
+}
+
+type ConsistencyService struct {
+	RepoPG    repoPG.IAchievementRepoPG
+	RepoMongo repoMongo.IAchievementRepoMongo
+	Outbox    repoPG.IOutboxRepoPG
+	Files     storage.Storage
+}
+
+func NewConsistencyService(pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchievementRepoMongo, outbox repoPG.IOutboxRepoPG, files storage.Storage) *ConsistencyService {
+	return &ConsistencyService{RepoPG: pg, RepoMongo: mongo, Outbox: outbox, Files: files}
+}
+
+// Jalankan event outbox yang jatuh tempo secara berkala
+func StartOutboxWorker(s *ConsistencyService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			if _, err := processOutbox(ctx, s.Outbox, s.RepoMongo, s.Files, "", OutboxBatchSize); err != nil {
+				log.Println("Failed to process achievement outbox:", err)
+			}
+			cancel()
+		}
+	}()
+}
+
+// Rekonsiliasi PostgreSQL <-> MongoDB secara berkala
+func StartReconciliationJob(s *ConsistencyService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			report := s.Reconcile(context.Background())
+			if n := report.DanglingReferences + report.OrphanDocuments + report.DeletedInMongo + report.RestoredInMongo; n > 0 || len(report.Errors) > 0 {
+				log.Printf("Achievement reconciliation: %d repaired, %d errors\n", n, len(report.Errors))
+			}
+		}
+	}()
+}
+
+// Eksekusi event outbox (aggregateID kosong = semua). Return jumlah event yang berhasil
+func processOutbox(ctx context.Context, outbox repoPG.IOutboxRepoPG, mongo repoMongo.IAchievementRepoMongo, files storage.Storage, aggregateID string, limit int) (int, error) {
+	events, err := outbox.ClaimEvents(aggregateID, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, ev := range events {
+		err := applyOutboxEvent(ctx, mongo, files, ev)
+		if err == nil {
+			if err := outbox.MarkEventDone(ev.ID); err != nil {
+				return done, err
+			}
+			done++
+			continue
+		}
+
+		// Gagal: retry dengan backoff eksponensial, berhenti setelah MaxOutboxAttempts
+		dead := ev.Attempts >= MaxOutboxAttempts || errors.Is(err, errUnknownOutboxEvent)
+		next := time.Now().Add(backoff(OutboxRetryBase, OutboxRetryMax, ev.Attempts))
+		if markErr := outbox.MarkEventFailed(ev.ID, err.Error(), next, dead); markErr != nil {
+			return done, markErr
+		}
+		log.Printf("Outbox event %s (%s) failed, attempt %d: %v\n", ev.ID, ev.EventType, ev.Attempts, err)
+	}
+	return done, nil
+}
+
+func applyOutboxEvent(ctx context.Context, mongo repoMongo.IAchievementRepoMongo, files storage.Storage, ev modelPG.OutboxEvent) error {
+	switch ev.EventType {
+	case modelPG.EventAchievementSoftDelete:
+		var payload modelPG.MongoDocumentPayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil {
+			return fmt.Errorf("%w: invalid payload", errUnknownOutboxEvent)
+		}
+		return mongo.SoftDeleteAchievement(ctx, payload.MongoID)
+	case modelPG.EventAttachmentFileDelete:
+		var payload modelPG.StoredFilePayload
+		if err := json.Unmarshal(ev.Payload, &payload); err != nil || payload.StorageKey == "" {
+			return fmt.Errorf("%w: invalid payload", errUnknownOutboxEvent)
+		}
+		return files.Delete(ctx, payload.StorageKey)
+	default:
+		return fmt.Errorf("%w: %s", errUnknownOutboxEvent, ev.EventType)
+	}
+}
+
+// Reconcile mencari dan memperbaiki data yang tidak konsisten antar store.
+// PostgreSQL adalah sumber kebenaran status; MongoDB mengikuti
+func (s *ConsistencyService) Reconcile(ctx context.Context) ReconcileReport {
+	report := ReconcileReport{Errors: []string{}}
+	fail := func(format string, args ...interface{}) {
+		report.Errors = append(report.Errors, fmt.Sprintf(format, args...))
+	}
+
+	// 1. Reference -> dokumen: dangling mongo_achievement_id & status soft delete yang berbeda
+	after := ""
+	for {
+		refs, err := s.RepoPG.ListReferenceLinks(after, ReconcileBatchSize)
+		if err != nil {
+			fail("list references: %v", err)
+			break
+		}
+		if len(refs) == 0 {
+			break
+		}
+		after = refs[len(refs)-1].ID
+		report.ReferencesScanned += len(refs)
+
+		mongoIDs := make([]string, len(refs))
+		for i, ref := range refs {
+			mongoIDs[i] = ref.MongoAchievementID
+		}
+		states, err := s.RepoMongo.FindDeletionStates(ctx, mongoIDs)
+		if err != nil {
+			fail("load mongo documents: %v", err)
+			break
+		}
+
+		for _, ref := range refs {
+			deletedInMongo, exists := states[ref.MongoAchievementID]
+			refDeleted := ref.Status == workflow.StatusDeleted
+			switch {
+			case !exists && !refDeleted:
+				if err := s.RepoPG.MarkReferenceDeleted(ref.ID, danglingReferenceNote); err != nil {
+					fail("mark reference %s deleted: %v", ref.ID, err)
+					continue
+				}
+				report.DanglingReferences++
+			case exists && refDeleted && !deletedInMongo:
+				if err := s.RepoMongo.SoftDeleteAchievement(ctx, ref.MongoAchievementID); err != nil {
+					fail("soft delete document %s: %v", ref.MongoAchievementID, err)
+					continue
+				}
+				report.DeletedInMongo++
+			case exists && !refDeleted && deletedInMongo:
+				if err := s.RepoMongo.RestoreAchievement(ctx, ref.MongoAchievementID); err != nil {
+					fail("restore document %s: %v", ref.MongoAchievementID, err)
+					continue
+				}
+				report.RestoredInMongo++
+			}
+		}
+		if len(refs) < ReconcileBatchSize {
+			break
+		}
+	}
+
+	// 2. Dokumen -> reference: dokumen yatim dari create yang gagal di tengah jalan
+	cutoff := time.Now().Add(-OrphanGracePeriod)
+	after = ""
+	for {
+		ids, err := s.RepoMongo.ListActiveIDsBefore(ctx, cutoff, after, ReconcileBatchSize)
+		if err != nil {
+			fail("list mongo documents: %v", err)
+			break
+		}
+		if len(ids) == 0 {
+			break
+		}
+		after = ids[len(ids)-1]
+		report.DocumentsScanned += len(ids)
+
+		referenced, err := s.RepoPG.FindReferencedMongoIDs(ids)
+		if err != nil {
+			fail("load references: %v", err)
+			break
+		}
+		for _, id := range ids {
+			if referenced[id] {
+				continue
+			}
+			if err := s.RepoMongo.SoftDeleteAchievement(ctx, id); err != nil {
+				fail("soft delete orphan %s: %v", id, err)
+				continue
+			}
+			report.OrphanDocuments++
+		}
+		if len(ids) < ReconcileBatchSize {
+			break
+		}
+	}
+
+	return report
+}
+
+// RunReconciliation godoc
+// @Summary      Run Consistency Reconciliation
+// @Description  Menjalankan rekonsiliasi PostgreSQL <-> MongoDB sekarang: reference tanpa dokumen ditandai deleted, dokumen tanpa reference di-soft-delete, dan status soft delete diselaraskan mengikuti PostgreSQL. Event outbox yang tertunda ikut diproses.
+// @Tags         Consistency (Admin)
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} service.ReconcileReport
+// @Router       /consistency/reconcile [post]
+func (s *ConsistencyService) RunReconciliation(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	processed, err := processOutbox(ctx, s.Outbox, s.RepoMongo, s.Files, "", OutboxBatchSize)
+	if err != nil {
+		log.Println("Failed to process achievement outbox:", err)
+	}
+	report := s.Reconcile(ctx)
+
+	return c.JSON(fiber.Map{"data": report, "outbox_processed": processed})
+}
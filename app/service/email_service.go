@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"be_uas/app/mailer"
+	modelPG "be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+)
+
+// Pengaturan pengiriman email outbox
+const (
+	EmailBatchSize    = 20
+	MaxEmailAttempts  = 8
+	EmailRetryBase    = 30 * time.Second
+	EmailRetryMax     = 6 * time.Hour
+	DefaultDigestHour = 7 // Jam kirim ringkasan harian Dosen Wali (waktu server)
+
+	// Batas waktu satu batch pengiriman, di bawah lease klaim agar email tidak diklaim ulang
+	// worker lain selagi masih dikirim
+	EmailBatchTimeout = repoPG.EmailLease - 30*time.Second
+)
+
+// Hasil satu kali pemrosesan outbox email
+type EmailReport struct {
+	Rendered int      `json:"rendered"` // Event workflow yang dirender menjadi email
+	Sent     int      `json:"sent"`
+	Retried  int      `json:"retried"`
+	Failed   int      `json:"failed"` // Gagal permanen (alamat ditolak / batas retry)
+	Errors   []string `json:"errors"`
+}
+
+type EmailService struct {
+	Repo         repoPG.IEmailRepoPG
+	Participants repoPG.INotificationRepoPG // Mahasiswa & dosen wali penerima event
+	Sender       mailer.Sender
+	Templates    *mailer.Templates
+
+	BaseURL    string // APP_BASE_URL, untuk link di email (kosong = tanpa link)
+	DigestHour int    // MAIL_DIGEST_HOUR
+}
+
+func NewEmailService(repo repoPG.IEmailRepoPG, participants repoPG.INotificationRepoPG, sender mailer.Sender, templates *mailer.Templates) *EmailService {
+	s := &EmailService{
+		Repo: repo, Participants: participants, Sender: sender, Templates: templates,
+		BaseURL:    strings.TrimRight(os.Getenv("APP_BASE_URL"), "/"),
+		DigestHour: DefaultDigestHour,
+	}
+	if h, err := strconv.Atoi(os.Getenv("MAIL_DIGEST_HOUR")); err == nil && h >= 0 && h < 24 {
+		s.DigestHour = h
+	}
+	return s
+}
+
+// Render event menjadi email untuk penerima notifikasinya, dalam bahasa masing-masing
+func (s *EmailService) render(ev modelPG.NotificationEvent) ([]modelPG.Email, error) {
+	var p *modelPG.NotificationParticipants
+	var err error
+	if ev.AchievementRefID != "" {
+		p, err = s.Participants.GetAchievementParticipants(ev.AchievementRefID)
+	} else {
+		p, err = s.Participants.GetStudentParticipants(ev.StudentID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := map[string]notificationRecipient{}
+	var userIDs []string
+	for _, r := range notificationRecipients(ev, p) {
+		if r.EventType == ev.Type {
+			userIDs = append(userIDs, r.UserID)
+			recipients[r.UserID] = r
+		}
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	// Dosen Wali dengan mode ringkasan menerima pengajuan baru lewat ringkasan harian
+	targets, err := s.Repo.GetEmailRecipients(userIDs, ev.Type, ev.Type == modelPG.NotifAchievementSubmitted)
+	if err != nil {
+		return nil, err
+	}
+	link := s.BaseURL
+	if s.BaseURL != "" && ev.AchievementRefID != "" {
+		link = s.BaseURL + "/achievements/" + ev.AchievementRefID
+	}
+
+	emails := make([]modelPG.Email, 0, len(targets))
+	for _, t := range targets {
+		msg, err := s.Templates.Render(ev.Type, t.Locale, mailer.Data{
+			RecipientName: t.FullName, StudentName: p.StudentName, StudentNIM: p.StudentNIM,
+			Note: ev.Note, Link: link, Advisor: recipients[t.UserID].Advisor, FormerAdvisor: recipients[t.UserID].FormerAdvisor,
+		})
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, newEmail(t, ev.Type, msg))
+	}
+	return emails, nil
+}
+
+func newEmail(t modelPG.EmailRecipient, eventType string, msg mailer.Message) modelPG.Email {
+	userID := t.UserID
+	return modelPG.Email{
+		UserID: &userID, To: t.Email, EventType: eventType,
+		Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML,
+	}
+}
+
+// Kirim email outbox yang jatuh tempo secara berkala
+func StartEmailWorker(s *EmailService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			report := s.DeliverPending(context.Background())
+			if report.Failed > 0 || len(report.Errors) > 0 {
+				log.Printf("Email outbox: %d sent, %d retried, %d failed, %d errors\n", report.Sent, report.Retried, report.Failed, len(report.Errors))
+			}
+		}
+	}()
+}
+
+// DeliverPending merender event yang tertunda lalu mengirim satu batch email (dibatasi EmailBatchTimeout).
+// Gagal sementara dijadwalkan ulang dengan backoff eksponensial; ditolak server (5xx) atau melewati
+// MaxEmailAttempts -> failed
+func (s *EmailService) DeliverPending(ctx context.Context) EmailReport {
+	report := EmailReport{Errors: []string{}}
+	s.renderEvents(&report)
+
+	ctx, cancel := context.WithTimeout(ctx, EmailBatchTimeout)
+	defer cancel()
+	emails, err := s.Repo.ClaimEmails(EmailBatchSize)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("claim emails: %v", err))
+		return report
+	}
+
+	for i, e := range emails {
+		// Waktu batch habis: sisa email dilepas untuk batch berikutnya
+		if ctx.Err() != nil {
+			var ids []string
+			for _, rest := range emails[i:] {
+				ids = append(ids, rest.ID)
+			}
+			if err := s.Repo.ReleaseEmails(ids); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("release emails: %v", err))
+			}
+			break
+		}
+
+		err := s.Sender.Send(ctx, mailer.Message{To: e.To, Subject: e.Subject, Text: e.Text, HTML: e.HTML})
+		if err == nil {
+			if err := s.Repo.MarkEmailSent(e.ID); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("mark email %s sent: %v", e.ID, err))
+				continue
+			}
+			report.Sent++
+			continue
+		}
+
+		dead := e.Attempts >= MaxEmailAttempts || errors.Is(err, mailer.ErrPermanent)
+		next := time.Now().Add(backoff(EmailRetryBase, EmailRetryMax, e.Attempts))
+		if markErr := s.Repo.MarkEmailFailed(e.ID, err.Error(), next, dead); markErr != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("mark email %s failed: %v", e.ID, markErr))
+			continue
+		}
+		if dead {
+			report.Failed++
+		} else {
+			report.Retried++
+		}
+		log.Printf("Email %s (%s) failed, attempt %d: %v\n", e.ID, e.EventType, e.Attempts, err)
+	}
+	return report
+}
+
+// Render event email yang jatuh tempo ke email_outbox (retry dengan backoff yang sama)
+func (s *EmailService) renderEvents(report *EmailReport) {
+	events, err := s.Repo.ClaimEmailEvents(EmailBatchSize)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("claim email events: %v", err))
+		return
+	}
+
+	for _, e := range events {
+		emails, err := s.render(e.Event)
+		if err == nil {
+			err = s.Repo.CompleteEmailEvent(e.ID, emails)
+		}
+		if err == nil {
+			report.Rendered++
+			continue
+		}
+
+		dead := e.Attempts >= MaxEmailAttempts
+		next := time.Now().Add(backoff(EmailRetryBase, EmailRetryMax, e.Attempts))
+		if markErr := s.Repo.MarkEmailEventFailed(e.ID, err.Error(), next, dead); markErr != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("mark email event %s failed: %v", e.ID, markErr))
+			continue
+		}
+		if dead {
+			report.Failed++
+		}
+		log.Printf("Email event %s (%s) failed, attempt %d: %v\n", e.ID, e.Event.Type, e.Attempts, err)
+	}
+}
+
+// Cek jadwal ringkasan harian secara berkala (dikirim sekali per hari setelah DigestHour)
+func StartDigestJob(s *EmailService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := s.SendDigests(time.Now())
+			if err != nil {
+				log.Println("Failed to send lecturer digests:", err)
+			}
+			if n > 0 {
+				log.Printf("Lecturer digest: %d emails queued\n", n)
+			}
+		}
+	}()
+}
+
+// SendDigests mengantrekan ringkasan prestasi yang menunggu verifikasi untuk Dosen Wali
+// dengan mode ringkasan. Dosen tanpa prestasi tertunda tidak dikirimi email
+func (s *EmailService) SendDigests(now time.Time) (int, error) {
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), s.DigestHour, 0, 0, 0, now.Location())
+	if now.Before(cutoff) {
+		return 0, nil
+	}
+	recipients, err := s.Repo.GetDigestRecipients(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	// Gagal untuk satu dosen tidak menghentikan yang lain; dosen tersebut dicoba lagi pada cek berikutnya
+	queued := 0
+	var errs []error
+	for _, rc := range recipients {
+		sent, err := s.sendDigest(rc, cutoff, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest for %s: %w", rc.UserID, err))
+			continue
+		}
+		if sent {
+			queued++
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// Render ringkasan satu dosen, lalu klaim & antrekan sekaligus agar replika lain tidak mengirim
+// duplikat. Tanpa prestasi tertunda hanya ditandai
+func (s *EmailService) sendDigest(rc modelPG.EmailRecipient, cutoff, now time.Time) (bool, error) {
+	pending, err := s.Repo.GetPendingVerifications(rc.UserID)
+	if err != nil {
+		return false, err
+	}
+	var digest *modelPG.Email
+	if len(pending) > 0 {
+		msg, err := s.Templates.Render(modelPG.EmailLecturerDigest, rc.Locale, s.digestData(rc, pending))
+		if err != nil {
+			return false, err
+		}
+		e := newEmail(rc, modelPG.EmailLecturerDigest, msg)
+		digest = &e
+	}
+	claimed, err := s.Repo.ClaimDigest(rc.UserID, cutoff, now, digest)
+	if err != nil {
+		return false, err
+	}
+	return claimed && digest != nil, nil
+}
+
+func (s *EmailService) digestData(rc modelPG.EmailRecipient, pending []modelPG.PendingVerification) mailer.Data {
+	data := mailer.Data{RecipientName: rc.FullName, Advisor: true}
+	if s.BaseURL != "" {
+		data.Link = s.BaseURL + "/achievements/advisees"
+	}
+	for _, p := range pending {
+		item := mailer.DigestItem{StudentName: p.StudentName, StudentNIM: p.StudentNIM, SubmittedAt: p.SubmittedAt}
+		if s.BaseURL != "" {
+			item.Link = s.BaseURL + "/achievements/" + p.AchievementRefID
+		}
+		data.Pending = append(data.Pending, item)
+	}
+	return data
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/utils"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Jumlah recovery code yang diterbitkan setiap kali aktivasi / regenerate
+const RecoveryCodeCount = 10
+
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Sistem Prestasi"
+}
+
+// Response login tahap pertama: token sementara untuk verifikasi / enrolment 2FA
+func (s *AuthService) mfaChallenge(c *fiber.Ctx, user *postgres.User, typ, status string) error {
+	token, err := utils.GenerateMFAToken(typ, user.ID, user.Username, user.RoleName, user.RoleID, user.TokenVersion)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to generate tokens"})
+	}
+	return c.JSON(fiber.Map{
+		"status": status,
+		"data": fiber.Map{
+			"mfa_token":  token,
+			"expires_in": int(utils.MFATokenTTL.Seconds()),
+		},
+	})
+}
+
+// Cek kode TOTP atau recovery code. Kode TOTP yang sama tidak bisa dipakai dua kali
+func (s *AuthService) verifySecondFactor(userID string, state *postgres.TOTPState, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || state.Secret == "" {
+		return false, nil
+	}
+
+	if step, ok := utils.VerifyTOTP(state.Secret, code, time.Now()); ok {
+		return s.MFARepo.MarkTOTPStepUsed(userID, step)
+	}
+	if !state.Enabled {
+		return false, nil
+	}
+	return s.MFARepo.UseRecoveryCode(userID, utils.HashRecoveryCode(code))
+}
+
+// VerifyLogin2FA godoc
+// @Summary      Login Step 2 (2FA)
+// @Description  Tukar mfa_token dari /auth/login + kode TOTP (atau recovery code) dengan access token dan refresh token
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body postgres.MFALoginRequest true "MFA token & kode"
+// @Success      200  {object} postgres.LoginResponse
+// @Failure      400  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{}
+// @Failure      423  {object} map[string]interface{} "Akun terkunci sementara"
+// @Failure      429  {object} map[string]interface{} "Terlalu banyak percobaan dari IP ini"
+// @Router       /auth/login/2fa [post]
+func (s *AuthService) VerifyLogin2FA(c *fiber.Ctx) error {
+	var req postgres.MFALoginRequest
+	if err := c.BodyParser(&req); err != nil || req.MFAToken == "" || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"status": "fail", "message": "mfa_token and code are required"})
+	}
+
+	wait, err := s.ipBackoff(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to check login attempts"})
+	}
+	if wait > 0 {
+		s.audit(c, postgres.AuditLoginIPBlocked, nil, "", "2fa step")
+		return retryAfter(c, 429, "Too many failed login attempts, try again later", wait)
+	}
+
+	claims, err := utils.ParseToken(req.MFAToken)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid or expired MFA token"})
+	}
+	if typ, _ := claims["typ"].(string); typ != utils.TokenTypeMFA {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid token type"})
+	}
+	userID, _ := claims["user_id"].(string)
+	jti, _ := claims["jti"].(string)
+	version, _ := claims["ver"].(float64)
+
+	// MFA token sekali pakai & ikut tercabut bersama sesi user
+	session, err := s.TokenRepo.GetSessionState(userID, jti)
+	if err != nil || !session.IsActive || session.TokenRevoked || int(version) != session.TokenVersion {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid or expired MFA token"})
+	}
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid or expired MFA token"})
+	}
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		s.audit(c, postgres.AuditLoginLocked, user, user.Username, "2fa step")
+		return retryAfter(c, 423, "Account temporarily locked", time.Until(*user.LockedUntil))
+	}
+
+	state, err := s.MFARepo.GetTOTPState(userID)
+	if err != nil || !state.Enabled {
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Two-factor authentication is not enabled"})
+	}
+	ok, err := s.verifySecondFactor(userID, state, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to verify code"})
+	}
+	if !ok {
+		s.recordFailedLogin(c, user, user.Username, "invalid 2fa code")
+		return c.Status(401).JSON(fiber.Map{"status": "fail", "message": "Invalid verification code"})
+	}
+
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		if err := s.TokenRepo.RevokeToken(jti, userID, exp.Time); err != nil {
+			return c.Status(500).JSON(fiber.Map{"status": "error", "message": "Failed to revoke MFA token"})
+		}
+	}
+	return s.completeLogin(c, user)
+}
+
+// GetTOTPStatus godoc
+// @Summary      2FA Status
+// @Description  Status 2FA user yang sedang login (aktif / wajib oleh role / sisa recovery code)
+// @Tags         Auth 2FA
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: {enabled, required, recovery_codes_remaining}}"
+// @Failure      404  {object} map[string]interface{}
+// @Router       /auth/2fa [get]
+func (s *AuthService) GetTOTPStatus(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	state, err := s.MFARepo.GetTOTPState(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load 2FA status"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"enabled":                  state.Enabled,
+			"required":                 user.Require2FA,
+			"recovery_codes_remaining": state.RecoveryCodesRemaining,
+		},
+	})
+}
+
+// SetupTOTP godoc
+// @Summary      Setup 2FA
+// @Description  Membuat secret TOTP baru (belum aktif) beserta provisioning URI untuk QR code. Bisa dipanggil dengan access token atau mfa_token enrolment dari login.
+// @Tags         Auth 2FA
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: {secret, provisioning_uri}}"
+// @Failure      409  {object} map[string]interface{} "2FA sudah aktif"
+// @Router       /auth/2fa/setup [post]
+func (s *AuthService) SetupTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if user.TOTPEnabled {
+		return c.Status(409).JSON(fiber.Map{"error": "Two-factor authentication is already enabled"})
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate secret"})
+	}
+	if err := s.MFARepo.SetPendingTOTPSecret(userID, secret); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(409).JSON(fiber.Map{"error": "Two-factor authentication is already enabled"})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save secret"})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"secret":           secret,
+			"provisioning_uri": utils.TOTPProvisioningURI(totpIssuer(), user.Username, secret),
+		},
+	})
+}
+
+// EnableTOTP godoc
+// @Summary      Enable 2FA
+// @Description  Aktifkan 2FA dengan kode TOTP pertama dari aplikasi authenticator. Response berisi recovery code (hanya ditampilkan sekali). Jika dipanggil dengan mfa_token enrolment, response juga berisi access token & refresh token.
+// @Tags         Auth 2FA
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body postgres.TOTPCodeRequest true "Kode TOTP"
+// @Success      200  {object} map[string]interface{} "Format: {data: {recovery_codes, token?, refreshToken?}}"
+// @Failure      400  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{}
+// @Router       /auth/2fa/enable [post]
+func (s *AuthService) EnableTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req postgres.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "code is required"})
+	}
+
+	state, err := s.MFARepo.GetTOTPState(userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if state.Enabled {
+		return c.Status(409).JSON(fiber.Map{"error": "Two-factor authentication is already enabled"})
+	}
+	if state.Secret == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Call /auth/2fa/setup first"})
+	}
+	ok, err := s.verifySecondFactor(userID, state, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify code"})
+	}
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid verification code"})
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate recovery codes"})
+	}
+	if err := s.MFARepo.EnableTOTP(userID, hashes); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to enable 2FA"})
+	}
+	s.audit(c, postgres.AuditMFAEnabled, &postgres.User{ID: userID}, "", "")
+
+	data := fiber.Map{"recovery_codes": codes}
+
+	// Enrolment wajib saat login: token enrolment dicabut dan diganti token asli
+	if typ, _ := c.Locals("token_typ").(string); typ == utils.TokenTypeMFAEnroll {
+		jti, _ := c.Locals("jti").(string)
+		exp, ok := c.Locals("token_exp").(time.Time)
+		if !ok {
+			exp = time.Now().Add(utils.MFATokenTTL)
+		}
+		if err := s.TokenRepo.RevokeToken(jti, userID, exp); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to revoke enrolment token"})
+		}
+
+		user, err := s.UserRepo.GetUserByID(userID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "2FA enabled, please login again"})
+		}
+		if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+			if err := s.UserRepo.ResetFailedLogins(userID); err != nil {
+				log.Println("Failed to reset failed logins:", err)
+			}
+		}
+		t, rt, err := s.issueTokens(user, uuid.New().String())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "2FA enabled, please login again"})
+		}
+		data["token"] = t
+		data["refreshToken"] = rt
+	}
+
+	return c.JSON(fiber.Map{"message": "Two-factor authentication enabled", "data": data})
+}
+
+// DisableTOTP godoc
+// @Summary      Disable 2FA
+// @Description  Nonaktifkan 2FA (butuh password dan kode TOTP / recovery code). Tidak bisa jika role mewajibkan 2FA.
+// @Tags         Auth 2FA
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body postgres.DisableTOTPRequest true "Password & kode"
+// @Success      200  {object} map[string]interface{}
+// @Failure      401  {object} map[string]interface{}
+// @Failure      403  {object} map[string]interface{} "2FA wajib untuk role ini"
+// @Router       /auth/2fa/disable [post]
+func (s *AuthService) DisableTOTP(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req postgres.DisableTOTPRequest
+	if err := c.BodyParser(&req); err != nil || req.Password == "" || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "password and code are required"})
+	}
+
+	user, err := s.UserRepo.GetUserByID(userID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "User not found"})
+	}
+	if user.Require2FA {
+		return c.Status(403).JSON(fiber.Map{"error": "Two-factor authentication is mandatory for your role"})
+	}
+
+	hash, err := s.UserRepo.GetPasswordHash(userID)
+	if err != nil || !utils.CheckPassword(hash, req.Password) {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid password or code"})
+	}
+	state, err := s.MFARepo.GetTOTPState(userID)
+	if err != nil || !state.Enabled {
+		return c.Status(400).JSON(fiber.Map{"error": "Two-factor authentication is not enabled"})
+	}
+	ok, err := s.verifySecondFactor(userID, state, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify code"})
+	}
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid password or code"})
+	}
+
+	if err := s.MFARepo.DisableTOTP(userID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to disable 2FA"})
+	}
+	s.audit(c, postgres.AuditMFADisabled, user, user.Username, "")
+	return c.JSON(fiber.Map{"message": "Two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate Recovery Codes
+// @Description  Ganti semua recovery code lama dengan yang baru (butuh kode TOTP valid)
+// @Tags         Auth 2FA
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        request body postgres.TOTPCodeRequest true "Kode TOTP"
+// @Success      200  {object} map[string]interface{} "Format: {data: {recovery_codes}}"
+// @Failure      401  {object} map[string]interface{}
+// @Router       /auth/2fa/recovery-codes [post]
+func (s *AuthService) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+
+	var req postgres.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "code is required"})
+	}
+
+	state, err := s.MFARepo.GetTOTPState(userID)
+	if err != nil || !state.Enabled {
+		return c.Status(400).JSON(fiber.Map{"error": "Two-factor authentication is not enabled"})
+	}
+	step, ok := utils.VerifyTOTP(state.Secret, req.Code, time.Now())
+	if ok {
+		ok, err = s.MFARepo.MarkTOTPStepUsed(userID, step)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to verify code"})
+		}
+	}
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{"error": "Invalid verification code"})
+	}
+
+	codes, hashes, err := utils.GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate recovery codes"})
+	}
+	if err := s.MFARepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save recovery codes"})
+	}
+	return c.JSON(fiber.Map{"data": fiber.Map{"recovery_codes": codes}})
+}
@@ -0,0 +1,389 @@
+package service
+
+import (
+	"be_uas/app/mailer"
+	modelPG "be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Panjang cuplikan catatan / komentar di dalam pesan notifikasi
+const notificationSnippetLen = 140
+
+type NotificationService struct {
+	Repo repoPG.INotificationRepoPG
+}
+
+func NewNotificationService(repo repoPG.INotificationRepoPG) *NotificationService {
+	return &NotificationService{Repo: repo}
+}
+
+type notificationRecipient struct {
+	UserID        string
+	EventType     string
+	Advisor       bool // Penerima adalah dosen wali (teks pesan berbeda)
+	FormerAdvisor bool // advisor_changed: penerima adalah dosen wali lama
+}
+
+// Publish membuat notifikasi untuk event workflow. Best effort: kegagalan hanya dicatat di log
+// agar aksi utama (submit, verify, komentar, ...) tidak ikut gagal. Aman dipanggil pada nil
+func (s *NotificationService) Publish(ev modelPG.NotificationEvent) {
+	if s == nil {
+		return
+	}
+	if err := s.publish(ev); err != nil {
+		log.Printf("Failed to publish %s notification: %v\n", ev.Type, err)
+	}
+}
+
+func (s *NotificationService) publish(ev modelPG.NotificationEvent) error {
+	var p *modelPG.NotificationParticipants
+	var err error
+	if ev.AchievementRefID != "" {
+		p, err = s.Repo.GetAchievementParticipants(ev.AchievementRefID)
+	} else {
+		p, err = s.Repo.GetStudentParticipants(ev.StudentID)
+	}
+	if err != nil {
+		return err
+	}
+
+	recipients := notificationRecipients(ev, p)
+	if len(recipients) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(notificationData(ev))
+	var refID, actorID *string
+	if ev.AchievementRefID != "" {
+		refID = &ev.AchievementRefID
+	}
+	if ev.ActorID != "" {
+		actorID = &ev.ActorID
+	}
+	ns := make([]modelPG.Notification, 0, len(recipients))
+	for _, r := range recipients {
+		title, message := notificationText(r, ev.Note)
+		ns = append(ns, modelPG.Notification{
+			UserID: r.UserID, EventType: r.EventType, Title: title, Message: message,
+			AchievementRefID: refID, ActorID: actorID, Data: data,
+		})
+	}
+	return s.Repo.CreateNotifications(ns)
+}
+
+// Tentukan penerima per jenis event; pelaku tidak menerima notifikasi atas aksinya sendiri.
+// Satu user hanya menerima satu notifikasi per event (mention lebih diutamakan)
+func notificationRecipients(ev modelPG.NotificationEvent, p *modelPG.NotificationParticipants) []notificationRecipient {
+	var list []notificationRecipient
+	seen := map[string]bool{ev.ActorID: true, "": true}
+	add := func(userID, eventType string, advisor bool) {
+		if !seen[userID] {
+			seen[userID] = true
+			list = append(list, notificationRecipient{UserID: userID, EventType: eventType, Advisor: advisor})
+		}
+	}
+
+	switch ev.Type {
+	case modelPG.NotifAchievementSubmitted:
+		add(p.AdvisorUserID, ev.Type, true)
+	case modelPG.NotifAchievementVerified, modelPG.NotifAchievementRejected, modelPG.NotifAchievementNeedsRevision:
+		add(p.StudentUserID, ev.Type, false)
+	case modelPG.NotifCommentCreated:
+		for _, id := range ev.MentionedUserIDs {
+			add(id, modelPG.NotifCommentMention, id == p.AdvisorUserID)
+		}
+		add(p.StudentUserID, ev.Type, false)
+		add(p.AdvisorUserID, ev.Type, true)
+	case modelPG.NotifAdvisorChanged:
+		add(p.StudentUserID, ev.Type, false)
+		add(p.AdvisorUserID, ev.Type, true)
+		if !seen[ev.PreviousAdvisorUserID] {
+			seen[ev.PreviousAdvisorUserID] = true
+			list = append(list, notificationRecipient{UserID: ev.PreviousAdvisorUserID, EventType: ev.Type, Advisor: true, FormerAdvisor: true})
+		}
+	}
+	return list
+}
+
+func notificationData(ev modelPG.NotificationEvent) map[string]string {
+	data := map[string]string{}
+	if ev.StudentID != "" {
+		data["student_id"] = ev.StudentID
+	}
+	if ev.CommentID != "" {
+		data["comment_id"] = ev.CommentID
+	}
+	if ev.Note != "" {
+		data["note"] = ev.Note
+	}
+	return data
+}
+
+func notificationText(r notificationRecipient, note string) (string, string) {
+	snippet := truncateRunes(note, notificationSnippetLen)
+	withNote := func(msg string) string {
+		if snippet == "" {
+			return msg
+		}
+		return fmt.Sprintf("%s Note: %s", msg, snippet)
+	}
+
+	switch r.EventType {
+	case modelPG.NotifAchievementSubmitted:
+		return "Achievement submitted", "An advisee submitted an achievement for your verification."
+	case modelPG.NotifAchievementVerified:
+		return "Achievement verified", "Your achievement has been verified."
+	case modelPG.NotifAchievementRejected:
+		return "Achievement rejected", withNote("Your achievement was rejected.")
+	case modelPG.NotifAchievementNeedsRevision:
+		return "Revision requested", withNote("Your advisor requested changes to your achievement.")
+	case modelPG.NotifCommentMention:
+		return "You were mentioned", "You were mentioned in a comment: " + snippet
+	case modelPG.NotifCommentCreated:
+		return "New comment", "New comment on an achievement: " + snippet
+	case modelPG.NotifAdvisorChanged:
+		if r.FormerAdvisor {
+			return "Advisee reassigned", "A student you advised has been assigned to another advisor."
+		}
+		if r.Advisor {
+			return "New advisee assigned", "A student has been assigned to you as an advisee."
+		}
+		return "Academic advisor changed", "Your academic advisor has been updated."
+	}
+	return r.EventType, snippet
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
+
+// GetNotifications godoc
+// @Summary      Get My Notifications
+// @Description  Notifikasi milik user yang sedang login (terbaru dulu) beserta jumlah yang belum dibaca
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Param        unread  query     bool  false  "Hanya yang belum dibaca"
+// @Param        page    query     int   false  "Page Number" default(1)
+// @Param        limit   query     int   false  "Items per Page" default(20)
+// @Success      200  {object} map[string]interface{} "Format: {data: [Notification], meta: {page, limit, total_data, total_page, unread_count}}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /notifications [get]
+func (s *NotificationService) GetNotifications(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	list, total, err := s.Repo.ListNotifications(userID, c.QueryBool("unread"), limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch notifications"})
+	}
+	unread, err := s.Repo.CountUnread(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch notifications"})
+	}
+	if list == nil {
+		list = []modelPG.Notification{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": list,
+		"meta": fiber.Map{
+			"page":         page,
+			"limit":        limit,
+			"total_data":   total,
+			"total_page":   (total + limit - 1) / limit,
+			"unread_count": unread,
+		},
+	})
+}
+
+// GetUnreadCount godoc
+// @Summary      Get Unread Notification Count
+// @Description  Jumlah notifikasi yang belum dibaca (untuk badge)
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {unread_count}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /notifications/unread-count [get]
+func (s *NotificationService) GetUnreadCount(c *fiber.Ctx) error {
+	unread, err := s.Repo.CountUnread(c.Locals("user_id").(string))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to count notifications"})
+	}
+	return c.JSON(fiber.Map{"unread_count": unread})
+}
+
+// MarkNotificationsRead godoc
+// @Summary      Mark Notifications as Read
+// @Description  Tandai notifikasi tertentu sebagai sudah dibaca (ID milik user lain diabaikan)
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      postgres.MarkNotificationsRequest  true  "Payload: { ids: [...] }"
+// @Success      200   {object}  map[string]interface{} "Format: {updated, unread_count}"
+// @Failure      400   {object}  map[string]interface{}
+// @Router       /notifications/read [post]
+func (s *NotificationService) MarkNotificationsRead(c *fiber.Ctx) error {
+	var req modelPG.MarkNotificationsRequest
+	if err := c.BodyParser(&req); err != nil || len(req.IDs) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "ids is required"})
+	}
+	if len(req.IDs) > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "At most 100 ids per request"})
+	}
+
+	userID := c.Locals("user_id").(string)
+	updated, err := s.Repo.MarkRead(userID, req.IDs)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update notifications"})
+	}
+	return s.readResponse(c, userID, updated)
+}
+
+// MarkAllNotificationsRead godoc
+// @Summary      Mark All Notifications as Read
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200   {object}  map[string]interface{} "Format: {updated, unread_count}"
+// @Failure      500   {object}  map[string]interface{}
+// @Router       /notifications/read-all [post]
+func (s *NotificationService) MarkAllNotificationsRead(c *fiber.Ctx) error {
+	userID := c.Locals("user_id").(string)
+	updated, err := s.Repo.MarkAllRead(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update notifications"})
+	}
+	return s.readResponse(c, userID, updated)
+}
+
+func (s *NotificationService) readResponse(c *fiber.Ctx, userID string, updated int64) error {
+	unread, err := s.Repo.CountUnread(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to count notifications"})
+	}
+	return c.JSON(fiber.Map{"updated": updated, "unread_count": unread})
+}
+
+// GetNotificationPreferences godoc
+// @Summary      Get Notification Preferences
+// @Description  Jenis event yang diterima user (in-app & email) beserta bahasa email dan mode ringkasan harian. Jenis yang belum pernah diatur aktif secara default
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: [{event_type, in_app, email}], settings: {locale, email_digest}}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /notifications/preferences [get]
+func (s *NotificationService) GetNotificationPreferences(c *fiber.Ctx) error {
+	return s.preferencesResponse(c, c.Locals("user_id").(string))
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary      Update Notification Preferences
+// @Description  Aktifkan / nonaktifkan jenis event per kanal (preferences = in-app, email = email), ubah bahasa email (id / en), atau mode ringkasan harian (email_digest, khusus Dosen Wali: pengajuan baru dirangkum sekali sehari). Yang tidak disebut tidak berubah
+// @Tags         Notifications
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      postgres.NotificationPreferencesRequest  true  "Payload: { preferences: { comment_created: false }, email: { achievement_verified: false }, locale: 'en', email_digest: true }"
+// @Success      200   {object}  map[string]interface{} "Format: {data: [{event_type, in_app, email}], settings: {locale, email_digest}}"
+// @Failure      400   {object}  map[string]interface{} "Error: Jenis event / bahasa tidak dikenal"
+// @Router       /notifications/preferences [put]
+func (s *NotificationService) UpdateNotificationPreferences(c *fiber.Ctx) error {
+	var req modelPG.NotificationPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if len(req.Preferences) == 0 && len(req.Email) == 0 && req.Locale == "" && req.EmailDigest == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "preferences, email, locale, or email_digest is required"})
+	}
+	if t := unknownEventType(req.Preferences, modelPG.NotificationEventTypes); t != "" {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Unknown event type '%s'", t), "event_types": modelPG.NotificationEventTypes})
+	}
+	if t := unknownEventType(req.Email, modelPG.EmailEventTypes); t != "" {
+		return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Event type '%s' has no email", t), "event_types": modelPG.EmailEventTypes})
+	}
+	var locale *string
+	if req.Locale != "" {
+		if !slices.Contains(mailer.Locales, req.Locale) {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("Unsupported locale '%s'", req.Locale), "locales": mailer.Locales})
+		}
+		locale = &req.Locale
+	}
+
+	userID := c.Locals("user_id").(string)
+	if len(req.Preferences) > 0 || len(req.Email) > 0 {
+		if err := s.Repo.SetPreferences(userID, req.Preferences, req.Email); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to update preferences"})
+		}
+	}
+	if locale != nil || req.EmailDigest != nil {
+		if err := s.Repo.SetSettings(userID, locale, req.EmailDigest); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to update preferences"})
+		}
+	}
+	return s.preferencesResponse(c, userID)
+}
+
+func unknownEventType(prefs map[string]bool, known []string) string {
+	for eventType := range prefs {
+		if !slices.Contains(known, eventType) {
+			return eventType
+		}
+	}
+	return ""
+}
+
+func (s *NotificationService) preferencesResponse(c *fiber.Ctx, userID string) error {
+	prefs, err := s.preferences(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch preferences"})
+	}
+	settings, err := s.Repo.GetSettings(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch preferences"})
+	}
+	return c.JSON(fiber.Map{"data": prefs, "settings": settings})
+}
+
+// Preferensi lengkap untuk semua jenis event (default aktif); email hanya untuk jenis yang punya email
+func (s *NotificationService) preferences(userID string) ([]modelPG.NotificationPreference, error) {
+	stored, err := s.Repo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	byType := map[string]modelPG.NotificationPreference{}
+	for _, p := range stored {
+		byType[p.EventType] = p
+	}
+	prefs := make([]modelPG.NotificationPreference, 0, len(modelPG.NotificationEventTypes))
+	for _, t := range modelPG.NotificationEventTypes {
+		p, ok := byType[t]
+		pref := modelPG.NotificationPreference{EventType: t, InApp: p.InApp || !ok}
+		if slices.Contains(modelPG.EmailEventTypes, t) {
+			email := !ok || p.Email == nil || *p.Email
+			pref.Email = &email
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
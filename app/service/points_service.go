@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"be_uas/app/listing"
+	modelMongo "be_uas/app/model/mongodb"
+	modelPG "be_uas/app/model/postgres"
+	repoMongo "be_uas/app/repository/mongodb"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/scoring"
+	"be_uas/app/workflow"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Jumlah dokumen MongoDB per batch saat hitung ulang poin
+const RecomputeBatchSize = 500
+
+type PointsService struct {
+	Rules     repoPG.IPointRuleRepoPG
+	Types     repoPG.IAchievementTypeRepoPG
+	RepoPG    repoPG.IAchievementRepoPG
+	RepoMongo repoMongo.IAchievementRepoMongo
+
+	recomputeMu sync.Mutex // Hitung ulang tidak berjalan paralel
+}
+
+func NewPointsService(rules repoPG.IPointRuleRepoPG, types repoPG.IAchievementTypeRepoPG, pg repoPG.IAchievementRepoPG, mongo repoMongo.IAchievementRepoMongo) *PointsService {
+	return &PointsService{Rules: rules, Types: types, RepoPG: pg, RepoMongo: mongo}
+}
+
+// Hasil satu kali hitung ulang poin
+type RecomputeReport struct {
+	RuleVersion int      `json:"rule_version"`
+	Checked     int      `json:"checked"`
+	Updated     int      `json:"updated"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// Aturan aktif beserta versinya; tanpa aturan aktif semua jenis memakai poin default (versi 0)
+func activeRuleSet(repo repoPG.IPointRuleRepoPG) (*scoring.RuleSet, int, error) {
+	rs, err := repo.GetActiveRuleSet()
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	parsed, err := scoring.Parse(rs.Rules)
+	if err != nil {
+		return nil, 0, err
+	}
+	return parsed, rs.Version, nil
+}
+
+// Poin default jenis; jenis yang sudah tidak ada di katalog memakai poin tersimpan
+func typeDefaultPoints(types repoPG.IAchievementTypeRepoPG, doc *modelMongo.Achievement) (int, error) {
+	t, err := types.GetTypeByCode(doc.AchievementType)
+	if errors.Is(err, sql.ErrNoRows) {
+		return doc.Points, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return t.DefaultPoints, nil
+}
+
+// Hitung ulang poin prestasi yang belum diverifikasi dengan aturan aktif.
+// Prestasi verified tidak disentuh karena poinnya sudah dibekukan
+func (s *PointsService) Recompute(ctx context.Context) (RecomputeReport, error) {
+	s.recomputeMu.Lock()
+	defer s.recomputeMu.Unlock()
+
+	var report RecomputeReport
+	rules, version, err := activeRuleSet(s.Rules)
+	if err != nil {
+		return report, err
+	}
+	report.RuleVersion = version
+
+	refs, err := s.RepoPG.FindReferencesByFilter(listing.Filter{
+		Statuses: []string{workflow.StatusDraft, workflow.StatusSubmitted, workflow.StatusRejected, workflow.StatusNeedsRevision},
+	})
+	if err != nil {
+		return report, err
+	}
+
+	defaults := map[string]int{}
+	for start := 0; start < len(refs); start += RecomputeBatchSize {
+		end := start + RecomputeBatchSize
+		if end > len(refs) {
+			end = len(refs)
+		}
+		ids := make([]string, 0, end-start)
+		for _, ref := range refs[start:end] {
+			ids = append(ids, ref.MongoAchievementID)
+		}
+
+		docs, err := s.RepoMongo.FindAchievementsByIDs(ctx, ids)
+		if err != nil {
+			return report, err
+		}
+		for _, id := range ids {
+			doc, ok := docs[id]
+			if !ok {
+				continue // Dangling reference: urusan job rekonsiliasi
+			}
+			report.Checked++
+
+			defaultPoints, cached := defaults[doc.AchievementType]
+			if !cached {
+				if defaultPoints, err = typeDefaultPoints(s.Types, doc); err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", id, err))
+					continue
+				}
+				defaults[doc.AchievementType] = defaultPoints
+			}
+
+			points := rules.Compute(doc.AchievementType, doc.Details, defaultPoints).Points
+			if points == doc.Points && version == doc.PointsRuleVersion {
+				continue
+			}
+			if err := s.RepoMongo.SetPoints(ctx, id, points, version); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", id, err))
+				continue
+			}
+			report.Updated++
+		}
+	}
+	return report, nil
+}
+
+func (s *PointsService) recomputeInBackground() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		report, err := s.Recompute(ctx)
+		if err != nil {
+			log.Println("Failed to recompute achievement points:", err)
+			return
+		}
+		log.Printf("Recomputed achievement points with rule v%d: %d checked, %d updated, %d errors\n",
+			report.RuleVersion, report.Checked, report.Updated, len(report.Errors))
+	}()
+}
+
+// ListPointRuleSets godoc
+// @Summary      Get Point Rule Sets
+// @Description  Daftar semua versi aturan poin (terbaru dulu), termasuk yang sedang aktif
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: [PointRuleSet]}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /point-rules [get]
+func (s *PointsService) ListPointRuleSets(c *fiber.Ctx) error {
+	sets, err := s.Rules.GetRuleSets()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch point rules"})
+	}
+	if sets == nil {
+		sets = []modelPG.PointRuleSet{}
+	}
+	return c.JSON(fiber.Map{"data": sets})
+}
+
+// GetPointRuleSet godoc
+// @Summary      Get Point Rule Set
+// @Description  Detail satu versi aturan poin. Gunakan version=active untuk aturan yang sedang berlaku
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        version  path  string  true  "Versi aturan (angka) atau active"
+// @Success      200  {object} postgres.PointRuleSet
+// @Failure      404  {object} map[string]interface{}
+// @Router       /point-rules/{version} [get]
+func (s *PointsService) GetPointRuleSet(c *fiber.Ctx) error {
+	var rs *modelPG.PointRuleSet
+	var err error
+	if c.Params("version") == "active" {
+		rs, err = s.Rules.GetActiveRuleSet()
+	} else {
+		version, convErr := strconv.Atoi(c.Params("version"))
+		if convErr != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid version"})
+		}
+		rs, err = s.Rules.GetRuleSetByVersion(version)
+	}
+	if err != nil {
+		return notFoundOr500(c, err, "Point rule set not found", "Failed to fetch point rules")
+	}
+	return c.JSON(fiber.Map{"data": rs})
+}
+
+// CreatePointRuleSet godoc
+// @Summary      Create Point Rule Set
+// @Description  Menyimpan versi aturan poin baru (nonaktif). Format rules: {types: {kode_jenis: {base, components: [{field, values, ranges, default}], multipliers: [...], min, max}}}. Poin = (base + jumlah komponen) x hasil kali multiplier
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body      postgres.PointRuleSet true "Payload: { description, rules }"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      422  {object}  map[string]interface{} "Error: Rules tidak valid"
+// @Router       /point-rules [post]
+func (s *PointsService) CreatePointRuleSet(c *fiber.Ctx) error {
+	var req modelPG.PointRuleSet
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid Input"})
+	}
+	if len(req.Rules) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "rules is required"})
+	}
+	if _, err := scoring.Parse(req.Rules); err != nil {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(string)
+	req.CreatedBy = &userID
+	version, err := s.Rules.CreateRuleSet(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create point rules"})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Point rule set created", "version": version})
+}
+
+// ActivatePointRuleSet godoc
+// @Summary      Activate Point Rule Set
+// @Description  Menjadikan versi ini aturan yang berlaku, lalu menghitung ulang poin prestasi yang belum diverifikasi di background. Poin prestasi verified tetap (sudah dibekukan)
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Param        version  path  int  true  "Versi aturan"
+// @Success      202  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Router       /point-rules/{version}/activate [post]
+func (s *PointsService) ActivatePointRuleSet(c *fiber.Ctx) error {
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid version"})
+	}
+	if err := s.Rules.ActivateRuleSet(version); err != nil {
+		return notFoundOr500(c, err, "Point rule set not found", "Failed to activate point rules")
+	}
+
+	s.recomputeInBackground()
+	return c.Status(202).JSON(fiber.Map{"message": "Point rule set activated, recomputation started", "version": version})
+}
+
+// RecomputePoints godoc
+// @Summary      Recompute Points
+// @Description  Menghitung ulang poin semua prestasi yang belum diverifikasi dengan aturan aktif (sinkron)
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: {rule_version, checked, updated, errors}}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /point-rules/recompute [post]
+func (s *PointsService) RecomputePoints(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	report, err := s.Recompute(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to recompute points"})
+	}
+	return c.JSON(fiber.Map{"data": report})
+}
+
+// PreviewPoints godoc
+// @Summary      Preview Points
+// @Description  Simulasi perhitungan poin untuk jenis & details tertentu beserta rinciannya. version=0 memakai aturan aktif
+// @Tags         Point Rules
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body      postgres.PreviewPointsRequest true "Payload: { achievementType, details, version }"
+// @Success      200  {object}  map[string]interface{} "Format: {data: {points, rule, breakdown: [{kind, field, input, value}], version}}"
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /point-rules/preview [post]
+func (s *PointsService) PreviewPoints(c *fiber.Ctx) error {
+	var req modelPG.PreviewPointsRequest
+	if err := c.BodyParser(&req); err != nil || req.AchievementType == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "achievementType is required"})
+	}
+
+	t, err := s.Types.GetTypeByCode(req.AchievementType)
+	if err != nil {
+		return notFoundOr500(c, err, "Achievement type not found", "Failed to compute points")
+	}
+
+	var rules *scoring.RuleSet
+	version := req.Version
+	if version == 0 {
+		rules, version, err = activeRuleSet(s.Rules)
+	} else {
+		var rs *modelPG.PointRuleSet
+		if rs, err = s.Rules.GetRuleSetByVersion(version); err == nil {
+			rules, err = scoring.Parse(rs.Rules)
+		}
+	}
+	if err != nil {
+		return notFoundOr500(c, err, "Point rule set not found", "Failed to compute points")
+	}
+
+	result := rules.Compute(req.AchievementType, req.Details, t.DefaultPoints)
+	return c.JSON(fiber.Map{"data": fiber.Map{
+		"points":    result.Points,
+		"rule":      result.Rule,
+		"breakdown": result.Breakdown,
+		"version":   version,
+	}})
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	modelMongo "be_uas/app/model/mongodb"
+	"be_uas/app/preview"
+	repoMongo "be_uas/app/repository/mongodb"
+	"be_uas/app/storage"
+)
+
+const PreviewBatchSize = 10
+
+// Hasil satu putaran pembuatan preview
+type PreviewReport struct {
+	Generated   int      `json:"generated"`
+	Unsupported int      `json:"unsupported"`
+	Failed      int      `json:"failed"`
+	Errors      []string `json:"errors"` // Tetap pending, dicoba lagi putaran berikutnya
+}
+
+type PreviewService struct {
+	RepoMongo repoMongo.IAchievementRepoMongo
+	Files     storage.Storage
+	Generator *preview.Generator
+}
+
+func NewPreviewService(mongo repoMongo.IAchievementRepoMongo, files storage.Storage, gen *preview.Generator) *PreviewService {
+	return &PreviewService{RepoMongo: mongo, Files: files, Generator: gen}
+}
+
+// Buat thumbnail / preview lampiran baru secara berkala
+func StartPreviewWorker(s *PreviewService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			report, err := s.GeneratePending(ctx)
+			if err != nil {
+				log.Println("Failed to load pending attachment previews:", err)
+			} else if len(report.Errors) > 0 {
+				log.Printf("Attachment previews: %d generated, %d errors\n", report.Generated, len(report.Errors))
+			}
+			cancel()
+		}
+	}()
+}
+
+// Key rendisi disimpan di samping file asli: <id>.thumb.jpg & <id>.preview.jpg
+func renditionKeys(storageKey string) (thumb, full string) {
+	base := strings.TrimSuffix(storageKey, path.Ext(storageKey))
+	return base + ".thumb.jpg", base + ".preview.jpg"
+}
+
+func (s *PreviewService) GeneratePending(ctx context.Context) (PreviewReport, error) {
+	report := PreviewReport{Errors: []string{}}
+	pending, err := s.RepoMongo.FindPendingPreviews(ctx, PreviewBatchSize)
+	if err != nil {
+		return report, err
+	}
+
+	for _, p := range pending {
+		status, err := s.generate(ctx, p.AchievementID.Hex(), p.Attachment)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("attachment %s: %v", p.Attachment.ID, err))
+			continue
+		}
+		switch status {
+		case modelMongo.PreviewReady:
+			report.Generated++
+		case modelMongo.PreviewUnsupported:
+			report.Unsupported++
+		case modelMongo.PreviewFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+func (s *PreviewService) generate(ctx context.Context, hexID string, att modelMongo.Attachment) (string, error) {
+	file, err := s.Files.Open(ctx, att.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(io.LimitReader(file, storage.MaxUploadSize+1))
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	var result modelMongo.AttachmentPreview
+	rendered, err := s.Generator.Render(ctx, content, att.FileType)
+	switch {
+	case errors.Is(err, preview.ErrUnsupported):
+		result.Status = modelMongo.PreviewUnsupported
+	case errors.Is(err, preview.ErrInvalid):
+		log.Printf("Cannot render preview of attachment %s: %v\n", att.ID, err)
+		result.Status = modelMongo.PreviewFailed
+	case err != nil:
+		return "", err
+	default:
+		thumbKey, fullKey := renditionKeys(att.StorageKey)
+		if err := s.Files.Put(ctx, thumbKey, bytes.NewReader(rendered.Thumbnail), int64(len(rendered.Thumbnail)), "image/jpeg"); err != nil {
+			return "", err
+		}
+		if err := s.Files.Put(ctx, fullKey, bytes.NewReader(rendered.Preview), int64(len(rendered.Preview)), "image/jpeg"); err != nil {
+			return "", err
+		}
+		result = modelMongo.AttachmentPreview{
+			Status:       modelMongo.PreviewReady,
+			ThumbnailURL: att.FileURL + "/thumbnail",
+			PreviewURL:   att.FileURL + "/preview",
+			ThumbnailKey: thumbKey,
+			PreviewKey:   fullKey,
+		}
+	}
+
+	err = s.RepoMongo.SetPreview(ctx, hexID, att.ID, result)
+	if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+		// Lampiran dihapus / diganti selama render: rendisi tidak dipakai siapa pun
+		for _, key := range []string{result.ThumbnailKey, result.PreviewKey} {
+			if key != "" {
+				s.Files.Delete(ctx, key)
+			}
+		}
+		return result.Status, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return result.Status, nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/middleware"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RBACService struct {
+	Repo repoPG.IRoleRepoPG
+}
+
+func NewRBACService(repo repoPG.IRoleRepoPG) *RBACService {
+	return &RBACService{Repo: repo}
+}
+
+// ListRoles godoc
+// @Summary      Get All Roles
+// @Description  Melihat daftar role beserta permission masing-masing
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: [Role]}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /roles [get]
+func (s *RBACService) ListRoles(c *fiber.Ctx) error {
+	roles, err := s.Repo.GetAllRoles()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch roles"})
+	}
+	if roles == nil {
+		roles = []postgres.Role{}
+	}
+	return c.JSON(fiber.Map{"data": roles})
+}
+
+// CreateRole godoc
+// @Summary      Create Role
+// @Description  Membuat role baru (permission di-assign terpisah)
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body      map[string]string true "Payload: { 'name': 'Kaprodi', 'description': '...' }"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /roles [post]
+func (s *RBACService) CreateRole(c *fiber.Ctx) error {
+	var req postgres.Role
+	if err := c.BodyParser(&req); err != nil || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Role name is required"})
+	}
+
+	id, err := s.Repo.CreateRole(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create role"})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Role created", "id": id})
+}
+
+// UpdateRole godoc
+// @Summary      Update Role
+// @Description  Mengubah nama, deskripsi, atau policy wajib 2FA (require_2fa) role. require_2fa yang tidak dikirim tidak diubah
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string                      true "Role ID"
+// @Param        body body      postgres.UpdateRoleRequest  true "Payload: { 'name': '...', 'description': '...', 'require_2fa': false }"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /roles/{id} [put]
+func (s *RBACService) UpdateRole(c *fiber.Ctx) error {
+	var req postgres.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil || req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Role name is required"})
+	}
+
+	if err := s.Repo.UpdateRole(c.Params("id"), req); err != nil {
+		return notFoundOr500(c, err, "Role not found", "Failed to update role")
+	}
+	return c.JSON(fiber.Map{"message": "Role updated"})
+}
+
+// DeleteRole godoc
+// @Summary      Delete Role
+// @Description  Menghapus role beserta assignment permission-nya (gagal jika masih dipakai user)
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Role ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Failure      409  {object} map[string]interface{}
+// @Router       /roles/{id} [delete]
+func (s *RBACService) DeleteRole(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if err := s.Repo.DeleteRole(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.Status(404).JSON(fiber.Map{"error": "Role not found"})
+		}
+		return c.Status(409).JSON(fiber.Map{"error": "Failed to delete role, it may still be assigned to users"})
+	}
+
+	middleware.InvalidatePermissions(id)
+	return c.JSON(fiber.Map{"message": "Role deleted"})
+}
+
+// ListPermissions godoc
+// @Summary      Get All Permissions
+// @Description  Melihat katalog permission yang tersedia
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} map[string]interface{} "Format: {data: [Permission]}"
+// @Failure      500  {object} map[string]interface{}
+// @Router       /permissions [get]
+func (s *RBACService) ListPermissions(c *fiber.Ctx) error {
+	perms, err := s.Repo.GetAllPermissions()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch permissions"})
+	}
+	if perms == nil {
+		perms = []postgres.Permission{}
+	}
+	return c.JSON(fiber.Map{"data": perms})
+}
+
+// CreatePermission godoc
+// @Summary      Create Permission
+// @Description  Menambah permission baru dengan format resource:action
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body      postgres.Permission true "Permission Data"
+// @Success      201  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /permissions [post]
+func (s *RBACService) CreatePermission(c *fiber.Ctx) error {
+	var req postgres.Permission
+	if err := c.BodyParser(&req); err != nil || req.Resource == "" || req.Action == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Resource and action are required"})
+	}
+	req.Name = req.Resource + ":" + req.Action
+
+	id, err := s.Repo.CreatePermission(req)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to create permission"})
+	}
+	return c.Status(201).JSON(fiber.Map{"message": "Permission created", "id": id, "name": req.Name})
+}
+
+// DeletePermission godoc
+// @Summary      Delete Permission
+// @Description  Menghapus permission dan mencabutnya dari semua role
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Permission ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Router       /permissions/{id} [delete]
+func (s *RBACService) DeletePermission(c *fiber.Ctx) error {
+	if err := s.Repo.DeletePermission(c.Params("id")); err != nil {
+		return notFoundOr500(c, err, "Permission not found", "Failed to delete permission")
+	}
+
+	// Permission bisa dipakai banyak role, reset seluruh cache
+	middleware.InvalidatePermissions("")
+	return c.JSON(fiber.Map{"message": "Permission deleted"})
+}
+
+// AssignPermission godoc
+// @Summary      Assign Permission to Role
+// @Description  Menambahkan permission ke role
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string            true "Role ID"
+// @Param        body body      map[string]string true "Payload: { 'permission_id': 'UUID' }"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /roles/{id}/permissions [post]
+func (s *RBACService) AssignPermission(c *fiber.Ctx) error {
+	type AssignReq struct {
+		PermissionID string `json:"permission_id"`
+	}
+	var req AssignReq
+	if err := c.BodyParser(&req); err != nil || req.PermissionID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "permission_id is required"})
+	}
+
+	roleID := c.Params("id")
+	if err := s.Repo.AssignPermission(roleID, req.PermissionID); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to assign permission"})
+	}
+
+	middleware.InvalidatePermissions(roleID)
+	return c.JSON(fiber.Map{"message": "Permission assigned"})
+}
+
+// RevokePermission godoc
+// @Summary      Revoke Permission from Role
+// @Description  Mencabut permission dari role
+// @Tags         RBAC (Admin)
+// @Security     BearerAuth
+// @Param        id             path  string  true  "Role ID"
+// @Param        permissionId   path  string  true  "Permission ID"
+// @Produce      json
+// @Success      200  {object} map[string]interface{}
+// @Failure      404  {object} map[string]interface{}
+// @Router       /roles/{id}/permissions/{permissionId} [delete]
+func (s *RBACService) RevokePermission(c *fiber.Ctx) error {
+	roleID := c.Params("id")
+	if err := s.Repo.RevokePermission(roleID, c.Params("permissionId")); err != nil {
+		return notFoundOr500(c, err, "Permission is not assigned to this role", "Failed to revoke permission")
+	}
+
+	middleware.InvalidatePermissions(roleID)
+	return c.JSON(fiber.Map{"message": "Permission revoked"})
+}
+
+// Helper: sql.ErrNoRows -> 404, selain itu 500
+func notFoundOr500(c *fiber.Ctx, err error, notFound, fallback string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return c.Status(404).JSON(fiber.Map{"error": notFound})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": fallback})
+}
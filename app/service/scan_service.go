@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	modelMongo "be_uas/app/model/mongodb"
+	repoMongo "be_uas/app/repository/mongodb"
+	"be_uas/app/scanner"
+	"be_uas/app/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Pengaturan worker scan lampiran
+const (
+	ScanBatchSize    = 20
+	QuarantinePrefix = "quarantine/" // File terinfeksi dipindah ke sini (tidak pernah di-GC otomatis)
+	MaxScanAttempts  = 6             // Setelah itu lampiran ditandai ScanFailed
+	ScanRetryBase    = time.Minute
+	ScanRetryMax     = 6 * time.Hour
+)
+
+// Hasil satu putaran scan
+type ScanReport struct {
+	Scanned  int      `json:"scanned"`
+	Clean    int      `json:"clean"`
+	Infected int      `json:"infected"`
+	Failed   int      `json:"failed"` // Gagal permanen (file hilang / batas retry), ditandai ScanFailed
+	Errors   []string `json:"errors"` // Yang belum permanen tetap pending dan dicoba lagi dengan backoff
+}
+
+type ScanService struct {
+	RepoMongo repoMongo.IAchievementRepoMongo
+	Files     storage.Storage
+	Scanner   scanner.Scanner
+}
+
+func NewScanService(mongo repoMongo.IAchievementRepoMongo, files storage.Storage, sc scanner.Scanner) *ScanService {
+	return &ScanService{RepoMongo: mongo, Files: files, Scanner: sc}
+}
+
+// Scan lampiran pending secara berkala
+func StartScanWorker(s *ScanService, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			report, err := s.ScanPending(ctx)
+			if err != nil {
+				log.Println("Failed to load pending attachment scans:", err)
+			} else if report.Infected > 0 || len(report.Errors) > 0 {
+				log.Printf("Attachment scan: %d infected, %d errors\n", report.Infected, len(report.Errors))
+			}
+			cancel()
+		}
+	}()
+}
+
+// ScanPending memindai satu batch lampiran pending
+func (s *ScanService) ScanPending(ctx context.Context) (ScanReport, error) {
+	report := ScanReport{Errors: []string{}}
+	pending, err := s.RepoMongo.FindPendingScans(ctx, ScanBatchSize)
+	if err != nil {
+		return report, err
+	}
+
+	for _, p := range pending {
+		status, err := s.scanAttachment(ctx, p.AchievementID.Hex(), p.Attachment)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("attachment %s: %v", p.Attachment.ID, err))
+			if s.recordFailure(ctx, p, err, &report) {
+				report.Failed++
+			}
+			continue
+		}
+		report.Scanned++
+		if status == modelMongo.ScanInfected {
+			report.Infected++
+		} else {
+			report.Clean++
+		}
+	}
+	return report, nil
+}
+
+var errNoStorageKey = errors.New("attachment has no storage key")
+
+// Jadwalkan ulang lampiran yang gagal discan agar tidak terus menempati batch; file yang hilang
+// atau sudah mencapai MaxScanAttempts ditandai ScanFailed. true jika ditandai gagal permanen
+func (s *ScanService) recordFailure(ctx context.Context, p modelMongo.PendingAttachment, scanErr error, report *ScanReport) bool {
+	attempts := p.Attachment.ScanAttempts + 1
+	dead := attempts >= MaxScanAttempts || errors.Is(scanErr, storage.ErrNotFound) || errors.Is(scanErr, errNoStorageKey)
+	next := time.Now().Add(backoff(ScanRetryBase, ScanRetryMax, attempts))
+	err := s.RepoMongo.RecordScanFailure(ctx, p.AchievementID.Hex(), p.Attachment.ID, scanErr.Error(), next, dead)
+	if err != nil && !errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+		report.Errors = append(report.Errors, fmt.Sprintf("record scan failure of %s: %v", p.Attachment.ID, err))
+		return false
+	}
+	log.Printf("Attachment %s scan failed, attempt %d: %v\n", p.Attachment.ID, attempts, scanErr)
+	return err == nil && dead
+}
+
+func (s *ScanService) scanAttachment(ctx context.Context, hexID string, att modelMongo.Attachment) (string, error) {
+	if att.StorageKey == "" {
+		return "", errNoStorageKey
+	}
+	file, err := s.Files.Open(ctx, att.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	result, err := s.Scanner.Scan(ctx, file)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if !result.Infected {
+		err := s.RepoMongo.SetScanResult(ctx, hexID, att.ID, modelMongo.ScanClean, "", att.StorageKey)
+		if errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+			return modelMongo.ScanClean, nil // Lampiran sudah dihapus / diganti selama scan
+		}
+		return modelMongo.ScanClean, err
+	}
+
+	// Karantina: salin dulu, catat key baru, baru hapus file aslinya
+	quarantineKey := QuarantinePrefix + att.StorageKey
+	if err := s.copyFile(ctx, att.StorageKey, quarantineKey, att); err != nil {
+		return "", fmt.Errorf("quarantine failed: %w", err)
+	}
+	if err := s.RepoMongo.SetScanResult(ctx, hexID, att.ID, modelMongo.ScanInfected, result.Signature, quarantineKey); err != nil && !errors.Is(err, repoMongo.ErrAttachmentNotFound) {
+		return "", err
+	}
+	if err := s.Files.Delete(ctx, att.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("Failed to remove infected attachment %s: %v\n", att.StorageKey, err)
+	}
+	log.Printf("Attachment %s quarantined: %s\n", att.ID, result.Signature)
+	return modelMongo.ScanInfected, nil
+}
+
+func (s *ScanService) copyFile(ctx context.Context, from, to string, att modelMongo.Attachment) error {
+	src, err := s.Files.Open(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return s.Files.Put(ctx, to, src, att.Size, att.FileType)
+}
+
+// RunAttachmentScan godoc
+// @Summary      Run Attachment Scan
+// @Description  Memindai satu batch lampiran berstatus pending sekarang (worker juga berjalan berkala). Lampiran terinfeksi dipindah ke karantina.
+// @Tags         Consistency (Admin)
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200  {object} service.ScanReport
+// @Failure      500  {object} map[string]interface{}
+// @Router       /consistency/scan-attachments [post]
+func (s *ScanService) RunAttachmentScan(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	report, err := s.ScanPending(ctx)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to load pending scans"})
+	}
+	return c.JSON(fiber.Map{"data": report})
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Batas ukuran satu lampiran
+const MaxUploadSize = 10 << 20
+
+// Ekstensi yang diizinkan beserta tipe konten hasil sniffing yang harus cocok
+var AllowedTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".webp": "image/webp",
+}
+
+var (
+	ErrTooLarge        = errors.New("file exceeds maximum size")
+	ErrUnsupportedType = errors.New("file type not allowed (pdf, png, jpg, webp)")
+	ErrTypeMismatch    = errors.New("file content does not match its extension")
+)
+
+// Cek ekstensi dan isi file (512 byte pertama). Mengembalikan ekstensi (lowercase) dan tipe konten
+func Inspect(fileName string, head []byte) (ext string, contentType string, err error) {
+	ext = strings.ToLower(filepath.Ext(fileName))
+	expected, ok := AllowedTypes[ext]
+	if !ok {
+		return "", "", ErrUnsupportedType
+	}
+	sniffed := http.DetectContentType(head)
+	if i := strings.Index(sniffed, ";"); i >= 0 {
+		sniffed = sniffed[:i]
+	}
+	if sniffed != expected {
+		return "", "", ErrTypeMismatch
+	}
+	return ext, sniffed, nil
+}
+
+// Nama file untuk ditampilkan / Content-Disposition: tanpa path, karakter kontrol, atau kutip
+func SanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == '/' || r == '\\' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > 120 {
+		ext := filepath.Ext(name)
+		name = string(runes[:120-len([]rune(ext))]) + ext
+	}
+	return name
+}
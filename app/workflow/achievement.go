@@ -0,0 +1,118 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Status prestasi (disimpan di achievement_references.status)
+const (
+	StatusDraft     = "draft"
+	StatusSubmitted = "submitted"
+	StatusVerified  = "verified"
+	StatusRejected  = "rejected"
+	StatusDeleted   = "deleted"
+
+	StatusNeedsRevision = "needs_revision" // Dikembalikan ke mahasiswa dengan komentar per field
+)
+
+// Aksi yang bisa dilakukan terhadap prestasi
+const (
+	ActionEdit   = "edit"   // Ubah konten / lampiran (status tidak berubah)
+	ActionSubmit = "submit" // Ajukan ke Dosen Wali
+	ActionDelete = "delete" // Soft delete
+	ActionVerify = "verify" // Disetujui Dosen Wali
+	ActionReject = "reject" // Ditolak Dosen Wali
+	ActionRevise = "revise" // Prestasi yang ditolak dikembalikan ke draft untuk diperbaiki
+
+	ActionRequestRevision = "request_revision" // Dosen Wali meminta perbaikan (bukan penolakan)
+)
+
+// Permission yang dibutuhkan tiap aksi (sesuai tabel permissions)
+const (
+	PermUpdate = "achievement:update"
+	PermDelete = "achievement:delete"
+	PermVerify = "achievement:verify"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrRoleNotAllowed    = errors.New("role is not allowed to perform this transition")
+)
+
+// Error bertipe agar handler bisa memetakan ke HTTP status
+type TransitionError struct {
+	From       string
+	Action     string
+	Permission string // Permission yang kurang (untuk ErrRoleNotAllowed)
+	Err        error
+}
+
+func (e *TransitionError) Error() string {
+	if errors.Is(e.Err, ErrRoleNotAllowed) {
+		return fmt.Sprintf("permission '%s' is required to %s an achievement with status '%s'", e.Permission, e.Action, e.From)
+	}
+	return fmt.Sprintf("cannot %s an achievement with status '%s'", e.Action, e.From)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+type rule struct {
+	To         string
+	Permission string
+}
+
+// Tabel transisi: status asal -> aksi -> (status tujuan, permission yang dibutuhkan)
+var transitions = map[string]map[string]rule{
+	StatusDraft: {
+		ActionEdit:   {To: StatusDraft, Permission: PermUpdate},
+		ActionSubmit: {To: StatusSubmitted, Permission: PermUpdate},
+		ActionDelete: {To: StatusDeleted, Permission: PermDelete},
+	},
+	StatusSubmitted: {
+		ActionVerify: {To: StatusVerified, Permission: PermVerify},
+		ActionReject: {To: StatusRejected, Permission: PermVerify},
+
+		ActionRequestRevision: {To: StatusNeedsRevision, Permission: PermVerify},
+	},
+	// Bisa diedit mahasiswa lalu diajukan ulang (ronde review berikutnya)
+	StatusNeedsRevision: {
+		ActionEdit:   {To: StatusNeedsRevision, Permission: PermUpdate},
+		ActionSubmit: {To: StatusSubmitted, Permission: PermUpdate},
+		ActionDelete: {To: StatusDeleted, Permission: PermDelete},
+	},
+	StatusRejected: {
+		ActionRevise: {To: StatusDraft, Permission: PermUpdate},
+	},
+}
+
+// Transition mengembalikan status tujuan jika aksi valid untuk status saat ini
+// dan permission role pemanggil mencukupi
+func Transition(from, action string, permissions []string) (string, error) {
+	r, ok := transitions[from][action]
+	if !ok {
+		return "", &TransitionError{From: from, Action: action, Err: ErrInvalidTransition}
+	}
+
+	for _, p := range permissions {
+		if p == r.Permission {
+			return r.To, nil
+		}
+	}
+	return "", &TransitionError{From: from, Action: action, Permission: r.Permission, Err: ErrRoleNotAllowed}
+}
+
+// AvailableActions mengembalikan daftar aksi yang boleh dilakukan pada status tertentu
+func AvailableActions(from string, permissions []string) []string {
+	actions := []string{}
+	for action := range transitions[from] {
+		if _, err := Transition(from, action, permissions); err == nil {
+			actions = append(actions, action)
+		}
+	}
+	sort.Strings(actions)
+	return actions
+}
@@ -1,10 +1,19 @@
 package config
 
 import (
+	"context"
+	"log"
+	"time"
+
 	repoMongo "be_uas/app/repository/mongodb"
 	repoPG "be_uas/app/repository/postgres" 
+	"be_uas/app/mailer"
+	"be_uas/app/preview"
+	"be_uas/app/scanner"
 	"be_uas/app/service"
+	"be_uas/app/storage"
 	"be_uas/database"
+	"be_uas/middleware"
 	"be_uas/route"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,7 +22,8 @@ import (
 )
 
 func NewApp() *fiber.App {
-	app := fiber.New()
+	// Body limit dinaikkan sedikit di atas batas lampiran (overhead multipart)
+	app := fiber.New(fiber.Config{BodyLimit: storage.MaxUploadSize + 1<<20})
 
 	app.Use(cors.New())
 	app.Use(logger.New(NewLoggerConfig()))
@@ -22,20 +32,92 @@ func NewApp() *fiber.App {
 	userRepo := repoPG.NewUserRepo(database.DB)
 	achieveRepoPG := repoPG.NewAchievementRepoPG(database.DB)
 	achieveRepoMongo := repoMongo.NewAchievementRepoMongo(database.MongoDB)
+	ensureMongoIndexes()
 	
 	reportRepoPG := repoPG.NewReportRepoPG(database.DB)
 	reportRepoMongo := repoMongo.NewReportRepoMongo(database.MongoDB)
 	academicRepo := repoPG.NewAcademicRepoPG(database.DB) 
+	roleRepo := repoPG.NewRoleRepoPG(database.DB)
+	tokenRepo := repoPG.NewTokenRepoPG(database.DB)
+	auditRepo := repoPG.NewAuditRepoPG(database.DB)
+	mfaRepo := repoPG.NewMFARepoPG(database.DB)
+	outboxRepo := repoPG.NewOutboxRepoPG(database.DB)
+	achieveTypeRepo := repoPG.NewAchievementTypeRepoPG(database.DB)
+	pointRuleRepo := repoPG.NewPointRuleRepoPG(database.DB)
+	commentRepo := repoPG.NewCommentRepoPG(database.DB)
+	notificationRepo := repoPG.NewNotificationRepoPG(database.DB)
+	emailRepo := repoPG.NewEmailRepoPG(database.DB)
+
+	// Storage lampiran (lokal / S3-compatible, lihat storage.FromEnv)
+	fileStore, err := storage.FromEnv()
+	if err != nil {
+		log.Fatal("Failed to init attachment storage:", err)
+	}
+	// Antivirus lampiran (stub / clamd, lihat scanner.FromEnv)
+	fileScanner, err := scanner.FromEnv()
+	if err != nil {
+		log.Fatal("Failed to init attachment scanner:", err)
+	}
+	// Pengirim email (log / SMTP, lihat mailer.FromEnv) & template ID/EN
+	mailSender, err := mailer.FromEnv()
+	if err != nil {
+		log.Fatal("Failed to init mailer:", err)
+	}
+	mailTemplates, err := mailer.LoadTemplates()
+	if err != nil {
+		log.Fatal("Failed to load email templates:", err)
+	}
+
+	// Permission per role dimuat dari DB (di-cache oleh middleware)
+	middleware.SetPermissionLoader(roleRepo.GetPermissionsByRoleID)
+	// Denylist token & status sesi dicek oleh AuthRequired
+	middleware.SetTokenRepo(tokenRepo)
+	service.StartRevokedTokenCleanup(tokenRepo, time.Hour)
 
 	// Init Services
-	authService := service.NewAuthService(userRepo)
-	achieveService := service.NewAchievementService(achieveRepoPG, achieveRepoMongo)
-	adminService := service.NewAdminService(userRepo, achieveRepoPG, achieveRepoMongo)
+	authService := service.NewAuthService(userRepo, tokenRepo, auditRepo, mfaRepo)
+	achieveService := service.NewAchievementService(achieveRepoPG, achieveRepoMongo, outboxRepo, achieveTypeRepo, pointRuleRepo, fileStore)
+	adminService := service.NewAdminService(userRepo, achieveRepoPG, achieveRepoMongo, tokenRepo, auditRepo, mfaRepo)
 	reportService := service.NewReportService(reportRepoPG, reportRepoMongo)
 	academicService := service.NewAcademicService(academicRepo)
+	rbacService := service.NewRBACService(roleRepo)
+	achieveTypeService := service.NewAchievementTypeService(achieveTypeRepo)
+	pointsService := service.NewPointsService(pointRuleRepo, achieveTypeRepo, achieveRepoPG, achieveRepoMongo)
+	consistencyService := service.NewConsistencyService(achieveRepoPG, achieveRepoMongo, outboxRepo, fileStore)
+	scanService := service.NewScanService(achieveRepoMongo, fileStore, fileScanner)
+	previewService := service.NewPreviewService(achieveRepoMongo, fileStore, preview.FromEnv())
+	commentService := service.NewCommentService(commentRepo)
+	notificationService := service.NewNotificationService(notificationRepo)
+	emailService := service.NewEmailService(emailRepo, notificationRepo, mailSender, mailTemplates)
+
+	// Event workflow, komentar & pergantian dosen wali menjadi notifikasi in-app
+	achieveService.Notifier = notificationService
+	commentService.Notifier = notificationService
+	academicService.Notifier = notificationService
+	// Event workflow & pergantian dosen wali juga dikirim lewat email (event dicatat bersama perubahan
+	// datanya, dirender & dikirim worker dengan retry), ringkasan harian untuk Dosen Wali
+	service.StartEmailWorker(emailService, 10*time.Second)
+	service.StartDigestJob(emailService, 15*time.Minute)
+
+	// Konsistensi PostgreSQL <-> MongoDB: outbox diproses ulang, data yatim/dangling diperbaiki berkala
+	service.StartOutboxWorker(consistencyService, 5*time.Second)
+	service.StartReconciliationJob(consistencyService, time.Hour)
+	// Lampiran baru berstatus pending sampai lolos scan
+	service.StartScanWorker(scanService, 5*time.Second)
+	// Thumbnail & preview untuk review Dosen Wali (gambar / halaman pertama PDF)
+	service.StartPreviewWorker(previewService, 5*time.Second)
 
 	// ROUTES
-	route.SetupRoutes(app, authService, adminService, achieveService, reportService, academicService)
+	route.SetupRoutes(app, authService, adminService, achieveService, reportService, academicService, rbacService, consistencyService, scanService, achieveTypeService, pointsService, commentService, notificationService, achieveRepoPG)
 
 	return app
-}
\ No newline at end of file
+}
+
+// Index MongoDB (text search). Gagal tidak fatal: pencarian saja yang tidak berfungsi
+func ensureMongoIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := repoMongo.EnsureAchievementIndexes(ctx, database.MongoDB); err != nil {
+		log.Println("Failed to create MongoDB indexes:", err)
+	}
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"log"
+	"sort"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Menjalankan file SQL di folder migrations secara berurutan (sekali per file)
+func RunMigrations() {
+	_, err := DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		name VARCHAR(255) PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+	)`)
+	if err != nil {
+		log.Fatal("Failed to prepare schema_migrations:", err)
+	}
+
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		log.Fatal("Failed to read migrations:", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var exists bool
+		if err := DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE name = $1)`, name).Scan(&exists); err != nil {
+			log.Fatal("Failed to check migration ", name, ": ", err)
+		}
+		if exists {
+			continue
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + name)
+		if err != nil {
+			log.Fatal("Failed to read migration ", name, ": ", err)
+		}
+
+		tx, err := DB.Begin()
+		if err != nil {
+			log.Fatal("Failed to begin migration ", name, ": ", err)
+		}
+		if _, err := tx.Exec(string(content)); err != nil {
+			tx.Rollback()
+			log.Fatal("Failed to apply migration ", name, ": ", err)
+		}
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (name) VALUES ($1)`, name); err != nil {
+			tx.Rollback()
+			log.Fatal("Failed to record migration ", name, ": ", err)
+		}
+		if err := tx.Commit(); err != nil {
+			log.Fatal("Failed to commit migration ", name, ": ", err)
+		}
+		fmt.Println("Applied migration", name)
+	}
+}
@@ -0,0 +1,28 @@
+-- Log transisi status prestasi (append-only)
+CREATE TABLE IF NOT EXISTS achievement_status_history (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    actor_id           UUID REFERENCES users(id) ON DELETE SET NULL,
+    from_status        VARCHAR(20),
+    to_status          VARCHAR(20) NOT NULL,
+    note               TEXT,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_status_history_ref
+    ON achievement_status_history (achievement_ref_id, created_at);
+
+-- Backfill data lama dari kolom timestamp yang sudah ada
+INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, note, created_at)
+SELECT id, NULL, 'draft', 'Achievement created', created_at
+FROM achievement_references;
+
+INSERT INTO achievement_status_history (achievement_ref_id, from_status, to_status, note, created_at)
+SELECT id, 'draft', 'submitted', 'Submitted for verification', submitted_at
+FROM achievement_references
+WHERE submitted_at IS NOT NULL;
+
+INSERT INTO achievement_status_history (achievement_ref_id, actor_id, from_status, to_status, note, created_at)
+SELECT id, verified_by, 'submitted', status, rejection_note, verified_at
+FROM achievement_references
+WHERE verified_at IS NOT NULL AND status IN ('verified', 'rejected');
@@ -0,0 +1,34 @@
+-- Permission standar (format resource:action)
+INSERT INTO permissions (name, resource, action, description)
+SELECT v.name, v.resource, v.action, v.description
+FROM (VALUES
+    ('achievement:create', 'achievement', 'create', 'Membuat prestasi'),
+    ('achievement:read',   'achievement', 'read',   'Melihat prestasi'),
+    ('achievement:update', 'achievement', 'update', 'Mengubah dan mengajukan prestasi'),
+    ('achievement:delete', 'achievement', 'delete', 'Menghapus prestasi'),
+    ('achievement:verify', 'achievement', 'verify', 'Memverifikasi atau menolak prestasi'),
+    ('student:read',       'student',     'read',   'Melihat daftar mahasiswa'),
+    ('user:manage',        'user',        'manage', 'Mengelola user, role dan permission')
+) AS v(name, resource, action, description)
+WHERE NOT EXISTS (SELECT 1 FROM permissions p WHERE p.name = v.name);
+
+-- Admin mendapat semua permission
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'Admin'
+  AND NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id);
+
+-- Dosen Wali
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r JOIN permissions p ON p.name IN ('achievement:read', 'achievement:verify', 'student:read')
+WHERE r.name = 'Dosen Wali'
+  AND NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id);
+
+-- Mahasiswa
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r JOIN permissions p ON p.name IN ('achievement:create', 'achievement:read', 'achievement:update', 'achievement:delete')
+WHERE r.name = 'Mahasiswa'
+  AND NOT EXISTS (SELECT 1 FROM role_permissions rp WHERE rp.role_id = r.id AND rp.permission_id = p.id);
@@ -0,0 +1,18 @@
+-- Lockout akun setelah beberapa kali gagal login
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP;
+
+-- Audit log autentikasi (gagal login, akun terkunci, unlock, dll)
+CREATE TABLE IF NOT EXISTS auth_audit_logs (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID REFERENCES users(id) ON DELETE SET NULL,
+    actor_id    UUID REFERENCES users(id) ON DELETE SET NULL,
+    username    VARCHAR(100),
+    ip_address  VARCHAR(64),
+    event       VARCHAR(50) NOT NULL,
+    detail      TEXT,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_audit_logs_ip ON auth_audit_logs (ip_address, event, created_at);
+CREATE INDEX IF NOT EXISTS idx_auth_audit_logs_user ON auth_audit_logs (user_id, created_at);
@@ -0,0 +1,17 @@
+-- Transactional outbox: perubahan di PostgreSQL yang harus diterapkan ke MongoDB
+-- ditulis dalam transaksi yang sama, lalu dieksekusi worker (retry dengan backoff)
+CREATE TABLE IF NOT EXISTS achievement_outbox (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_id    UUID NOT NULL, -- achievement_references.id
+    event_type      VARCHAR(50) NOT NULL,
+    payload         JSONB NOT NULL DEFAULT '{}',
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, done, failed
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_due ON achievement_outbox (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_achievement_outbox_aggregate ON achievement_outbox (aggregate_id);
@@ -0,0 +1,5 @@
+-- Keyset pagination listing prestasi: (field sort, id)
+CREATE INDEX IF NOT EXISTS idx_achievement_references_created ON achievement_references (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_updated ON achievement_references (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_student ON achievement_references (student_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_achievement_references_mongo ON achievement_references (mongo_achievement_id);
@@ -0,0 +1,73 @@
+-- Katalog jenis prestasi beserta JSON-Schema untuk field details
+CREATE TABLE IF NOT EXISTS achievement_types (
+    code           VARCHAR(50) PRIMARY KEY, -- Disimpan di achievements.achievementType (MongoDB)
+    name           VARCHAR(100) NOT NULL,
+    description    TEXT,
+    details_schema JSONB NOT NULL DEFAULT '{"type": "object"}',
+    default_points INT NOT NULL DEFAULT 10,
+    is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO achievement_types (code, name, description, details_schema) VALUES
+('competition', 'Kompetisi', 'Lomba / kompetisi akademik maupun non-akademik', '{
+    "type": "object",
+    "required": ["competitionName", "competitionLevel"],
+    "properties": {
+        "competitionName":  {"type": "string", "minLength": 3, "maxLength": 200},
+        "competitionLevel": {"type": "string", "enum": ["international", "national", "regional", "local"]},
+        "rank":             {"type": "integer", "minimum": 1},
+        "medalType":        {"type": "string", "enum": ["gold", "silver", "bronze", "honorable_mention"]},
+        "eventDate":        {"type": "string", "format": "date"},
+        "location":         {"type": "string", "maxLength": 200},
+        "organizer":        {"type": "string", "maxLength": 200}
+    }
+}'),
+('publication', 'Publikasi', 'Artikel jurnal, prosiding konferensi, atau buku', '{
+    "type": "object",
+    "required": ["publicationType", "publicationTitle", "authors"],
+    "properties": {
+        "publicationType":  {"type": "string", "enum": ["journal", "conference", "book"]},
+        "publicationTitle": {"type": "string", "minLength": 3, "maxLength": 300},
+        "authors":          {"type": "array", "minItems": 1, "items": {"type": "string", "minLength": 1}},
+        "publisher":        {"type": "string", "maxLength": 200},
+        "issn":             {"type": "string", "pattern": "^[0-9]{4}-[0-9]{3}[0-9Xx]$"},
+        "publishedDate":    {"type": "string", "format": "date"},
+        "url":              {"type": "string", "format": "uri"}
+    }
+}'),
+('organization', 'Organisasi', 'Kepengurusan organisasi kemahasiswaan', '{
+    "type": "object",
+    "required": ["organizationName", "position", "periodStart"],
+    "properties": {
+        "organizationName": {"type": "string", "minLength": 2, "maxLength": 200},
+        "position":         {"type": "string", "minLength": 2, "maxLength": 100},
+        "periodStart":      {"type": "string", "format": "date"},
+        "periodEnd":        {"type": "string", "format": "date"}
+    }
+}'),
+('certification', 'Sertifikasi', 'Sertifikasi keahlian / profesi', '{
+    "type": "object",
+    "required": ["certificationName", "issuedBy"],
+    "properties": {
+        "certificationName":   {"type": "string", "minLength": 2, "maxLength": 200},
+        "issuedBy":            {"type": "string", "minLength": 2, "maxLength": 200},
+        "certificationNumber": {"type": "string", "maxLength": 100},
+        "issuedDate":          {"type": "string", "format": "date"},
+        "validUntil":          {"type": "string", "format": "date"}
+    }
+}'),
+('community_service', 'Pengabdian Masyarakat', 'Kegiatan pengabdian / pelayanan masyarakat', '{
+    "type": "object",
+    "required": ["eventName", "role"],
+    "properties": {
+        "eventName": {"type": "string", "minLength": 3, "maxLength": 200},
+        "role":      {"type": "string", "maxLength": 100},
+        "location":  {"type": "string", "maxLength": 200},
+        "eventDate": {"type": "string", "format": "date"},
+        "organizer": {"type": "string", "maxLength": 200}
+    }
+}'),
+('other', 'Lainnya', 'Prestasi lain yang belum memiliki jenis khusus', '{"type": "object"}')
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,54 @@
+-- Aturan perhitungan poin prestasi (berversi, hanya satu yang aktif)
+CREATE TABLE IF NOT EXISTS point_rule_sets (
+    version      SERIAL PRIMARY KEY,
+    description  TEXT,
+    rules        JSONB NOT NULL,
+    is_active    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
+    activated_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_point_rule_sets_active ON point_rule_sets (is_active) WHERE is_active;
+
+-- Poin dibekukan saat prestasi diverifikasi (tidak ikut berubah saat aturan diganti)
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS verified_points INT,
+    ADD COLUMN IF NOT EXISTS points_rule_version INT;
+
+-- Tingkat indeksasi publikasi dipakai sebagai komponen poin
+UPDATE achievement_types
+SET details_schema = jsonb_set(details_schema, '{properties,indexingTier}',
+    '{"type": "string", "enum": ["scopus_q1", "scopus_q2", "scopus_q3", "scopus_q4", "sinta_1", "sinta_2", "sinta_3", "sinta_4", "sinta_5", "sinta_6", "none"]}')
+WHERE code = 'publication' AND NOT (details_schema->'properties' ? 'indexingTier');
+
+INSERT INTO point_rule_sets (description, rules, is_active, activated_at)
+SELECT 'Aturan awal', '{
+    "types": {
+        "competition": {
+            "components": [
+                {"field": "competitionLevel", "values": {"international": 50, "national": 30, "regional": 20, "local": 10}}
+            ],
+            "multipliers": [
+                {"field": "rank", "ranges": [{"max": 1, "value": 1}, {"min": 2, "max": 2, "value": 0.8}, {"min": 3, "max": 3, "value": 0.6}], "default": 0.4}
+            ]
+        },
+        "publication": {
+            "components": [
+                {"field": "publicationType", "values": {"journal": 20, "conference": 15, "book": 25}},
+                {"field": "indexingTier", "values": {
+                    "scopus_q1": 40, "scopus_q2": 30, "scopus_q3": 20, "scopus_q4": 15,
+                    "sinta_1": 25, "sinta_2": 20, "sinta_3": 15, "sinta_4": 10, "sinta_5": 8, "sinta_6": 5
+                }}
+            ]
+        },
+        "organization": {
+            "components": [
+                {"field": "position", "values": {"ketua": 20, "wakil ketua": 15, "sekretaris": 10, "bendahara": 10}, "default": 5}
+            ]
+        },
+        "certification": {"base": 15},
+        "community_service": {"base": 10}
+    }
+}', TRUE, NOW()
+WHERE NOT EXISTS (SELECT 1 FROM point_rule_sets);
@@ -0,0 +1,33 @@
+-- Hasil review "perlu revisi": status baru + ronde review + komentar per field
+
+-- Jika kolom status memakai tipe enum, tambahkan nilai barunya
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_type WHERE typname = 'achievement_status') THEN
+        ALTER TYPE achievement_status ADD VALUE IF NOT EXISTS 'needs_revision';
+    END IF;
+END $$;
+
+-- Ronde review bertambah setiap kali prestasi diajukan (submit pertama = ronde 1)
+ALTER TABLE achievement_references
+    ADD COLUMN IF NOT EXISTS review_round INT NOT NULL DEFAULT 0;
+
+UPDATE achievement_references
+SET review_round = 1
+WHERE review_round = 0 AND submitted_at IS NOT NULL;
+
+ALTER TABLE achievement_status_history
+    ADD COLUMN IF NOT EXISTS round INT;
+
+CREATE TABLE IF NOT EXISTS achievement_revision_comments (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    round              INT NOT NULL,
+    field              VARCHAR(100) NOT NULL,
+    comment            TEXT NOT NULL,
+    created_by         UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_revision_comments_ref
+    ON achievement_revision_comments (achievement_ref_id, round);
@@ -0,0 +1,25 @@
+-- Diskusi per prestasi (mahasiswa, dosen wali, admin). Balasan selalu menunjuk ke komentar utama thread
+CREATE TABLE IF NOT EXISTS achievement_comments (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    achievement_ref_id UUID NOT NULL REFERENCES achievement_references(id) ON DELETE CASCADE,
+    parent_id          UUID REFERENCES achievement_comments(id) ON DELETE CASCADE,
+    author_id          UUID REFERENCES users(id) ON DELETE SET NULL,
+    body               TEXT NOT NULL,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP NOT NULL DEFAULT NOW(),
+    edited_at          TIMESTAMP,
+    deleted_at         TIMESTAMP -- Soft delete: isi dikosongkan, balasan tetap ada
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_comments_ref
+    ON achievement_comments (achievement_ref_id, created_at);
+
+-- User yang di-mention (hanya pihak yang boleh melihat prestasi)
+CREATE TABLE IF NOT EXISTS achievement_comment_mentions (
+    comment_id UUID NOT NULL REFERENCES achievement_comments(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_comment_mentions_user
+    ON achievement_comment_mentions (user_id);
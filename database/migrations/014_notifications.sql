@@ -0,0 +1,25 @@
+-- Notifikasi in-app per user (event workflow prestasi, komentar, pergantian dosen wali)
+CREATE TABLE IF NOT EXISTS notifications (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id            UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type         VARCHAR(50) NOT NULL,
+    title              VARCHAR(200) NOT NULL,
+    message            TEXT NOT NULL,
+    achievement_ref_id UUID REFERENCES achievement_references(id) ON DELETE CASCADE,
+    actor_id           UUID REFERENCES users(id) ON DELETE SET NULL,
+    data               JSONB NOT NULL DEFAULT '{}',
+    read_at            TIMESTAMP,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+-- Preferensi per jenis event (tidak ada baris = aktif)
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    in_app     BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, event_type)
+);
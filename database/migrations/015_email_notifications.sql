@@ -0,0 +1,30 @@
+-- Notifikasi email: preferensi per jenis event (tidak ada baris = aktif)
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS email BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Pengaturan email per user: bahasa template & mode ringkasan harian (Dosen Wali)
+CREATE TABLE IF NOT EXISTS notification_settings (
+    user_id        UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    locale         VARCHAR(5) NOT NULL DEFAULT 'id', -- id, en
+    email_digest   BOOLEAN NOT NULL DEFAULT FALSE,
+    last_digest_at TIMESTAMP,
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Outbox email: dirender saat event terjadi, dikirim worker via SMTP (retry dengan backoff)
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         UUID REFERENCES users(id) ON DELETE SET NULL,
+    to_address      VARCHAR(255) NOT NULL,
+    event_type      VARCHAR(50) NOT NULL,
+    subject         VARCHAR(300) NOT NULL,
+    text_body       TEXT NOT NULL,
+    html_body       TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, sent, failed
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_due ON email_outbox (status, next_attempt_at);
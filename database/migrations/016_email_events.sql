@@ -0,0 +1,15 @@
+-- Event email yang ditulis di transaksi yang sama dengan perubahan status,
+-- dirender worker menjadi baris email_outbox (penerima, bahasa & preferensi dibaca saat render)
+CREATE TABLE IF NOT EXISTS email_events (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type      VARCHAR(50) NOT NULL,
+    payload         JSONB NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, processing, done, failed
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_events_due ON email_events (status, next_attempt_at);
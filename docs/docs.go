@@ -23,105 +23,60 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/achievements": {
+        "/.well-known/jwks.json": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mahasiswa melihat daftar prestasi miliknya sendiri (Gabungan data PostgreSQL \u0026 MongoDB)",
+                "description": "Public key untuk memverifikasi access token secara offline (termasuk key lama selama masa rotasi)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements"
+                    "Auth"
                 ],
-                "summary": "Get My Achievements",
+                "summary": "JSON Web Key Set",
                 "responses": {
                     "200": {
-                        "description": "Data berisi array prestasi: id, status, detail (dari mongo)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "403": {
-                        "description": "Error: User is not a student",
+                        "description": "Format: {keys: [JWK]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "Error: Failed to fetch achievements",
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/achievement-types": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mahasiswa input prestasi, atau Admin inputkan untuk mahasiswa",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Katalog jenis prestasi beserta JSON-Schema details (dipakai untuk membangun form). Admin dapat melihat jenis nonaktif dengan include_inactive=true",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements"
+                    "Achievement Types"
                 ],
-                "summary": "Create Achievement (Draft)",
+                "summary": "Get Achievement Types",
                 "parameters": [
                     {
-                        "description": "Achievement Data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/mongodb.Achievement"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    }
-                }
-            }
-        },
-        "/achievements/advisees": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
+                        "type": "boolean",
+                        "description": "Sertakan jenis nonaktif (Admin)",
+                        "name": "include_inactive",
+                        "in": "query"
                     }
                 ],
-                "description": "Dosen Wali melihat daftar prestasi yang diajukan oleh mahasiswa bimbingannya untuk diverifikasi.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Achievements (Dosen)"
-                ],
-                "summary": "Get Advisee Achievements (Dosen Wali)",
                 "responses": {
                     "200": {
-                        "description": "Response format: { data: [ {ref_id, status, detail...} ] }",
+                        "description": "Format: {data: [AchievementType]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -135,16 +90,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/achievements/all": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Admin melihat daftar semua prestasi mahasiswa dengan pagination (Gabungan data Postgres \u0026 Mongo)",
+                "description": "Menambah jenis prestasi. details_schema adalah JSON-Schema bertipe object (type, properties, required, enum, format, dll)",
                 "consumes": [
                     "application/json"
                 ],
@@ -152,35 +105,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements (Admin)"
+                    "Achievement Types"
                 ],
-                "summary": "Get All Achievements (Admin)",
+                "summary": "Create Achievement Type",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "Page Number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "Items per Page",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Payload: { code, name, description, details_schema, default_points, is_active }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.AchievementType"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Structure: {data: [], meta: {page, limit, total_data, total_page}}",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Code sudah dipakai",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Schema tidak valid",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -189,39 +151,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}": {
+        "/achievement-types/{code}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat detail lengkap prestasi (Menggabungkan data Reference dari Postgres dan Detail Konten dari MongoDB)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Detail satu jenis prestasi beserta JSON-Schema details",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements"
+                    "Achievement Types"
                 ],
-                "summary": "Get Achievement Detail",
+                "summary": "Get Achievement Type",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement Reference ID (UUID)",
-                        "name": "id",
+                        "description": "Type Code",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Structure: {data: {ref: ReferenceObj, detail: MongoObj}}",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/postgres.AchievementType"
                         }
                     },
                     "404": {
@@ -230,13 +188,6 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
                     }
                 }
             },
@@ -246,7 +197,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengubah data konten prestasi. Hanya diizinkan jika status prestasi masih 'draft'.",
+                "description": "Mengubah nama, deskripsi, schema details, poin default, atau status aktif jenis prestasi. Schema baru berlaku untuk create/update berikutnya",
                 "consumes": [
                     "application/json"
                 ],
@@ -254,58 +205,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements"
+                    "Achievement Types"
                 ],
-                "summary": "Update Achievement (Draft Only)",
+                "summary": "Update Achievement Type",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement Ref ID (UUID)",
-                        "name": "id",
+                        "description": "Type Code",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Data Update (Title, Description, Details, etc)",
+                        "description": "Payload: { name, description, details_schema, default_points, is_active }",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/mongodb.Achievement"
+                            "$ref": "#/definitions/postgres.AchievementType"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Achievement updated",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Error: Invalid Input atau Status bukan Draft",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "401": {
-                        "description": "Error: Unauthorized",
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Error: Not found",
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Error: Internal Server Error",
+                    "422": {
+                        "description": "Error: Schema tidak valid",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -319,19 +263,19 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus prestasi secara soft-delete. Hanya bisa dilakukan jika status masih 'draft'.",
+                "description": "Menonaktifkan jenis prestasi (tidak bisa dipilih untuk prestasi baru, prestasi lama tetap utuh)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements"
+                    "Achievement Types"
                 ],
-                "summary": "Delete Draft Achievement",
+                "summary": "Deactivate Achievement Type",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement Ref ID",
-                        "name": "id",
+                        "description": "Type Code",
+                        "name": "code",
                         "in": "path",
                         "required": true
                     }
@@ -344,47 +288,142 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Error: Only draft can be deleted",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    }
+                }
+            }
+        },
+        "/achievements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mahasiswa melihat daftar prestasi miliknya sendiri (Gabungan data PostgreSQL \u0026 MongoDB)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Get My Achievements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)",
+                        "name": "status",
+                        "in": "query"
                     },
-                    "401": {
-                        "description": "Error: Unauthorized",
+                    {
+                        "type": "string",
+                        "description": "Filter achievement type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sejak (YYYY-MM-DD / RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sampai (YYYY-MM-DD / RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin minimum",
+                        "name": "min_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin maksimum",
+                        "name": "max_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "created_at | updated_at | points | title",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc | desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Jumlah item per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari meta.next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Data berisi array prestasi: id, status, detail (dari mongo), atau error jika detail tidak ditemukan. meta.next_cursor untuk halaman berikutnya",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Error: Not found",
+                    "400": {
+                        "description": "Error: Query tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Error: User is not a student",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "Error: Server failure",
+                        "description": "Error: Failed to fetch achievements",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/achievements/{id}/attachments": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Upload gambar/pdf untuk prestasi",
+                "description": "Mahasiswa input prestasi, atau Admin inputkan untuk mahasiswa. Poin dihitung server dari aturan poin aktif (field points diabaikan)",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -392,68 +431,35 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Upload File Bukti",
+                "summary": "Create Achievement (Draft)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Achievement Ref ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "File Bukti",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "Achievement Data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mongodb.Achievement"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/achievements/{id}/history": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Melihat timeline riwayat perubahan status prestasi (Draft -\u003e Submitted -\u003e Verified/Rejected)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Achievements"
-                ],
-                "summary": "Get Status History",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Achievement Ref ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Response format: {data: [{status, timestamp, note}]}",
+                    },
+                    "403": {
+                        "description": "Error: Bukan mahasiswa / bukan mahasiswa bimbingan",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Error: Validasi gagal, fields: [{field, message}]",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -462,14 +468,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}/reject": {
-            "post": {
+        "/achievements/advisees": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Dosen Wali menolak prestasi mahasiswa dengan memberikan catatan (Note) wajib.",
+                "description": "Dosen Wali melihat daftar prestasi yang diajukan oleh mahasiswa bimbingannya untuk diverifikasi.",
                 "consumes": [
                     "application/json"
                 ],
@@ -479,31 +485,99 @@ const docTemplate = `{
                 "tags": [
                     "Achievements (Dosen)"
                 ],
-                "summary": "Reject Achievement (Dosen)",
+                "summary": "Get Advisee Achievements (Dosen Wali)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement Ref ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)",
+                        "name": "status",
+                        "in": "query"
                     },
                     {
-                        "description": "Payload: { 'note': 'Alasan penolakan...' }",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Filter achievement type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sejak (YYYY-MM-DD / RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sampai (YYYY-MM-DD / RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin minimum",
+                        "name": "min_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin maksimum",
+                        "name": "max_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter student UUID",
+                        "name": "student_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter program studi",
+                        "name": "program_study",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter dosen wali (lecturer UUID)",
+                        "name": "advisor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "created_at | updated_at | points | title",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc | desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Jumlah item per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari meta.next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Response format: { data: [ {ref_id, status, detail...} ], meta: {limit, sort, next_cursor} }",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -526,33 +600,116 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}/submit": {
-            "post": {
+        "/achievements/all": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengubah status prestasi dari 'draft' menjadi 'submitted' untuk diverifikasi Dosen Wali.",
+                "description": "Admin melihat daftar semua prestasi mahasiswa dengan pagination (Gabungan data Postgres \u0026 Mongo)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Achievements"
+                    "Achievements (Admin)"
                 ],
-                "summary": "Submit to Advisor",
+                "summary": "Get All Achievements (Admin)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement Ref ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter achievement type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sejak (YYYY-MM-DD / RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sampai (YYYY-MM-DD / RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin minimum",
+                        "name": "min_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin maksimum",
+                        "name": "max_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter student UUID",
+                        "name": "student_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter program studi",
+                        "name": "program_study",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter dosen wali (lecturer UUID)",
+                        "name": "advisor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "created_at | updated_at | points | title",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc | desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Jumlah item per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari meta.next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Structure: {data: [], meta: {limit, sort, next_cursor}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -565,13 +722,6 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -582,40 +732,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/achievements/{id}/verify": {
+        "/achievements/bulk-review": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Dosen Wali menyetujui prestasi mahasiswa bimbingannya (Status berubah menjadi 'verified')",
+                "description": "Verifikasi / penolakan banyak prestasi sekaligus (maks 100). Tiap item diproses dalam transaksi sendiri dengan aturan yang sama seperti endpoint verify/reject (scope dosen wali \u0026 state machine); hasil dilaporkan per item",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Achievements (Dosen)"
                 ],
-                "summary": "Verify Achievement (Dosen)",
+                "summary": "Bulk Verify / Reject (Dosen)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Achievement Ref ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Payload: { items: [{ id, action: verify|reject, note }] }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.BulkReviewRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Format: { data: [BulkReviewResult], summary: {total, succeeded, failed} }",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -624,69 +779,68 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
-            "post": {
-                "description": "Masuk sebagai Admin, Dosen, atau Mahasiswa",
-                "consumes": [
-                    "application/json"
+        "/achievements/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Pencarian full-text pada judul, deskripsi, tag, dan beberapa field details (urut berdasarkan relevansi, dengan potongan teks yang di-highlight). Hasil mengikuti visibilitas role: Mahasiswa milik sendiri, Dosen Wali mahasiswa bimbingan, Admin semua.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Achievements"
                 ],
-                "summary": "Login User",
+                "summary": "Search Achievements",
                 "parameters": [
                     {
-                        "description": "Login Credentials",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/postgres.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Kata kunci (mendukung frasa dalam tanda kutip dan -pengecualian)",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items per Page (maks 50)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Format: { data: [{ id, student_id, status, score, highlights: {field: snippet}, detail }], meta: {page, limit} }",
                         "schema": {
-                            "$ref": "#/definitions/postgres.LoginResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Error: Query kosong / terlalu panjang",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Error: User is not a student",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Logout user (Client side clear token)",
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Logout",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -695,78 +849,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/profile": {
+        "/achievements/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mendapatkan detail user yang sedang login beserta permissions",
+                "description": "Melihat detail lengkap prestasi (Menggabungkan data Reference dari Postgres dan Detail Konten dari MongoDB)",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Achievements"
                 ],
-                "summary": "Get User Profile",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/postgres.UserDetail"
-                        }
-                    }
-                }
-            }
-        },
-        "/auth/refresh": {
-            "post": {
-                "security": [
+                "summary": "Get Achievement Detail",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Achievement Reference ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "Dapatkan token baru menggunakan Refresh Token (Mekanisme via Header Authorization saat ini)",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Auth"
-                ],
-                "summary": "Refresh Access Token",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Structure: {data: {ref: ReferenceObj, detail: MongoObj, actions: [aksi yang tersedia], revision_comments: [komentar ronde terakhir jika needs_revision]}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/lecturers": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mendapatkan daftar semua dosen yang terdaftar dalam sistem",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Academic"
-                ],
-                "summary": "Get All Lecturers",
-                "responses": {
-                    "200": {
-                        "description": "Format: {data: [Array of Lecturers]}",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -780,16 +899,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/lecturers/{id}/advisees": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat daftar mahasiswa yang dibimbing (perwalian) oleh dosen tertentu berdasarkan ID Dosen.",
+                "description": "Mengubah data konten prestasi. Hanya diizinkan jika status prestasi masih 'draft'.",
                 "consumes": [
                     "application/json"
                 ],
@@ -797,88 +914,97 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Academic"
+                    "Achievements"
                 ],
-                "summary": "Get Lecturer's Advisees",
+                "summary": "Update Achievement (Draft Only)",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Lecturer UUID",
+                        "description": "Achievement Ref ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Data Update (Title, Description, Details, etc)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/mongodb.Achievement"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Returns object {data: [Student Array]}",
+                        "description": "message: Achievement updated",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Error: Invalid Input",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/reports/statistics": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mendapatkan data statistik untuk Dashboard: total prestasi per tipe (untuk Pie Chart) dan Top 5 Mahasiswa (Leaderboard).",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Reports"
-                ],
-                "summary": "Get Global Statistics",
-                "responses": {
-                    "200": {
-                        "description": "Format: {message: string, data: {achievements_by_type: Array, top_students: Array}}",
+                    },
+                    "401": {
+                        "description": "Error: Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Status bukan Draft",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Validasi gagal, fields: [{field, message}]",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Error: Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/reports/student/{id}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat statistik prestasi spesifik untuk satu mahasiswa (Total Verified, Submitted, Rejected)",
+                "description": "Menghapus prestasi secara soft-delete. Hanya bisa dilakukan jika status masih 'draft'.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Reports"
+                    "Achievements"
                 ],
-                "summary": "Get Student Statistics",
+                "summary": "Delete Draft Achievement",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Student UUID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -892,41 +1018,29 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Error: Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/students": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mendapatkan daftar semua mahasiswa beserta data akademiknya",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Academic"
-                ],
-                "summary": "Get All Students",
-                "responses": {
-                    "200": {
-                        "description": "Format: { data: [StudentObjects...] }",
+                    },
+                    "404": {
+                        "description": "Error: Not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Only draft can be deleted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Error: Server failure",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -935,28 +1049,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/students/{id}": {
+        "/achievements/{id}/attachments": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mendapatkan data lengkap mahasiswa (termasuk program studi \u0026 advisor) berdasarkan ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Daftar lampiran prestasi. Akses sama dengan detail prestasi",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Academic"
+                    "Achievements"
                 ],
-                "summary": "Get Student Detail",
+                "summary": "List File Bukti",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Student UUID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -964,7 +1075,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Format: {data: [Attachment]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -978,173 +1089,265 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/students/{id}/achievements": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat daftar prestasi lengkap (Gabungan data PostgreSQL \u0026 MongoDB) milik mahasiswa tertentu berdasarkan Student ID.",
+                "description": "Upload bukti prestasi (pdf, png, jpg, webp; maks 10 MB). Tipe file dicek dari isinya, checksum SHA-256 disimpan di attachment. Lampiran berstatus pending sampai lolos scan antivirus",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Academic"
+                    "Achievements"
                 ],
-                "summary": "Get Student's Achievements",
+                "summary": "Upload File Bukti",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Student UUID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File Bukti",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Format: { data: [{ id, status, created_at, detail: {...} }] }",
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Error: File is required",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
+                    },
+                    "409": {
+                        "description": "Error: Status bukan Draft",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Error: File terlalu besar",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "Error: Tipe file tidak diizinkan / tidak sesuai isi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
                 }
             }
         },
-        "/students/{id}/advisor": {
-            "put": {
+        "/achievements/{id}/attachments/{attachmentId}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Admin menugaskan atau mengubah Dosen Wali untuk mahasiswa tertentu",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Mengunduh lampiran prestasi. Akses sama dengan detail prestasi (pemilik, dosen wali, atau Admin)",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Academic"
+                    "Achievements"
                 ],
-                "summary": "Update Student Advisor",
+                "summary": "Download File Bukti",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Student UUID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Request Body: { \\",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Lampiran dikarantina",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Error: Lampiran masih discan / gagal discan",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/users": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat daftar semua user",
+                "description": "Mengganti file lampiran selama prestasi masih draft (validasi sama dengan upload). Lampiran baru mendapat ID baru di posisi yang sama; file lama dihapus dari storage",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users (Admin)"
+                    "Achievements"
+                ],
+                "summary": "Replace File Bukti",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File Bukti Pengganti",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
                 ],
-                "summary": "Get All Users",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Format: {data: Attachment, replaced_id}",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/postgres.User"
-                            }
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Status bukan Draft",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Admin membuat user baru (Dosen/Mahasiswa/Admin)",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Menghapus lampiran selama prestasi masih bisa diedit (draft). File di storage ikut dihapus",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users (Admin)"
+                    "Achievements"
                 ],
-                "summary": "Create New User",
+                "summary": "Delete File Bukti",
                 "parameters": [
                     {
-                        "description": "User Data",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Status bukan Draft",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1153,100 +1356,121 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/achievements/{id}/attachments/{attachmentId}/preview": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Melihat detail user berdasarkan ID",
+                "description": "Preview JPEG (maks 1280px) dari lampiran gambar / halaman pertama PDF, untuk review tanpa mengunduh file asli",
                 "produces": [
-                    "application/json"
+                    "image/jpeg"
                 ],
                 "tags": [
-                    "Users (Admin)"
+                    "Achievements"
                 ],
-                "summary": "Get User Detail",
+                "summary": "Preview File Bukti",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/postgres.User"
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/achievements/{id}/attachments/{attachmentId}/thumbnail": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update nama lengkap atau status aktif user",
+                "description": "Thumbnail JPEG (maks 320px) dari lampiran gambar / halaman pertama PDF. Tersedia setelah preview.status = ready",
                 "produces": [
-                    "application/json"
+                    "image/jpeg"
                 ],
                 "tags": [
-                    "Users (Admin)"
+                    "Achievements"
                 ],
-                "summary": "Update User Info",
+                "summary": "Thumbnail File Bukti",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update Data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "type": "string",
+                        "description": "Attachment ID",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/achievements/{id}/comments": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Menghapus user permanen",
+                "description": "Diskusi pada prestasi dalam bentuk thread (komentar utama + balasan). Hanya pemilik, dosen wali, dan Admin yang bisa melihat",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users (Admin)"
+                    "Achievement Comments"
                 ],
-                "summary": "Delete User",
+                "summary": "Get Achievement Comments",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1254,127 +1478,3216 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Format: {data: [AchievementComment + replies]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/users/{id}/role": {
-            "put": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Mengubah role user",
+                "description": "Menulis komentar atau membalas (parent_id). Mention ditulis sebagai @username dan hanya berlaku untuk pihak yang boleh melihat prestasi",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users (Admin)"
+                    "Achievement Comments"
                 ],
-                "summary": "Update User Role",
+                "summary": "Post Comment",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Achievement Ref ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Role Name",
+                        "description": "Payload: { body, parent_id }",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/postgres.CommentRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "mongodb.Achievement": {
-            "type": "object",
-            "properties": {
-                "achievementType": {
-                    "type": "string"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Error: Komentar yang dibalas tidak ditemukan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Komentar yang dibalas sudah dihapus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/comments/{commentId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Penulis dapat menghapus komentarnya sendiri maksimal 1 jam setelah ditulis; Admin dapat menghapus kapan saja. Balasan tetap ditampilkan",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievement Comments"
+                ],
+                "summary": "Delete Comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Bukan penulis / batas waktu hapus lewat",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Penulis dapat mengubah komentarnya sendiri maksimal 15 menit setelah ditulis. Mention dihitung ulang dari isi baru",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievement Comments"
+                ],
+                "summary": "Edit Comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment ID",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload: { body }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.CommentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Error: Bukan penulis / batas waktu edit lewat",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat timeline riwayat perubahan status prestasi dari log transisi (termasuk resubmit \u0026 penolakan berulang). Tiap entri membawa ronde review; entri needs_revision menyertakan komentar per field ronde tersebut",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Get Status History",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items per Page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Response format: {data: [{from_status, to_status, actor_id, actor_name, note, round, comments, created_at}], meta: {page, limit, total_data, total_page}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dosen Wali menolak prestasi mahasiswa dengan memberikan catatan (Note) wajib.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements (Dosen)"
+                ],
+                "summary": "Reject Achievement (Dosen)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload: { 'note': 'Alasan penolakan...' }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Only submitted achievements can be rejected",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/request-revision": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dosen Wali mengembalikan prestasi ke mahasiswa untuk diperbaiki (berbeda dengan penolakan) dengan komentar per field. Field: title, description, achievementType, tags, details, attachments, details.\u003ckey\u003e, attachments.\u003cid\u003e. Mahasiswa bisa mengedit lalu mengajukan ulang sebagai ronde review berikutnya",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements (Dosen)"
+                ],
+                "summary": "Request Revision (Dosen)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload: { note, comments: [{ field, comment }] }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.RevisionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Komentar kosong / field tidak dikenal",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Only submitted achievements can be returned for revision / status changed by another request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/revise": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengembalikan prestasi yang ditolak ke status 'draft' agar bisa diperbaiki dan diajukan ulang.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Revise Rejected Achievement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Only rejected achievements can be revised",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/submit": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah status prestasi dari 'draft' / 'needs_revision' menjadi 'submitted' untuk diverifikasi Dosen Wali. Setiap pengajuan membuka ronde review baru",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Submit to Advisor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Only draft achievements can be submitted / lampiran belum lolos scan antivirus",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievements/{id}/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Dosen Wali menyetujui prestasi mahasiswa bimbingannya (Status berubah menjadi 'verified'). Poin dihitung dengan aturan aktif lalu dibekukan pada reference",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements (Dosen)"
+                ],
+                "summary": "Verify Achievement (Dosen)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement Ref ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Error: Only submitted achievements can be verified",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Status 2FA user yang sedang login (aktif / wajib oleh role / sisa recovery code)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth 2FA"
+                ],
+                "summary": "2FA Status",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {enabled, required, recovery_codes_remaining}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Nonaktifkan 2FA (butuh password dan kode TOTP / recovery code). Tidak bisa jika role mewajibkan 2FA.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth 2FA"
+                ],
+                "summary": "Disable 2FA",
+                "parameters": [
+                    {
+                        "description": "Password \u0026 kode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.DisableTOTPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "2FA wajib untuk role ini",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aktifkan 2FA dengan kode TOTP pertama dari aplikasi authenticator. Response berisi recovery code (hanya ditampilkan sekali). Jika dipanggil dengan mfa_token enrolment, response juga berisi access token \u0026 refresh token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth 2FA"
+                ],
+                "summary": "Enable 2FA",
+                "parameters": [
+                    {
+                        "description": "Kode TOTP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {recovery_codes, token?, refreshToken?}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/recovery-codes": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ganti semua recovery code lama dengan yang baru (butuh kode TOTP valid)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth 2FA"
+                ],
+                "summary": "Regenerate Recovery Codes",
+                "parameters": [
+                    {
+                        "description": "Kode TOTP",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.TOTPCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {recovery_codes}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/setup": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat secret TOTP baru (belum aktif) beserta provisioning URI untuk QR code. Bisa dipanggil dengan access token atau mfa_token enrolment dari login.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth 2FA"
+                ],
+                "summary": "Setup 2FA",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {secret, provisioning_uri}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "2FA sudah aktif",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Masuk sebagai Admin, Dosen, atau Mahasiswa. Jika 2FA aktif, lanjutkan ke POST /auth/login/2fa dengan mfa_token dari response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login User",
+                "parameters": [
+                    {
+                        "description": "Login Credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jika 2FA aktif/wajib: {status: mfa_required | mfa_enrollment_required, data: {mfa_token}}",
+                        "schema": {
+                            "$ref": "#/definitions/postgres.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Kredensial salah atau akun terkunci sementara (respon sama)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "User nonaktif atau wajib ganti password (code: password_change_required)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan dari IP ini",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Tukar mfa_token dari /auth/login + kode TOTP (atau recovery code) dengan access token dan refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login Step 2 (2FA)",
+                "parameters": [
+                    {
+                        "description": "MFA token \u0026 kode",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.MFALoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/postgres.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "423": {
+                        "description": "Akun terkunci sementara",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak percobaan dari IP ini",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mencabut access token yang sedang dipakai (dan refresh token jika dikirim di body). Gunakan ?all=true untuk logout dari semua sesi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Cabut semua sesi user",
+                        "name": "all",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Refresh Token (opsional)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/postgres.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ganti password sendiri. Password lama wajib benar dan password baru harus memenuhi password policy. Semua sesi lain dicabut, response berisi token baru.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Change Password",
+                "parameters": [
+                    {
+                        "description": "Password lama \u0026 baru",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {status, data: {token, refreshToken}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Password baru melanggar policy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password/reset": {
+            "post": {
+                "description": "Set password baru memakai token reset sekali pakai yang diterbitkan admin. Setelah berhasil, login dengan password baru.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset Password with Token",
+                "parameters": [
+                    {
+                        "description": "Token reset \u0026 password baru",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Token tidak valid, sudah dipakai, atau kedaluwarsa",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Password baru melanggar policy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan detail user yang sedang login beserta permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get User Profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/postgres.UserDetail"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Tukar refresh token dengan access token baru. Refresh token dirotasi setiap dipakai; token lama yang dipakai ulang akan mencabut seluruh rantai sesi.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh Access Token",
+                "parameters": [
+                    {
+                        "description": "Refresh Token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {status, data: {token, refreshToken}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/consistency/reconcile": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menjalankan rekonsiliasi PostgreSQL \u003c-\u003e MongoDB sekarang: reference tanpa dokumen ditandai deleted, dokumen tanpa reference di-soft-delete, dan status soft delete diselaraskan mengikuti PostgreSQL. Event outbox yang tertunda ikut diproses.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consistency (Admin)"
+                ],
+                "summary": "Run Consistency Reconciliation",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ReconcileReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/consistency/scan-attachments": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Memindai satu batch lampiran berstatus pending sekarang (worker juga berjalan berkala). Lampiran terinfeksi dipindah ke karantina.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Consistency (Admin)"
+                ],
+                "summary": "Run Attachment Scan",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ScanReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/lecturers": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan daftar semua dosen yang terdaftar dalam sistem",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Academic"
+                ],
+                "summary": "Get All Lecturers",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [Array of Lecturers]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/lecturers/{id}/advisees": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat daftar mahasiswa yang dibimbing (perwalian) oleh dosen tertentu berdasarkan ID Dosen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Academic"
+                ],
+                "summary": "Get Lecturer's Advisees",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lecturer UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns object {data: [Student Array]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Notifikasi milik user yang sedang login (terbaru dulu) beserta jumlah yang belum dibaca",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Get My Notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Hanya yang belum dibaca",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page Number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items per Page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [Notification], meta: {page, limit, total_data, total_page, unread_count}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Jenis event yang diterima user (in-app \u0026 email) beserta bahasa email dan mode ringkasan harian. Jenis yang belum pernah diatur aktif secara default",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Get Notification Preferences",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [{event_type, in_app, email}], settings: {locale, email_digest}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Aktifkan / nonaktifkan jenis event per kanal (preferences = in-app, email = email), ubah bahasa email (id / en), atau mode ringkasan harian (email_digest, khusus Dosen Wali: pengajuan baru dirangkum sekali sehari). Yang tidak disebut tidak berubah",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Update Notification Preferences",
+                "parameters": [
+                    {
+                        "description": "Payload: { preferences: { comment_created: false }, email: { achievement_verified: false }, locale: 'en', email_digest: true }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.NotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [{event_type, in_app, email}], settings: {locale, email_digest}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Error: Jenis event / bahasa tidak dikenal",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Tandai notifikasi tertentu sebagai sudah dibaca (ID milik user lain diabaikan)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Mark Notifications as Read",
+                "parameters": [
+                    {
+                        "description": "Payload: { ids: [...] }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.MarkNotificationsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {updated, unread_count}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Mark All Notifications as Read",
+                "responses": {
+                    "200": {
+                        "description": "Format: {updated, unread_count}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/unread-count": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Jumlah notifikasi yang belum dibaca (untuk badge)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notifications"
+                ],
+                "summary": "Get Unread Notification Count",
+                "responses": {
+                    "200": {
+                        "description": "Format: {unread_count}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat katalog permission yang tersedia",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Get All Permissions",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [Permission]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menambah permission baru dengan format resource:action",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Create Permission",
+                "parameters": [
+                    {
+                        "description": "Permission Data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.Permission"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus permission dan mencabutnya dari semua role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Delete Permission",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Permission ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Daftar semua versi aturan poin (terbaru dulu), termasuk yang sedang aktif",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Point Rules"
+                ],
+                "summary": "Get Point Rule Sets",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [PointRuleSet]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menyimpan versi aturan poin baru (nonaktif). Format rules: {types: {kode_jenis: {base, components: [{field, values, ranges, default}], multipliers: [...], min, max}}}. Poin = (base + jumlah komponen) x hasil kali multiplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Point Rules"
+                ],
+                "summary": "Create Point Rule Set",
+                "parameters": [
+                    {
+                        "description": "Payload: { description, rules }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.PointRuleSet"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Error: Rules tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules/preview": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Simulasi perhitungan poin untuk jenis \u0026 details tertentu beserta rinciannya. version=0 memakai aturan aktif",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Point Rules"
+                ],
+                "summary": "Preview Points",
+                "parameters": [
+                    {
+                        "description": "Payload: { achievementType, details, version }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.PreviewPointsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {points, rule, breakdown: [{kind, field, input, value}], version}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules/recompute": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghitung ulang poin semua prestasi yang belum diverifikasi dengan aturan aktif (sinkron)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Point Rules"
+                ],
+                "summary": "Recompute Points",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {rule_version, checked, updated, errors}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules/{version}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Detail satu versi aturan poin. Gunakan version=active untuk aturan yang sedang berlaku",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Point Rules"
+                ],
+                "summary": "Get Point Rule Set",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Versi aturan (angka) atau active",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/postgres.PointRuleSet"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/point-rules/{version}/activate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menjadikan versi ini aturan yang berlaku, lalu menghitung ulang poin prestasi yang belum diverifikasi di background. Poin prestasi verified tetap (sudah dibekukan)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Point Rules"
+                ],
+                "summary": "Activate Point Rule Set",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Versi aturan",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/statistics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan data statistik untuk Dashboard: total prestasi per tipe (untuk Pie Chart) dan Top 5 Mahasiswa (Leaderboard).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get Global Statistics",
+                "responses": {
+                    "200": {
+                        "description": "Format: {message: string, data: {achievements_by_type: Array, top_students: Array}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/student/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat statistik prestasi spesifik untuk satu mahasiswa (Total Verified, Submitted, Rejected)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reports"
+                ],
+                "summary": "Get Student Statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Student UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat daftar role beserta permission masing-masing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Get All Roles",
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [Role]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuat role baru (permission di-assign terpisah)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Create Role",
+                "parameters": [
+                    {
+                        "description": "Payload: { 'name': 'Kaprodi', 'description': '...' }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah nama, deskripsi, atau policy wajib 2FA (require_2fa) role. require_2fa yang tidak dikirim tidak diubah",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Update Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload: { 'name': '...', 'description': '...', 'require_2fa': false }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/postgres.UpdateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus role beserta assignment permission-nya (gagal jika masih dipakai user)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Delete Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/permissions": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menambahkan permission ke role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Assign Permission to Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload: { 'permission_id': 'UUID' }",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{id}/permissions/{permissionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mencabut permission dari role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "RBAC (Admin)"
+                ],
+                "summary": "Revoke Permission from Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Permission ID",
+                        "name": "permissionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/students": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan daftar semua mahasiswa beserta data akademiknya",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Academic"
+                ],
+                "summary": "Get All Students",
+                "responses": {
+                    "200": {
+                        "description": "Format: { data: [StudentObjects...] }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mendapatkan data lengkap mahasiswa (termasuk program studi \u0026 advisor) berdasarkan ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Academic"
+                ],
+                "summary": "Get Student Detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Student UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/achievements": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat daftar prestasi lengkap (Gabungan data PostgreSQL \u0026 MongoDB) milik mahasiswa tertentu berdasarkan Student ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Academic"
+                ],
+                "summary": "Get Student's Achievements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Student UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status, pisahkan dengan koma (draft,submitted,verified,rejected,deleted)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter achievement type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sejak (YYYY-MM-DD / RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Dibuat sampai (YYYY-MM-DD / RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin minimum",
+                        "name": "min_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Poin maksimum",
+                        "name": "max_points",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created_at",
+                        "description": "created_at | updated_at | points | title",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "desc",
+                        "description": "asc | desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Jumlah item per halaman (maks 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor dari meta.next_cursor halaman sebelumnya",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: { data: [{ id, status, created_at, detail: {...} }], meta: {limit, sort, next_cursor} }",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/students/{id}/advisor": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin menugaskan atau mengubah Dosen Wali untuk mahasiswa tertentu. Mahasiswa, dosen wali baru \u0026 lama dinotifikasi; jika dosen wali sama tidak ada notifikasi",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Academic"
+                ],
+                "summary": "Update Student Advisor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Student UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request Body: { \\",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat daftar semua user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Get All Users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/postgres.User"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin membuat user baru (Dosen/Mahasiswa/Admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Create New User",
+                "parameters": [
+                    {
+                        "description": "User Data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Melihat detail user berdasarkan ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Get User Detail",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/postgres.User"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update nama lengkap atau status aktif user. Menonaktifkan user akan mencabut semua sesinya.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Update User Info",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus user permanen (token user otomatis tidak berlaku karena user tidak ditemukan)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Delete User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/2fa/reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Menghapus TOTP \u0026 recovery code user (misal HP hilang) dan mencabut semua sesinya. Jika role wajib 2FA, user akan diminta enrol ulang saat login.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Reset User 2FA",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/auth-logs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Riwayat audit autentikasi user (login gagal, terkunci, unlock)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Get User Auth Audit Logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Jumlah entri",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: [AuthAuditLog]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/password-reset": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Admin menerbitkan token reset password sekali pakai (berlaku 24 jam). Semua sesi user dicabut dan user wajib set password baru lewat POST /auth/password/reset sebelum bisa login lagi.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Reset User Password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Format: {data: {reset_token, expires_at}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mengubah role user (semua sesi user dicabut agar token memuat role baru)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Update User Role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role Name",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Membuka kunci akun yang terkunci karena terlalu banyak gagal login dan mereset counter percobaan",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users (Admin)"
+                ],
+                "summary": "Unlock User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "mongodb.Achievement": {
+            "type": "object",
+            "properties": {
+                "achievementType": {
+                    "type": "string"
+                },
+                "attachments": {
+                    "description": "Array of Objects",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/mongodb.Attachment"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "Soft Delete support",
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "id": {
+                    "type": "string"
+                },
+                "points": {
+                    "description": "Number (Poin prestasi)",
+                    "type": "integer"
+                },
+                "pointsRuleVersion": {
+                    "description": "Versi aturan poin (0 = poin default jenis)",
+                    "type": "integer"
+                },
+                "studentId": {
+                    "description": "Referensi UUID dari Postgres",
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "mongodb.Attachment": {
+            "type": "object",
+            "properties": {
+                "checksumSha256": {
+                    "type": "string"
+                },
+                "fileName": {
+                    "type": "string"
+                },
+                "fileType": {
+                    "description": "Hasil sniffing isi file, bukan dari client",
+                    "type": "string"
+                },
+                "fileUrl": {
+                    "description": "Endpoint download (butuh akses ke prestasi)",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "preview": {
+                    "description": "Thumbnail \u0026 preview (dibuat worker setelah lampiran lolos scan)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/mongodb.AttachmentPreview"
+                        }
+                    ]
+                },
+                "scanSignature": {
+                    "type": "string"
+                },
+                "scanStatus": {
+                    "description": "Hasil scan antivirus (kosong = diunggah sebelum ada scanning)",
+                    "type": "string"
+                },
+                "scannedAt": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
                 },
-                "attachments": {
-                    "description": "Array of Objects",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/mongodb.Attachment"
-                    }
+                "uploadedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "mongodb.AttachmentPreview": {
+            "type": "object",
+            "properties": {
+                "previewUrl": {
+                    "type": "string"
                 },
-                "createdAt": {
+                "status": {
                     "type": "string"
                 },
-                "deletedAt": {
-                    "description": "Soft Delete support",
+                "thumbnailUrl": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.AchievementType": {
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "string"
                 },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_points": {
+                    "type": "integer"
+                },
                 "description": {
                     "type": "string"
                 },
-                "details": {
-                    "type": "object",
-                    "additionalProperties": true
+                "details_schema": {
+                    "type": "object"
                 },
-                "id": {
+                "is_active": {
+                    "type": "boolean"
+                },
+                "name": {
                     "type": "string"
                 },
-                "points": {
-                    "description": "Number (Poin prestasi)",
-                    "type": "integer"
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.BulkReviewItem": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
                 },
-                "studentId": {
-                    "description": "Referensi UUID dari Postgres",
+                "id": {
                     "type": "string"
                 },
-                "tags": {
+                "note": {
+                    "description": "Wajib untuk reject",
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.BulkReviewRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/postgres.BulkReviewItem"
                     }
-                },
-                "title": {
+                }
+            }
+        },
+        "postgres.ChangePasswordRequest": {
+            "type": "object",
+            "properties": {
+                "current_password": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "new_password": {
                     "type": "string"
                 }
             }
         },
-        "mongodb.Attachment": {
+        "postgres.CommentRequest": {
             "type": "object",
             "properties": {
-                "fileName": {
+                "body": {
                     "type": "string"
                 },
-                "fileType": {
+                "parent_id": {
+                    "description": "Isi untuk membalas",
                     "type": "string"
-                },
-                "fileUrl": {
+                }
+            }
+        },
+        "postgres.DisableTOTPRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
                     "type": "string"
                 },
-                "uploadedAt": {
+                "password": {
                     "type": "string"
                 }
             }
@@ -1417,6 +4730,181 @@ const docTemplate = `{
                 }
             }
         },
+        "postgres.MFALoginRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Kode TOTP 6 digit atau recovery code",
+                    "type": "string"
+                },
+                "mfa_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.MarkNotificationsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "postgres.NotificationPreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "email_digest": {
+                    "type": "boolean"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "preferences": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                }
+            }
+        },
+        "postgres.Permission": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Format: resource:action, contoh achievement:verify",
+                    "type": "string"
+                },
+                "resource": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.PointRuleSet": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "rules": {
+                    "type": "object"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "postgres.PreviewPointsRequest": {
+            "type": "object",
+            "properties": {
+                "achievementType": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "version": {
+                    "description": "0 = aturan aktif",
+                    "type": "integer"
+                }
+            }
+        },
+        "postgres.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.RevisionCommentInput": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.RevisionRequest": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/postgres.RevisionCommentInput"
+                    }
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.TOTPCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "postgres.UpdateRoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "require_2fa": {
+                    "type": "boolean"
+                }
+            }
+        },
         "postgres.User": {
             "type": "object",
             "properties": {
@@ -1426,6 +4914,9 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "failed_login_attempts": {
+                    "type": "integer"
+                },
                 "full_name": {
                     "type": "string"
                 },
@@ -1435,6 +4926,13 @@ const docTemplate = `{
                 "is_active": {
                     "type": "boolean"
                 },
+                "locked_until": {
+                    "description": "Terisi selama akun dikunci",
+                    "type": "string"
+                },
+                "must_change_password": {
+                    "type": "boolean"
+                },
                 "password_hash": {
                     "description": "Tidak dikirim ke JSON response",
                     "type": "string"
@@ -1445,6 +4943,10 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "require_2fa": {
+                    "description": "Dari policy role",
+                    "type": "boolean"
+                },
                 "role_id": {
                     "type": "string"
                 },
@@ -1452,6 +4954,9 @@ const docTemplate = `{
                     "description": "Untuk join query",
                     "type": "string"
                 },
+                "totp_enabled": {
+                    "type": "boolean"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -1482,6 +4987,64 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "service.ReconcileReport": {
+            "type": "object",
+            "properties": {
+                "dangling_references": {
+                    "description": "Reference tanpa dokumen -\u003e ditandai deleted",
+                    "type": "integer"
+                },
+                "deleted_in_mongo": {
+                    "description": "Reference deleted tapi dokumen masih aktif",
+                    "type": "integer"
+                },
+                "documents_scanned": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "orphan_documents": {
+                    "description": "Dokumen tanpa reference -\u003e di-soft-delete",
+                    "type": "integer"
+                },
+                "references_scanned": {
+                    "type": "integer"
+                },
+                "restored_in_mongo": {
+                    "description": "Dokumen terhapus tapi reference masih aktif",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ScanReport": {
+            "type": "object",
+            "properties": {
+                "clean": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "Yang belum permanen tetap pending dan dicoba lagi dengan backoff",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failed": {
+                    "description": "Gagal permanen (file hilang / batas retry), ditandai ScanFailed",
+                    "type": "integer"
+                },
+                "infected": {
+                    "type": "integer"
+                },
+                "scanned": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -3,6 +3,7 @@ package main
 import (
 	"be_uas/config"
 	"be_uas/database"
+	"be_uas/utils"
 	"log"
 	"os"
 	_ "be_uas/docs"
@@ -29,10 +30,21 @@ func main() {
 	// Load Env
 	config.LoadEnv()
 
+	// Load JWT Signing Keys
+	if err := utils.LoadJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+
+	// Load Password Policy
+	utils.LoadPasswordPolicy()
+
 	// Connect Databases
 	database.ConnectPostgres()
 	database.ConnectMongo() 
 
+	// Jalankan Migrasi Skema
+	database.RunMigrations()
+
 	// Setup App
 	app := config.NewApp()
 
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	repoPG "be_uas/app/repository/postgres"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cakupan akses terhadap resource milik mahasiswa
+type AccessScope int
+
+const (
+	ScopeOwner          AccessScope = iota // Hanya mahasiswa pemilik
+	ScopeAdvisor                           // Hanya dosen wali mahasiswa tersebut
+	ScopeOwnerOrAdvisor                    // Pemilik atau dosen walinya
+)
+
+// Cek akses ke prestasi berdasarkan param :id (Achievement Ref ID). Akses penuh (user:manage) selalu lolos.
+func AchievementAccess(repo repoPG.IAchievementRepoPG, scope AccessScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ref, err := repo.GetReferenceByID(c.Params("id"))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+			}
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+		}
+		return checkStudentAccess(c, repo, ref.StudentID, scope, "Forbidden: you do not have access to this achievement")
+	}
+}
+
+// Cek akses ke data mahasiswa berdasarkan param :id (Student ID). Akses penuh (user:manage) selalu lolos.
+func StudentAccess(repo repoPG.IAchievementRepoPG, scope AccessScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return checkStudentAccess(c, repo, c.Params("id"), scope, "Forbidden: you do not have access to this student")
+	}
+}
+
+// Cek bahwa param :id (Lecturer ID) adalah dosen yang sedang login. Akses penuh (user:manage) selalu lolos.
+func LecturerSelfAccess(repo repoPG.IAchievementRepoPG) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if HasFullAccess(c) {
+			return c.Next()
+		}
+
+		userID, _ := c.Locals("user_id").(string)
+		lecturerID, err := repo.GetLecturerIDByUserID(userID)
+		if err != nil || lecturerID != c.Params("id") {
+			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: you can only access your own advisees"})
+		}
+		return c.Next()
+	}
+}
+
+func checkStudentAccess(c *fiber.Ctx, repo repoPG.IAchievementRepoPG, studentID string, scope AccessScope, message string) error {
+	if HasFullAccess(c) {
+		return c.Next()
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+
+	// Pemilik: user login adalah mahasiswa yang bersangkutan
+	if scope == ScopeOwner || scope == ScopeOwnerOrAdvisor {
+		if ownStudentID, err := repo.GetStudentIDByUserID(userID); err == nil && ownStudentID == studentID {
+			return c.Next()
+		}
+	}
+
+	// Dosen Wali: students.advisor_id -> lecturers.user_id
+	if scope == ScopeAdvisor || scope == ScopeOwnerOrAdvisor {
+		ok, err := repo.IsStudentAdvisedBy(studentID, userID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+		}
+		if ok {
+			return c.Next()
+		}
+	}
+
+	return c.Status(403).JSON(fiber.Map{"error": message})
+}
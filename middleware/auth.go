@@ -1,15 +1,32 @@
 package middleware
 
 import (
-	"os"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/utils"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v5"
 )
 
+// Repo untuk cek denylist & status sesi user (diset saat setup app)
+var tokenRepo repoPG.ITokenRepoPG
+
+func SetTokenRepo(repo repoPG.ITokenRepoPG) {
+	tokenRepo = repo
+}
+
 // Middleware Auth Check
 func AuthRequired() fiber.Handler {
+	return tokenAuth(utils.TokenTypeAccess)
+}
+
+// Auth untuk endpoint enrolment 2FA: access token biasa, atau token enrolment
+// yang diterbitkan saat login bagi role yang wajib 2FA tapi user belum mendaftar
+func MFAEnrollmentAuth() fiber.Handler {
+	return tokenAuth(utils.TokenTypeAccess, utils.TokenTypeMFAEnroll)
+}
+
+func tokenAuth(allowed ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
@@ -19,31 +36,49 @@ func AuthRequired() fiber.Handler {
 		// Hapus "Bearer "
 		tokenString := strings.Replace(authHeader, "Bearer ", "", 1)
 		
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_SECRET")), nil
-		})
-
-		if err != nil || !token.Valid {
+		// Verifikasi signature (RS256/EdDSA berdasarkan kid)
+		claims, err := utils.ParseToken(tokenString)
+		if err != nil {
 			return c.Status(401).JSON(fiber.Map{"error": "Invalid or Expired Token"})
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
+		// Hanya tipe token yang diizinkan (umumnya access token) yang boleh dipakai
+		typ, _ := claims["typ"].(string)
+		if !containsString(allowed, typ) {
+			return c.Status(401).JSON(fiber.Map{"error": "Invalid token type"})
+		}
+
+		userID, _ := claims["user_id"].(string)
+		jti, _ := claims["jti"].(string)
+		version, _ := claims["ver"].(float64)
+
+		// Cek revocation: jti di denylist, user dihapus/nonaktif, atau semua sesi dicabut
+		if tokenRepo == nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Token store is not configured"})
+		}
+		state, err := tokenRepo.GetSessionState(userID, jti)
+		if err != nil || !state.IsActive || state.TokenRevoked || int(version) != state.TokenVersion {
+			return c.Status(401).JSON(fiber.Map{"error": "Token has been revoked"})
+		}
+
 		c.Locals("user_id", claims["user_id"])
 		c.Locals("role", claims["role"])
 		c.Locals("role_id", claims["role_id"])
+		c.Locals("jti", jti)
+		c.Locals("token_typ", typ)
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			c.Locals("token_exp", exp.Time)
+		}
 
 		return c.Next()
 	}
 }
 
-// Check Role (RBAC Simple)
-func PermissionCheck(requiredRole string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		role := c.Locals("role").(string)
-		if role != requiredRole && role != "Admin" {
-			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: Access denied"})
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
 		}
-		
-		return c.Next()
 	}
-}
\ No newline at end of file
+	return false
+}
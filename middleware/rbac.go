@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Fungsi untuk memuat daftar permission sebuah role (biasanya dari IRoleRepoPG)
+type PermissionLoader func(roleID string) ([]string, error)
+
+type permissionEntry struct {
+	permissions map[string]bool
+	list        []string
+	loadedAt    time.Time
+}
+
+// Cache permission per role, di-invalidate saat admin mengubah role/permission
+type permissionCache struct {
+	mu      sync.RWMutex
+	loader  PermissionLoader
+	ttl     time.Duration
+	entries map[string]permissionEntry
+}
+
+var permCache = &permissionCache{
+	ttl:     5 * time.Minute,
+	entries: map[string]permissionEntry{},
+}
+
+// Dipanggil sekali saat setup app
+func SetPermissionLoader(loader PermissionLoader) {
+	permCache.mu.Lock()
+	defer permCache.mu.Unlock()
+	permCache.loader = loader
+	permCache.entries = map[string]permissionEntry{}
+}
+
+// Hapus cache satu role, atau semua role jika roleID kosong
+func InvalidatePermissions(roleID string) {
+	permCache.mu.Lock()
+	defer permCache.mu.Unlock()
+	if roleID == "" {
+		permCache.entries = map[string]permissionEntry{}
+		return
+	}
+	delete(permCache.entries, roleID)
+}
+
+func (pc *permissionCache) get(roleID string) (permissionEntry, error) {
+	pc.mu.RLock()
+	entry, ok := pc.entries[roleID]
+	loader := pc.loader
+	pc.mu.RUnlock()
+
+	if ok && time.Since(entry.loadedAt) < pc.ttl {
+		return entry, nil
+	}
+
+	if loader == nil {
+		return permissionEntry{}, errors.New("permission loader is not configured")
+	}
+
+	perms, err := loader(roleID)
+	if err != nil {
+		return permissionEntry{}, err
+	}
+
+	entry = permissionEntry{permissions: map[string]bool{}, list: perms, loadedAt: time.Now()}
+	for _, p := range perms {
+		entry.permissions[p] = true
+	}
+
+	pc.mu.Lock()
+	pc.entries[roleID] = entry
+	pc.mu.Unlock()
+	return entry, nil
+}
+
+// Cek permission (contoh: "achievement:verify") berdasarkan role_id di token.
+// Daftar permission role disimpan ke c.Locals("permissions") untuk dipakai service.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		roleID, _ := c.Locals("role_id").(string)
+		if roleID == "" {
+			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: Access denied"})
+		}
+
+		entry, err := permCache.get(roleID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to load permissions"})
+		}
+
+		c.Locals("permissions", entry.list)
+		if !entry.permissions[permission] {
+			return c.Status(403).JSON(fiber.Map{"error": "Forbidden: missing permission " + permission})
+		}
+
+		return c.Next()
+	}
+}
+
+// Permission untuk akses penuh ke data semua mahasiswa (role Admin, atau role baru yang diberi permission ini)
+const PermFullAccess = "user:manage"
+
+// Permission role user yang sedang login. Memakai hasil RequirePermission jika sudah ada,
+// selain itu dimuat dari cache berdasarkan role_id (gagal dimuat = tanpa permission)
+func Permissions(c *fiber.Ctx) []string {
+	if perms, ok := c.Locals("permissions").([]string); ok {
+		return perms
+	}
+	roleID, _ := c.Locals("role_id").(string)
+	if roleID == "" {
+		return nil
+	}
+	entry, err := permCache.get(roleID)
+	if err != nil {
+		return nil
+	}
+	c.Locals("permissions", entry.list)
+	return entry.list
+}
+
+func HasPermission(c *fiber.Ctx, permission string) bool {
+	return containsString(Permissions(c), permission)
+}
+
+// Pengganti cek role == "Admin": lolos scope kepemilikan / dosen wali
+func HasFullAccess(c *fiber.Ctx) bool {
+	return HasPermission(c, PermFullAccess)
+}
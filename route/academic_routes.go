@@ -1,21 +1,23 @@
 package route
 
 import (
+	repoPG "be_uas/app/repository/postgres"
 	"be_uas/app/service"
 	"be_uas/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
-func AcademicRoutes(group fiber.Router, acadS *service.AcademicService, achS *service.AchievementService) {
+func AcademicRoutes(group fiber.Router, acadS *service.AcademicService, achS *service.AchievementService, achRepo repoPG.IAchievementRepoPG) {
 	academic := group.Group("/", middleware.AuthRequired())
 
 	// Students
-	academic.Get("/students", acadS.GetAllStudents)
-	academic.Get("/students/:id", acadS.GetStudentByID)
-	academic.Get("/students/:id/achievements", achS.GetStudentAchievements)
+	studentAccess := middleware.StudentAccess(achRepo, middleware.ScopeOwnerOrAdvisor)
+	academic.Get("/students", middleware.RequirePermission("student:read"), acadS.GetAllStudents)
+	academic.Get("/students/:id", studentAccess, acadS.GetStudentByID)
+	academic.Get("/students/:id/achievements", studentAccess, achS.GetStudentAchievements)
 
-	academic.Put("/students/:id/advisor", middleware.PermissionCheck("Admin"), acadS.UpdateStudentAdvisor)
+	academic.Put("/students/:id/advisor", middleware.RequirePermission("user:manage"), acadS.UpdateStudentAdvisor)
 
 	academic.Get("/lecturers", acadS.GetAllLecturers)
-	academic.Get("/lecturers/:id/advisees", acadS.GetLecturerAdvisees)
-}
\ No newline at end of file
+	academic.Get("/lecturers/:id/advisees", middleware.LecturerSelfAccess(achRepo), acadS.GetLecturerAdvisees)
+}
@@ -1,29 +1,53 @@
 package route
 
 import (
+	repoPG "be_uas/app/repository/postgres"
 	"be_uas/app/service"
 	"be_uas/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
-func AchievementRoutes(group fiber.Router, achS *service.AchievementService) {
+func AchievementRoutes(group fiber.Router, achS *service.AchievementService, adminS *service.AdminService, commentS *service.CommentService, achRepo repoPG.IAchievementRepoPG) {
 	ach := group.Group("/achievements", middleware.AuthRequired())
 	
-	ach.Get("/advisees", middleware.PermissionCheck("Dosen Wali"), achS.GetAdviseesAchievements)
+	ach.Get("/advisees", middleware.RequirePermission("achievement:verify"), achS.GetAdviseesAchievements)
+	ach.Get("/all", middleware.RequirePermission("user:manage"), adminS.GetAllAchievements)
 	
-	// Public/Shared Access (Filtered by logic in service)
-	ach.Get("/", achS.GetAllAchievements)
-	ach.Get("/:id", achS.GetAchievementByID)
-	ach.Get("/:id/history", achS.GetAchievementHistory)
+	// Shared Access (Pemilik, Dosen Wali, atau Admin)
+	read := middleware.RequirePermission("achievement:read")
+	ach.Get("/", read, achS.GetAllAchievements)
+	ach.Get("/search", read, achS.SearchAchievements)
+	ach.Get("/:id", read, middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor), achS.GetAchievementByID)
+	ach.Get("/:id/history", read, middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor), achS.GetAchievementHistory)
+	ach.Get("/:id/attachments", read, middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor), achS.ListAttachments)
+	ach.Get("/:id/attachments/:attachmentId", read, middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor), achS.DownloadAttachment)
+	ach.Get("/:id/attachments/:attachmentId/thumbnail", read, middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor), achS.DownloadAttachmentThumbnail)
+	ach.Get("/:id/attachments/:attachmentId/preview", read, middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor), achS.DownloadAttachmentPreview)
 
-	// Mahasiswa Actions
-	ach.Post("/", achS.CreateAchievement)
-	ach.Put("/:id", achS.UpdateAchievement)
-	ach.Delete("/:id", achS.DeleteAchievement)
-	ach.Post("/:id/submit", achS.SubmitAchievement)
-	ach.Post("/:id/attachments", achS.UploadAttachment)
+	// Diskusi (pemilik, dosen wali, atau Admin); hak edit/hapus dicek di service
+	participant := middleware.AchievementAccess(achRepo, middleware.ScopeOwnerOrAdvisor)
+	ach.Get("/:id/comments", read, participant, commentS.ListComments)
+	ach.Post("/:id/comments", read, participant, commentS.CreateComment)
+	ach.Patch("/:id/comments/:commentId", read, participant, commentS.UpdateComment)
+	ach.Delete("/:id/comments/:commentId", read, participant, commentS.DeleteComment)
 
-	// Dosen Wali Actions
-	ach.Post("/:id/verify", middleware.PermissionCheck("Dosen Wali"), achS.VerifyAchievement)
-	ach.Post("/:id/reject", middleware.PermissionCheck("Dosen Wali"), achS.RejectAchievement)
-}
\ No newline at end of file
+	// Mahasiswa Actions (Hanya pemilik atau Admin)
+	owner := middleware.AchievementAccess(achRepo, middleware.ScopeOwner)
+	update := middleware.RequirePermission("achievement:update")
+	ach.Post("/", middleware.RequirePermission("achievement:create"), achS.CreateAchievement)
+	ach.Put("/:id", update, owner, achS.UpdateAchievement)
+	ach.Delete("/:id", middleware.RequirePermission("achievement:delete"), owner, achS.DeleteAchievement)
+	ach.Post("/:id/submit", update, owner, achS.SubmitAchievement)
+	ach.Post("/:id/revise", update, owner, achS.ReviseAchievement)
+	ach.Post("/:id/attachments", update, owner, achS.UploadAttachment)
+	ach.Put("/:id/attachments/:attachmentId", update, owner, achS.ReplaceAttachment)
+	ach.Delete("/:id/attachments/:attachmentId", update, owner, achS.DeleteAttachment)
+
+	// Dosen Wali Actions (Hanya dosen wali mahasiswa tersebut atau Admin)
+	verify := middleware.RequirePermission("achievement:verify")
+	advisor := middleware.AchievementAccess(achRepo, middleware.ScopeAdvisor)
+	ach.Post("/:id/verify", verify, advisor, achS.VerifyAchievement)
+	ach.Post("/:id/reject", verify, advisor, achS.RejectAchievement)
+	ach.Post("/:id/request-revision", verify, advisor, achS.RequestRevision)
+	ach.Post("/bulk-review", verify, achS.BulkReviewAchievements) // Scope dosen wali dicek per item
+}
@@ -0,0 +1,18 @@
+package route
+
+import (
+	"be_uas/app/service"
+	"be_uas/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func AchievementTypeRoutes(group fiber.Router, typeS *service.AchievementTypeService) {
+	types := group.Group("/achievement-types", middleware.AuthRequired())
+	types.Get("/", typeS.ListAchievementTypes)
+	types.Get("/:code", typeS.GetAchievementType)
+
+	manage := middleware.RequirePermission("user:manage")
+	types.Post("/", manage, typeS.CreateAchievementType)
+	types.Put("/:code", manage, typeS.UpdateAchievementType)
+	types.Delete("/:code", manage, typeS.DeleteAchievementType)
+}
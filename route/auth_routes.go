@@ -9,7 +9,17 @@ import (
 func AuthRoutes(group fiber.Router, authS *service.AuthService) {
 	auth := group.Group("/auth")
 	auth.Post("/login", authS.Login)
-	auth.Post("/refresh", middleware.AuthRequired(), authS.RefreshToken)
-	auth.Post("/logout", authS.Logout)
+	auth.Post("/login/2fa", authS.VerifyLogin2FA)
+	auth.Post("/refresh", authS.RefreshToken)
+	auth.Post("/logout", middleware.AuthRequired(), authS.Logout)
 	auth.Get("/profile", middleware.AuthRequired(), authS.GetProfile)
+	auth.Post("/password", middleware.AuthRequired(), authS.ChangePassword)
+	auth.Post("/password/reset", authS.ResetPassword)
+
+	// 2FA (setup & enable juga menerima token enrolment dari login)
+	auth.Get("/2fa", middleware.AuthRequired(), authS.GetTOTPStatus)
+	auth.Post("/2fa/setup", middleware.MFAEnrollmentAuth(), authS.SetupTOTP)
+	auth.Post("/2fa/enable", middleware.MFAEnrollmentAuth(), authS.EnableTOTP)
+	auth.Post("/2fa/disable", middleware.AuthRequired(), authS.DisableTOTP)
+	auth.Post("/2fa/recovery-codes", middleware.AuthRequired(), authS.RegenerateRecoveryCodes)
 }
\ No newline at end of file
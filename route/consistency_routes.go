@@ -0,0 +1,13 @@
+package route
+
+import (
+	"be_uas/app/service"
+	"be_uas/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func ConsistencyRoutes(group fiber.Router, consS *service.ConsistencyService, scanS *service.ScanService) {
+	cons := group.Group("/consistency", middleware.AuthRequired(), middleware.RequirePermission("user:manage"))
+	cons.Post("/reconcile", consS.RunReconciliation)
+	cons.Post("/scan-attachments", scanS.RunAttachmentScan)
+}
@@ -0,0 +1,18 @@
+package route
+
+import (
+	"be_uas/app/service"
+	"be_uas/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Notifikasi selalu milik user yang sedang login (tanpa permission khusus)
+func NotificationRoutes(group fiber.Router, notifS *service.NotificationService) {
+	notif := group.Group("/notifications", middleware.AuthRequired())
+	notif.Get("/", notifS.GetNotifications)
+	notif.Get("/unread-count", notifS.GetUnreadCount)
+	notif.Post("/read", notifS.MarkNotificationsRead)
+	notif.Post("/read-all", notifS.MarkAllNotificationsRead)
+	notif.Get("/preferences", notifS.GetNotificationPreferences)
+	notif.Put("/preferences", notifS.UpdateNotificationPreferences)
+}
@@ -0,0 +1,17 @@
+package route
+
+import (
+	"be_uas/app/service"
+	"be_uas/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func PointRuleRoutes(group fiber.Router, pointsS *service.PointsService) {
+	rules := group.Group("/point-rules", middleware.AuthRequired(), middleware.RequirePermission("user:manage"))
+	rules.Get("/", pointsS.ListPointRuleSets)
+	rules.Post("/", pointsS.CreatePointRuleSet)
+	rules.Post("/preview", pointsS.PreviewPoints)
+	rules.Post("/recompute", pointsS.RecomputePoints)
+	rules.Get("/:version", pointsS.GetPointRuleSet)
+	rules.Post("/:version/activate", pointsS.ActivatePointRuleSet)
+}
@@ -0,0 +1,22 @@
+package route
+
+import (
+	"be_uas/app/service"
+	"be_uas/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func RBACRoutes(group fiber.Router, rbacS *service.RBACService) {
+	roles := group.Group("/roles", middleware.AuthRequired(), middleware.RequirePermission("user:manage"))
+	roles.Get("/", rbacS.ListRoles)
+	roles.Post("/", rbacS.CreateRole)
+	roles.Put("/:id", rbacS.UpdateRole)
+	roles.Delete("/:id", rbacS.DeleteRole)
+	roles.Post("/:id/permissions", rbacS.AssignPermission)
+	roles.Delete("/:id/permissions/:permissionId", rbacS.RevokePermission)
+
+	perms := group.Group("/permissions", middleware.AuthRequired(), middleware.RequirePermission("user:manage"))
+	perms.Get("/", rbacS.ListPermissions)
+	perms.Post("/", rbacS.CreatePermission)
+	perms.Delete("/:id", rbacS.DeletePermission)
+}
@@ -1,6 +1,7 @@
 package route
 
 import (
+	repoPG "be_uas/app/repository/postgres"
 	"be_uas/app/service"
     "be_uas/middleware"
 	"github.com/gofiber/fiber/v2"
@@ -14,7 +15,15 @@ func SetupRoutes(app *fiber.App,
 	adminS *service.AdminService, 
 	achS *service.AchievementService, 
 	repS *service.ReportService,
-	acadS *service.AcademicService) {
+	acadS *service.AcademicService,
+	rbacS *service.RBACService,
+	consS *service.ConsistencyService,
+	scanS *service.ScanService,
+	typeS *service.AchievementTypeService,
+	pointsS *service.PointsService,
+	commentS *service.CommentService,
+	notifS *service.NotificationService,
+	achRepo repoPG.IAchievementRepoPG) {
 	
 	app.Use(logger.New())
 
@@ -22,14 +31,20 @@ func SetupRoutes(app *fiber.App,
         return c.Redirect("/swagger/index.html")
     })
 	app.Get("/swagger/*", swagger.HandlerDefault)
+	app.Get("/.well-known/jwks.json", authS.JWKS)
 
 	api := app.Group("/api/v1")
 
 	AuthRoutes(api, authS)
 	UserRoutes(api, adminS) 
-	AchievementRoutes(api, achS)
-	AcademicRoutes(api, acadS, achS)
+	RBACRoutes(api, rbacS)
+	ConsistencyRoutes(api, consS, scanS)
+	AchievementRoutes(api, achS, adminS, commentS, achRepo)
+	AchievementTypeRoutes(api, typeS)
+	PointRuleRoutes(api, pointsS)
+	AcademicRoutes(api, acadS, achS, achRepo)
+	NotificationRoutes(api, notifS)
 
 	api.Get("/reports/statistics", middleware.AuthRequired(), repS.GetStatistics)
-	api.Get("/reports/student/:id", middleware.AuthRequired(), repS.GetStudentReport)
+	api.Get("/reports/student/:id", middleware.AuthRequired(), middleware.StudentAccess(achRepo, middleware.ScopeOwnerOrAdvisor), repS.GetStudentReport)
 }
\ No newline at end of file
@@ -7,11 +7,15 @@ import (
 )
 
 func UserRoutes(group fiber.Router, adminS *service.AdminService) {
-	users := group.Group("/users", middleware.AuthRequired(), middleware.PermissionCheck("Admin"))
+	users := group.Group("/users", middleware.AuthRequired(), middleware.RequirePermission("user:manage"))
 	users.Get("/", adminS.ListUsers)        
 	users.Post("/", adminS.CreateUser)
 	users.Get("/:id", adminS.GetUserDetail) 
 	users.Put("/:id", adminS.UpdateUser)
 	users.Put("/:id/role", adminS.UpdateRole) 
+	users.Post("/:id/unlock", adminS.UnlockUser)
+	users.Post("/:id/password-reset", adminS.ResetUserPassword)
+	users.Post("/:id/2fa/reset", adminS.ResetUserTOTP)
+	users.Get("/:id/auth-logs", adminS.GetUserAuthLogs)
 	users.Delete("/:id", adminS.DeleteUser)
 }
\ No newline at end of file
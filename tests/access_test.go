@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/middleware"
+	"be_uas/tests/mocks"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper: app dengan user login tertentu dan route yang dilindungi AchievementAccess
+func setupAccessApp(userID, role string, guard fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", userID)
+		c.Locals("role", role)
+		return c.Next()
+	})
+	app.Put("/achievements/:id", guard, func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+	return app
+}
+
+func TestAchievementAccess_OwnerAllowed(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", StudentID: "student-A"}, nil)
+	mockPG.On("GetStudentIDByUserID", "user-A").Return("student-A", nil)
+
+	app := setupAccessApp("user-A", "Mahasiswa", middleware.AchievementAccess(mockPG, middleware.ScopeOwner))
+	resp, _ := app.Test(httptest.NewRequest("PUT", "/achievements/ref-1", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestAchievementAccess_OtherStudentForbidden(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", StudentID: "student-A"}, nil)
+	mockPG.On("GetStudentIDByUserID", "user-B").Return("student-B", nil)
+
+	app := setupAccessApp("user-B", "Mahasiswa", middleware.AchievementAccess(mockPG, middleware.ScopeOwner))
+	resp, _ := app.Test(httptest.NewRequest("PUT", "/achievements/ref-1", nil))
+
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestAchievementAccess_AdvisorScope(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", StudentID: "student-A"}, nil)
+	mockPG.On("IsStudentAdvisedBy", "student-A", "dosen-wali-A").Return(true, nil)
+	mockPG.On("IsStudentAdvisedBy", "student-A", "dosen-lain").Return(false, nil)
+
+	// Dosen wali mahasiswa tersebut boleh
+	app := setupAccessApp("dosen-wali-A", "Dosen Wali", middleware.AchievementAccess(mockPG, middleware.ScopeAdvisor))
+	resp, _ := app.Test(httptest.NewRequest("PUT", "/achievements/ref-1", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// Dosen lain ditolak
+	app = setupAccessApp("dosen-lain", "Dosen Wali", middleware.AchievementAccess(mockPG, middleware.ScopeAdvisor))
+	resp, _ = app.Test(httptest.NewRequest("PUT", "/achievements/ref-1", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestAchievementAccess_FullAccessFromPermission(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", StudentID: "student-A"}, nil)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "kaprodi-1")
+		c.Locals("role", "Kaprodi")
+		c.Locals("permissions", []string{"achievement:read", "user:manage"})
+		return c.Next()
+	})
+	app.Put("/achievements/:id", middleware.AchievementAccess(mockPG, middleware.ScopeOwner), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	// Nama role tidak dikenal, tapi permission user:manage memberi akses penuh tanpa cek kepemilikan
+	resp, _ := app.Test(httptest.NewRequest("PUT", "/achievements/ref-1", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	mockPG.AssertNotCalled(t, "GetStudentIDByUserID", "kaprodi-1")
+}
+
+func TestAchievementAccess_AdminRoleNameWithoutPermission(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", StudentID: "student-A"}, nil)
+	mockPG.On("GetStudentIDByUserID", "admin-x").Return("", nil)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "admin-x")
+		c.Locals("role", "Admin")
+		c.Locals("permissions", []string{"achievement:read"})
+		return c.Next()
+	})
+	app.Put("/achievements/:id", middleware.AchievementAccess(mockPG, middleware.ScopeOwner), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+
+	// Nama role saja tidak cukup tanpa permission
+	resp, _ := app.Test(httptest.NewRequest("PUT", "/achievements/ref-1", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+}
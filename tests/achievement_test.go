@@ -3,6 +3,7 @@ package tests
 import (
 	"be_uas/app/model/mongodb"
 	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
 	"be_uas/app/service"
 	"be_uas/tests/mocks"
 	"bytes"
@@ -22,6 +23,7 @@ func setupAppWithAuth(handler fiber.Handler) *fiber.App {
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "user-123")
 		c.Locals("role", "Mahasiswa")
+		c.Locals("permissions", studentPerms)
 		return c.Next()
 	})
 	return app
@@ -33,20 +35,21 @@ func TestCreateAchievement_Success(t *testing.T) {
 	mockMongo := new(mocks.AchievementRepoMongo)
 
 	// Inject kedua mock ke Service
-	svc := service.NewAchievementService(mockPG, mockMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
 
 	// 2. Expectation
 	// Mock Mongo Insert
 	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-uuid-999", nil)
 	mockMongo.On("InsertAchievement", mock.Anything, mock.Anything).Return("mongo-id-999", nil)
 	// Mock Postgres Create Reference
-	mockPG.On("CreateReference", mock.Anything).Return(nil)
+	mockPG.On("CreateReference", mock.Anything, "user-123").Return(nil)
 
 	// 3. Request
 	reqBody := mongodb.Achievement{
 		Title:           "Juara 1 Lomba",
 		AchievementType: "competition",
 		Points:          100,
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
 	}
 	bodyBytes, _ := json.Marshal(reqBody)
 
@@ -66,7 +69,7 @@ func TestCreateAchievement_Success(t *testing.T) {
 func TestSubmitAchievement_Success(t *testing.T) {
 	mockPG := new(mocks.AchievementRepoPG)
 	mockMongo := new(mocks.AchievementRepoMongo)
-	svc := service.NewAchievementService(mockPG, mockMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
 
 	refID := "ref-uuid-abc"
 
@@ -76,8 +79,13 @@ func TestSubmitAchievement_Success(t *testing.T) {
 		ID: refID, Status: "draft",
 	}, nil)
 
+	// Semua lampiran sudah lolos scan
+	mockMongo.On("FindAchievementByID", mock.Anything, "").Return(&mongodb.Achievement{
+		Attachments: []mongodb.Attachment{{ID: "att-1", ScanStatus: mongodb.ScanClean}},
+	}, nil)
+
 	// Mock Update Status ke 'submitted'
-	mockPG.On("UpdateStatus", refID, "submitted").Return(nil)
+	mockPG.On("UpdateStatus", refID, "draft", "submitted", "user-123").Return(nil)
 
 	// 2. Request
 	app := setupAppWithAuth(svc.SubmitAchievement)
@@ -97,6 +105,7 @@ func setupAppWithDosenAuth(handler fiber.Handler) *fiber.App {
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "dosen-uuid-123") 
 		c.Locals("role", "Dosen Wali")        
+		c.Locals("permissions", lecturerPerms)
 		return c.Next()
 	})
 	return app
@@ -105,15 +114,22 @@ func setupAppWithDosenAuth(handler fiber.Handler) *fiber.App {
 func TestVerifyAchievement_Success(t *testing.T) {
 	mockPG := new(mocks.AchievementRepoPG)
 	mockMongo := new(mocks.AchievementRepoMongo)
-	svc := service.NewAchievementService(mockPG, mockMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
 
 	refID := "ref-verify-123"
 	dosenID := "dosen-uuid-123"
 
 	// 1. Expectation
-	// Mock: Dosen memverifikasi (Status berubah jadi 'verified')
-	// Parameter order: id, status, verifiedBy, rejectionNote
-	mockPG.On("UpdateVerification", refID, "verified", dosenID, (*string)(nil)).Return(nil)
+	// Mock: Prestasi sudah di-submit
+	mockPG.On("GetReferenceByID", refID).Return(&postgres.AchievementReference{
+		ID: refID, Status: "submitted", MongoAchievementID: "mongo-verify",
+	}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-verify").Return(&mongodb.Achievement{
+		AchievementType: "competition", Points: 25,
+	}, nil)
+	// Mock: Dosen memverifikasi (Status berubah jadi 'verified', poin default jenis dibekukan)
+	// Parameter order: id, status, verifiedBy, rejectionNote, points
+	mockPG.On("UpdateVerification", refID, "submitted", "verified", dosenID, (*string)(nil), &postgres.PointsSnapshot{Points: 25}).Return(nil)
 
 	// 2. Request
 	app := setupAppWithDosenAuth(svc.VerifyAchievement)
@@ -131,16 +147,20 @@ func TestVerifyAchievement_Success(t *testing.T) {
 func TestRejectAchievement_Success(t *testing.T) {
 	mockPG := new(mocks.AchievementRepoPG)
 	mockMongo := new(mocks.AchievementRepoMongo)
-	svc := service.NewAchievementService(mockPG, mockMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
 
 	refID := "ref-reject-123"
 	dosenID := "dosen-uuid-123"
 	catatan := "Data kurang lengkap"
 
 	// 1. Expectation
+	// Mock: Prestasi sudah di-submit
+	mockPG.On("GetReferenceByID", refID).Return(&postgres.AchievementReference{
+		ID: refID, Status: "submitted",
+	}, nil)
 	// Mock: Dosen menolak (Status berubah jadi 'rejected', ada catatan)
-	// Parameter order: id, status, verifiedBy, rejectionNote
-	mockPG.On("UpdateVerification", refID, "rejected", dosenID, &catatan).Return(nil)
+	// Parameter order: id, fromStatus, status, verifiedBy, rejectionNote, points (tidak dibekukan)
+	mockPG.On("UpdateVerification", refID, "submitted", "rejected", dosenID, &catatan, (*postgres.PointsSnapshot)(nil)).Return(nil)
 
 	// 2. Request Body (Reject butuh alasan/note)
 	reqBody := map[string]string{"note": catatan}
@@ -158,3 +178,146 @@ func TestRejectAchievement_Success(t *testing.T) {
 	// 4. Assert
 	assert.Equal(t, 200, resp.StatusCode)
 }
+
+func TestRejectAchievement_ConcurrentVerifyConflict(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	// Handler membaca 'submitted', tetapi verifikasi lain sudah commit lebih dulu
+	note := "Data kurang lengkap"
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted"}, nil)
+	mockPG.On("UpdateVerification", "ref-1", "submitted", "rejected", "dosen-uuid-123", &note, (*postgres.PointsSnapshot)(nil)).
+		Return(&repoPG.StatusConflictError{Expected: "submitted", Actual: "verified"})
+
+	app := setupAppWithDosenAuth(svc.RejectAchievement)
+	app.Post("/achievements/:id/reject", svc.RejectAchievement)
+	resp := postJSON(app, "/achievements/ref-1/reject", map[string]string{"note": note})
+
+	assert.Equal(t, 409, resp.StatusCode)
+	var body map[string]string
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "verified", body["status"])
+}
+
+func TestUpdateAchievement_SubmittedConcurrently(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockPG.On("LockForEdit", "ref-1", "draft").Return(&repoPG.StatusConflictError{Expected: "draft", Actual: "submitted"})
+
+	app := setupAppWithAuth(svc.UpdateAchievement)
+	app.Put("/achievements/:id", svc.UpdateAchievement)
+	b, _ := json.Marshal(mongodb.Achievement{
+		Title: "Juara 1", AchievementType: "competition",
+		Details: map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+	})
+	req := httptest.NewRequest("PUT", "/achievements/ref-1", bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 409, resp.StatusCode)
+	mockMongo.AssertNotCalled(t, "UpdateAchievement", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAchievementHistory_FromLog(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	refID := "ref-history-123"
+	draft := "draft"
+	submitted := "submitted"
+	note := "Bukti kurang jelas"
+
+	// 1. Expectation: history dibaca dari log, bukan direkonstruksi
+	mockPG.On("GetReferenceByID", refID).Return(&postgres.AchievementReference{ID: refID, Status: "rejected"}, nil)
+	mockPG.On("GetStatusHistory", refID, 20, 0).Return([]postgres.AchievementStatusHistory{
+		{AchievementRefID: refID, ToStatus: "draft"},
+		{AchievementRefID: refID, FromStatus: &draft, ToStatus: "submitted"},
+		{AchievementRefID: refID, FromStatus: &submitted, ToStatus: "rejected", Note: &note},
+	}, 3, nil)
+
+	// 2. Request
+	app := setupAppWithAuth(svc.GetAchievementHistory)
+	app.Get("/achievements/:id/history", svc.GetAchievementHistory)
+
+	req := httptest.NewRequest("GET", "/achievements/"+refID+"/history", nil)
+	resp, _ := app.Test(req)
+
+	// 3. Assert
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data []postgres.AchievementStatusHistory `json:"data"`
+		Meta map[string]int                      `json:"meta"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 3)
+	assert.Equal(t, 3, body.Meta["total_data"])
+	mockPG.AssertExpectations(t)
+}
+
+func TestVerifyAchievement_DraftConflict(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	refID := "ref-draft-123"
+
+	// 1. Expectation: Prestasi masih draft, tidak boleh diverifikasi
+	mockPG.On("GetReferenceByID", refID).Return(&postgres.AchievementReference{
+		ID: refID, Status: "draft",
+	}, nil)
+
+	// 2. Request
+	app := setupAppWithDosenAuth(svc.VerifyAchievement)
+	app.Post("/achievements/:id/verify", svc.VerifyAchievement)
+
+	req := httptest.NewRequest("POST", "/achievements/"+refID+"/verify", nil)
+	resp, _ := app.Test(req)
+
+	// 3. Assert (409 Conflict, status tidak diubah)
+	assert.Equal(t, 409, resp.StatusCode)
+	mockPG.AssertNotCalled(t, "UpdateVerification", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetAllAchievements_BatchedDetailsInOrder(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+	mockPG.On("QueryAchievements", mock.Anything).Return([]postgres.AchievementReference{
+		{ID: "ref-1", MongoAchievementID: "m-1", Status: "draft"},
+		{ID: "ref-2", MongoAchievementID: "m-2", Status: "verified"},
+		{ID: "ref-3", MongoAchievementID: "m-3", Status: "submitted"},
+	}, "", nil)
+	// Satu kali query untuk semua detail; m-2 tidak ditemukan
+	mockMongo.On("FindAchievementsByIDs", mock.Anything, []string{"m-1", "m-2", "m-3"}).Return(map[string]*mongodb.Achievement{
+		"m-3": {Title: "Tiga"},
+		"m-1": {Title: "Satu"},
+	}, nil).Once()
+
+	app := setupAppWithAuth(svc.GetAllAchievements)
+	app.Get("/achievements", svc.GetAllAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body struct {
+		Data []struct {
+			ID     string               `json:"id"`
+			Detail *mongodb.Achievement `json:"detail"`
+			Error  string               `json:"error"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 3)
+	assert.Equal(t, "ref-1", body.Data[0].ID)
+	assert.Equal(t, "Satu", body.Data[0].Detail.Title)
+	assert.Nil(t, body.Data[1].Detail)
+	assert.Equal(t, "Achievement detail not found", body.Data[1].Error)
+	assert.Equal(t, "Tiga", body.Data[2].Detail.Title)
+	mockMongo.AssertExpectations(t)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/schema"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const competitionSchema = `{
+	"type": "object",
+	"required": ["competitionName", "competitionLevel"],
+	"additionalProperties": false,
+	"properties": {
+		"competitionName":  {"type": "string", "minLength": 3},
+		"competitionLevel": {"type": "string", "enum": ["international", "national", "regional", "local"]},
+		"rank":             {"type": "integer", "minimum": 1},
+		"eventDate":        {"type": "string", "format": "date"}
+	}
+}`
+
+// Katalog berisi jenis 'competition' (aktif, poin default 25)
+func competitionTypeRepo() *mocks.AchievementTypeRepo {
+	repo := new(mocks.AchievementTypeRepo)
+	repo.On("GetTypeByCode", "competition").Return(&postgres.AchievementType{
+		Code: "competition", Name: "Kompetisi", DetailsSchema: json.RawMessage(competitionSchema), DefaultPoints: 25, IsActive: true,
+	}, nil)
+	repo.On("GetTypeByCode", mock.Anything).Return(nil, sql.ErrNoRows)
+	return repo
+}
+
+type validationBody struct {
+	Error  string              `json:"error"`
+	Fields []schema.FieldError `json:"fields"`
+}
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := schema.Parse([]byte(competitionSchema))
+	assert.NoError(t, err)
+
+	errs := s.Validate(map[string]interface{}{
+		"competitionLevel": "galaxy",
+		"rank":             1.5,
+		"eventDate":        "17-08-2024",
+		"extra":            true,
+	}, "details")
+	assert.Equal(t, []schema.FieldError{
+		{Field: "details.competitionLevel", Message: "must be one of: international, national, regional, local"},
+		{Field: "details.competitionName", Message: "is required"},
+		{Field: "details.eventDate", Message: "must be a date (YYYY-MM-DD)"},
+		{Field: "details.extra", Message: "is not allowed"},
+		{Field: "details.rank", Message: "must be of type integer"},
+	}, errs)
+
+	_, err = schema.Parse([]byte(`{"type": "string"}`))
+	assert.ErrorIs(t, err, schema.ErrInvalidSchema)
+}
+
+func TestCreateAchievement_DetailsValidationFails(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
+
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+
+	body, _ := json.Marshal(mongodb.Achievement{
+		Title: "Juara 1", AchievementType: "competition",
+		Details: map[string]interface{}{"competitionName": "Gemastik", "rank": 0},
+	})
+	app := setupAppWithAuth(svc.CreateAchievement)
+	app.Post("/achievements", svc.CreateAchievement)
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 422, resp.StatusCode)
+	var res validationBody
+	json.NewDecoder(resp.Body).Decode(&res)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "details.competitionLevel", Message: "is required"},
+		{Field: "details.rank", Message: "must be >= 1"},
+	}, res.Fields)
+	mockMongo.AssertNotCalled(t, "InsertAchievement", mock.Anything, mock.Anything)
+}
+
+func TestCreateAchievement_UnknownTypeAndDefaultPoints(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
+
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+	app := setupAppWithAuth(svc.CreateAchievement)
+	app.Post("/achievements", svc.CreateAchievement)
+	send := func(a mongodb.Achievement) int {
+		body, _ := json.Marshal(a)
+		req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 422, send(mongodb.Achievement{Title: "X", AchievementType: "hobi"}))
+
+	// Poin kosong diisi dari poin default jenis prestasi
+	mockMongo.On("InsertAchievement", mock.Anything, mock.MatchedBy(func(a mongodb.Achievement) bool {
+		return a.Points == 25
+	})).Return("mongo-1", nil)
+	mockPG.On("CreateReference", mock.Anything, "user-123").Return(nil)
+	assert.Equal(t, 201, send(mongodb.Achievement{
+		Title: "Juara 1", AchievementType: "competition",
+		Details: map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+	}))
+	mockMongo.AssertExpectations(t)
+}
+
+func TestCreateAchievementType_InvalidSchema(t *testing.T) {
+	mockRepo := new(mocks.AchievementTypeRepo)
+	svc := service.NewAchievementTypeService(mockRepo)
+
+	app := setupAppWithAdminAuth(svc.CreateAchievementType)
+	app.Post("/achievement-types", svc.CreateAchievementType)
+	resp := postJSON(app, "/achievement-types", map[string]interface{}{
+		"code": "hackathon", "name": "Hackathon",
+		"details_schema": map[string]interface{}{"type": "object", "properties": map[string]interface{}{"x": map[string]interface{}{"type": "text"}}},
+	})
+
+	assert.Equal(t, 422, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "CreateType", mock.Anything)
+}
+
+func TestCreateAchievementType_Success(t *testing.T) {
+	mockRepo := new(mocks.AchievementTypeRepo)
+	svc := service.NewAchievementTypeService(mockRepo)
+
+	mockRepo.On("GetTypeByCode", "hackathon").Return(nil, sql.ErrNoRows)
+	mockRepo.On("CreateType", mock.MatchedBy(func(t postgres.AchievementType) bool {
+		return t.Code == "hackathon" && t.IsActive && string(t.DetailsSchema) == `{"type": "object"}`
+	})).Return(nil)
+
+	app := setupAppWithAdminAuth(svc.CreateAchievementType)
+	app.Post("/achievement-types", svc.CreateAchievementType)
+	resp := postJSON(app, "/achievement-types", map[string]interface{}{"code": "hackathon", "name": "Hackathon"})
+
+	assert.Equal(t, 201, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
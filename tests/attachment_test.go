@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/app/storage"
+	"be_uas/tests/mocks"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Storage lokal berisi satu lampiran lama (achievements/ref-1/att-1.pdf)
+func storageWithAttachment(t *testing.T) (string, *storage.Local, mongodb.Attachment) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocal(dir)
+	store.Put(context.Background(), "achievements/ref-1/att-1.pdf", strings.NewReader("%PDF-1.4 lama"), 13, "application/pdf")
+	return dir, store, mongodb.Attachment{
+		ID: "att-1", FileName: "salah.pdf", FileType: "application/pdf", StorageKey: "achievements/ref-1/att-1.pdf",
+	}
+}
+
+// Outbox yang langsung mengeksekusi event GC file yang baru dicatat
+func gcOutbox(key string) *mocks.OutboxRepo {
+	outbox := new(mocks.OutboxRepo)
+	outbox.On("Enqueue", "ref-1", postgres.EventAttachmentFileDelete, postgres.StoredFilePayload{StorageKey: key}).Return(nil)
+	payload, _ := json.Marshal(postgres.StoredFilePayload{StorageKey: key})
+	outbox.On("ClaimEvents", "ref-1", service.OutboxBatchSize).Return([]postgres.OutboxEvent{{
+		ID: "ev-gc", EventType: postgres.EventAttachmentFileDelete, Payload: payload, Attempts: 1,
+	}}, nil)
+	outbox.On("MarkEventDone", "ev-gc").Return(nil)
+	return outbox
+}
+
+func TestListAttachments(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{
+		Attachments: []mongodb.Attachment{{ID: "att-1", FileName: "a.pdf", StorageKey: "rahasia/a.pdf"}},
+	}, nil)
+
+	app := setupAppWithAuth(svc.ListAttachments)
+	app.Get("/achievements/:id/attachments", svc.ListAttachments)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var raw bytes.Buffer
+	raw.ReadFrom(resp.Body)
+	assert.Contains(t, raw.String(), `"id":"att-1"`)
+	assert.NotContains(t, raw.String(), "rahasia") // Storage key tidak diekspos
+}
+
+func TestDeleteAttachment_GarbageCollectsFile(t *testing.T) {
+	dir, store, old := storageWithAttachment(t)
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	outbox := gcOutbox(old.StorageKey)
+	svc := service.NewAchievementService(mockPG, mockMongo, outbox, new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), store)
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{Attachments: []mongodb.Attachment{old}}, nil)
+	mockPG.On("LockForEdit", "ref-1", "draft").Return(nil)
+	mockMongo.On("RemoveAttachment", mock.Anything, "mongo-1", "att-1").Return(nil)
+
+	app := setupAppWithAuth(svc.DeleteAttachment)
+	app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-1", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	outbox.AssertExpectations(t)
+	_, err := os.Stat(filepath.Join(dir, "achievements", "ref-1", "att-1.pdf"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDeleteAttachment_OnlyWhileEditable(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted", MongoAchievementID: "mongo-1"}, nil)
+
+	app := setupAppWithAuth(svc.DeleteAttachment)
+	app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-1", nil))
+
+	assert.Equal(t, 409, resp.StatusCode)
+	mockMongo.AssertNotCalled(t, "RemoveAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAttachment_UnknownID(t *testing.T) {
+	_, store, old := storageWithAttachment(t)
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), store)
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{Attachments: []mongodb.Attachment{old}}, nil)
+
+	app := setupAppWithAuth(svc.DeleteAttachment)
+	app.Delete("/achievements/:id/attachments/:attachmentId", svc.DeleteAttachment)
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/ref-1/attachments/att-lain", nil))
+
+	assert.Equal(t, 404, resp.StatusCode)
+}
+
+func TestReplaceAttachment_SwapsFile(t *testing.T) {
+	dir, store, old := storageWithAttachment(t)
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	outbox := gcOutbox(old.StorageKey)
+	svc := service.NewAchievementService(mockPG, mockMongo, outbox, new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), store)
+
+	var replacement mongodb.Attachment
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{Attachments: []mongodb.Attachment{old}}, nil)
+	mockPG.On("LockForEdit", "ref-1", "draft").Return(nil)
+	mockMongo.On("ReplaceAttachment", mock.Anything, "mongo-1", "att-1", mock.Anything).Run(func(args mock.Arguments) {
+		replacement = args.Get(3).(mongodb.Attachment)
+	}).Return(nil)
+
+	app := setupAppWithAuth(svc.ReplaceAttachment)
+	app.Put("/achievements/:id/attachments/:attachmentId", svc.ReplaceAttachment)
+	resp, _ := app.Test(uploadRequest("PUT", "/achievements/ref-1/attachments/att-1", "sertifikat.png", pngBytes))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.NotEqual(t, "att-1", replacement.ID)
+	assert.Equal(t, "image/png", replacement.FileType)
+
+	// File baru tersimpan, file lama di-GC
+	_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(replacement.StorageKey)))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "achievements", "ref-1", "att-1.pdf"))
+	assert.True(t, os.IsNotExist(err))
+	outbox.AssertExpectations(t)
+}
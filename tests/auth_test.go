@@ -7,19 +7,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"net/http/httptest"
-	"os"
 	"testing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 )
 
 func TestLogin_Success(t *testing.T) {
 	// 1. Setup
-	os.Setenv("JWT_SECRET", "test_secret")
 	mockUserRepo := new(mocks.UserRepo)
-	authService := service.NewAuthService(mockUserRepo) // Inject Mock
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, new(mocks.MFARepo)) // Inject Mock
 
 	// 2. Data Dummy
 	password := "rahasia123"
@@ -36,6 +37,9 @@ func TestLogin_Success(t *testing.T) {
 	// 3. Expectation (Mocking)
 	// "Kalau ada yang minta user 'mahasiswa_test', kasih dummyUser ini ya"
 	mockUserRepo.On("GetByUsername", "mahasiswa_test").Return(dummyUser, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	// Hash refresh token disimpan di DB
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
 
 	// 4. Setup Fiber & Request
 	app := fiber.New()
@@ -58,7 +62,8 @@ func TestLogin_Success(t *testing.T) {
 func TestLogin_WrongPassword(t *testing.T) {
 	// 1. Setup
 	mockUserRepo := new(mocks.UserRepo)
-	authService := service.NewAuthService(mockUserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
 
 	// 2. Data Dummy (Password Asli: "rahasia123")
 	password := "rahasia123"
@@ -72,6 +77,9 @@ func TestLogin_WrongPassword(t *testing.T) {
 	}
 
 	mockUserRepo.On("GetByUsername", "mahasiswa_test").Return(dummyUser, nil)
+	mockUserRepo.On("RecordFailedLogin", "user-uuid-1").Return(1, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
 
 	// 3. Request (Password Input: "SALAH")
 	app := fiber.New()
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type bulkReviewResponse struct {
+	Data    []postgres.BulkReviewResult `json:"data"`
+	Summary struct {
+		Total     int `json:"total"`
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	} `json:"summary"`
+}
+
+func TestBulkReview_PerItemResults(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
+
+	note := "Sertifikat tidak terbaca"
+	// ref-1: diverifikasi
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", StudentID: "stu-1", Status: "submitted", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{AchievementType: "competition", Points: 25}, nil)
+	mockPG.On("UpdateVerification", "ref-1", "submitted", "verified", "dosen-uuid-123", (*string)(nil), &postgres.PointsSnapshot{Points: 25}).Return(nil)
+	// ref-2: ditolak dengan catatan
+	mockPG.On("GetReferenceByID", "ref-2").Return(&postgres.AchievementReference{ID: "ref-2", StudentID: "stu-1", Status: "submitted"}, nil)
+	mockPG.On("UpdateVerification", "ref-2", "submitted", "rejected", "dosen-uuid-123", &note, (*postgres.PointsSnapshot)(nil)).Return(nil)
+	// ref-3: masih draft
+	mockPG.On("GetReferenceByID", "ref-3").Return(&postgres.AchievementReference{ID: "ref-3", StudentID: "stu-1", Status: "draft"}, nil)
+	// ref-4: bukan mahasiswa bimbingan
+	mockPG.On("GetReferenceByID", "ref-4").Return(&postgres.AchievementReference{ID: "ref-4", StudentID: "stu-lain", Status: "submitted"}, nil)
+	// ref-5: tidak ada
+	mockPG.On("GetReferenceByID", "ref-5").Return(nil, sql.ErrNoRows)
+	mockPG.On("IsStudentAdvisedBy", "stu-1", "dosen-uuid-123").Return(true, nil)
+	mockPG.On("IsStudentAdvisedBy", "stu-lain", "dosen-uuid-123").Return(false, nil)
+
+	app := setupAppWithDosenAuth(svc.BulkReviewAchievements)
+	app.Post("/achievements/bulk-review", svc.BulkReviewAchievements)
+	resp := postJSON(app, "/achievements/bulk-review", postgres.BulkReviewRequest{Items: []postgres.BulkReviewItem{
+		{ID: "ref-1", Action: "verify"},
+		{ID: "ref-2", Action: "reject", Note: note},
+		{ID: "ref-3", Action: "verify"},
+		{ID: "ref-4", Action: "verify"},
+		{ID: "ref-5", Action: "verify"},
+		{ID: "ref-6", Action: "reject"},             // Tanpa catatan
+		{ID: "ref-1", Action: "reject", Note: note}, // Duplikat
+	}})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body bulkReviewResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	codes := []int{}
+	for _, r := range body.Data {
+		codes = append(codes, r.Code)
+	}
+	assert.Equal(t, []int{200, 200, 409, 403, 404, 400, 400}, codes)
+	assert.Equal(t, "verified", body.Data[0].Status)
+	assert.Equal(t, "rejected", body.Data[1].Status)
+	assert.Equal(t, 7, body.Summary.Total)
+	assert.Equal(t, 2, body.Summary.Succeeded)
+	assert.Equal(t, 5, body.Summary.Failed)
+
+	mockPG.AssertNumberOfCalls(t, "UpdateVerification", 2)
+}
+
+func TestBulkReview_RejectsOversizedBatch(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	items := make([]postgres.BulkReviewItem, postgres.MaxBulkReviewItems+1)
+	app := setupAppWithDosenAuth(svc.BulkReviewAchievements)
+	app.Post("/achievements/bulk-review", svc.BulkReviewAchievements)
+
+	resp := postJSON(app, "/achievements/bulk-review", postgres.BulkReviewRequest{Items: items})
+	assert.Equal(t, 400, resp.StatusCode)
+	resp = postJSON(app, "/achievements/bulk-review", postgres.BulkReviewRequest{})
+	assert.Equal(t, 400, resp.StatusCode)
+	mockPG.AssertNotCalled(t, "GetReferenceByID", mock.Anything)
+}
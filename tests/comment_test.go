@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateComment_ReplyWithMentions(t *testing.T) {
+	repo := new(mocks.CommentRepo)
+	svc := service.NewCommentService(repo)
+
+	rootID := "c-root"
+	repo.On("GetComment", "ref-1", "c-reply").Return(&postgres.AchievementComment{ID: "c-reply", ParentID: &rootID}, nil)
+	// @bukan_pihak tidak bisa melihat prestasi -> tidak dicatat sebagai mention
+	repo.On("FindMentionableUsers", "ref-1", []string{"budi", "bukan_pihak"}).Return([]postgres.CommentMention{
+		{UserID: "user-budi", Username: "budi", FullName: "Budi"},
+	}, nil)
+	repo.On("CreateComment", mock.MatchedBy(func(cm postgres.AchievementComment) bool {
+		return cm.ParentID != nil && *cm.ParentID == rootID && *cm.AuthorID == "dosen-uuid-123" && cm.Body == "Tolong cek @budi, cc @bukan_pihak. Email: x@y.com"
+	}), []string{"user-budi"}).Return(&postgres.AchievementComment{ID: "c-new", ParentID: &rootID}, nil)
+
+	app := setupAppWithDosenAuth(svc.CreateComment)
+	app.Post("/achievements/:id/comments", svc.CreateComment)
+	resp := postJSON(app, "/achievements/ref-1/comments", postgres.CommentRequest{
+		Body: "  Tolong cek @budi, cc @bukan_pihak. Email: x@y.com ", ParentID: "c-reply",
+	})
+
+	assert.Equal(t, 201, resp.StatusCode)
+	var body struct {
+		Data postgres.AchievementComment `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, rootID, *body.Data.ParentID)
+	assert.Len(t, body.Data.Mentions, 1)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateComment_Validation(t *testing.T) {
+	repo := new(mocks.CommentRepo)
+	svc := service.NewCommentService(repo)
+	deleted := time.Now()
+	repo.On("GetComment", "ref-1", "c-missing").Return(nil, sql.ErrNoRows)
+	repo.On("GetComment", "ref-1", "c-deleted").Return(&postgres.AchievementComment{ID: "c-deleted", DeletedAt: &deleted}, nil)
+
+	app := setupAppWithAuth(svc.CreateComment)
+	app.Post("/achievements/:id/comments", svc.CreateComment)
+
+	assert.Equal(t, 400, postJSON(app, "/achievements/ref-1/comments", postgres.CommentRequest{Body: "   "}).StatusCode)
+	assert.Equal(t, 404, postJSON(app, "/achievements/ref-1/comments", postgres.CommentRequest{Body: "Hai", ParentID: "c-missing"}).StatusCode)
+	assert.Equal(t, 409, postJSON(app, "/achievements/ref-1/comments", postgres.CommentRequest{Body: "Hai", ParentID: "c-deleted"}).StatusCode)
+	repo.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything)
+}
+
+func TestUpdateComment_EditWindow(t *testing.T) {
+	repo := new(mocks.CommentRepo)
+	svc := service.NewCommentService(repo)
+	author := "user-123"
+	other := "user-456"
+	repo.On("GetComment", "ref-1", "c-fresh").Return(&postgres.AchievementComment{ID: "c-fresh", AuthorID: &author, CreatedAt: time.Now().Add(-time.Minute)}, nil)
+	repo.On("GetComment", "ref-1", "c-old").Return(&postgres.AchievementComment{ID: "c-old", AuthorID: &author, CreatedAt: time.Now().Add(-service.CommentEditWindow - time.Minute)}, nil)
+	repo.On("GetComment", "ref-1", "c-other").Return(&postgres.AchievementComment{ID: "c-other", AuthorID: &other, CreatedAt: time.Now()}, nil)
+	repo.On("UpdateComment", "c-fresh", "Sudah diperbaiki", []string{}).Return(nil)
+
+	app := setupAppWithAuth(svc.UpdateComment)
+	app.Patch("/achievements/:id/comments/:commentId", svc.UpdateComment)
+	patch := func(id string) int {
+		b, _ := json.Marshal(postgres.CommentRequest{Body: "Sudah diperbaiki"})
+		req := httptest.NewRequest("PATCH", "/achievements/ref-1/comments/"+id, bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, patch("c-fresh"))
+	assert.Equal(t, 403, patch("c-old"))
+	assert.Equal(t, 403, patch("c-other"))
+	repo.AssertNumberOfCalls(t, "UpdateComment", 1)
+}
+
+func TestDeleteComment_AuthorWindowAndAdmin(t *testing.T) {
+	repo := new(mocks.CommentRepo)
+	svc := service.NewCommentService(repo)
+	author := "user-123"
+	repo.On("GetComment", "ref-1", "c-old").Return(&postgres.AchievementComment{ID: "c-old", AuthorID: &author, CreatedAt: time.Now().Add(-2 * service.CommentDeleteWindow)}, nil)
+	repo.On("DeleteComment", "c-old").Return(nil)
+
+	student := setupAppWithAuth(svc.DeleteComment)
+	student.Delete("/achievements/:id/comments/:commentId", svc.DeleteComment)
+	resp, _ := student.Test(httptest.NewRequest("DELETE", "/achievements/ref-1/comments/c-old", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+
+	// Admin (moderasi) tidak dibatasi waktu
+	admin := setupAppWithAdminAuth(svc.DeleteComment)
+	admin.Delete("/achievements/:id/comments/:commentId", svc.DeleteComment)
+	resp, _ = admin.Test(httptest.NewRequest("DELETE", "/achievements/ref-1/comments/c-old", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	repo.AssertNumberOfCalls(t, "DeleteComment", 1)
+}
+
+func TestListComments_Threads(t *testing.T) {
+	repo := new(mocks.CommentRepo)
+	svc := service.NewCommentService(repo)
+	deleted := time.Now()
+	root1, root2 := "c-1", "c-2"
+	repo.On("ListComments", "ref-1").Return([]postgres.AchievementComment{
+		{ID: "c-1", Body: "Sertifikat sudah diunggah?"},
+		{ID: "c-2", DeletedAt: &deleted}, // Dihapus tanpa balasan: disembunyikan
+		{ID: "c-3", ParentID: &root1, Body: "Sudah, Pak"},
+		{ID: "c-4", ParentID: &root1, DeletedAt: &deleted},
+		{ID: "c-5", Body: "Terima kasih"},
+		{ID: "c-6", ParentID: &root2, DeletedAt: &deleted},
+	}, nil)
+
+	app := setupAppWithAuth(svc.ListComments)
+	app.Get("/achievements/:id/comments", svc.ListComments)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/ref-1/comments", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data []postgres.AchievementComment `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, "c-1", body.Data[0].ID)
+	assert.Len(t, body.Data[0].Replies, 1)
+	assert.Equal(t, "c-5", body.Data[1].ID)
+}
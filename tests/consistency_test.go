@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateAchievement_CompensatesWhenReferenceFails(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), noPointRules(), new(mocks.Storage))
+
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-uuid-999", nil)
+	mockMongo.On("InsertAchievement", mock.Anything, mock.Anything).Return("mongo-id-999", nil)
+	mockPG.On("CreateReference", mock.Anything, "user-123").Return(errors.New("db down"))
+	// Dokumen yang sudah terlanjur masuk MongoDB dihapus lagi
+	mockMongo.On("DeleteAchievement", mock.Anything, "mongo-id-999").Return(nil)
+
+	body, _ := json.Marshal(mongodb.Achievement{
+		Title: "Juara 1", AchievementType: "competition",
+		Details: map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national"},
+	})
+	app := setupAppWithAuth(svc.CreateAchievement)
+	app.Post("/achievements", svc.CreateAchievement)
+	req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 500, resp.StatusCode)
+	mockMongo.AssertExpectations(t)
+}
+
+func TestDeleteAchievement_DispatchesOutbox(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	mockOutbox := new(mocks.OutboxRepo)
+	svc := service.NewAchievementService(mockPG, mockMongo, mockOutbox, new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockPG.On("UpdateStatus", "ref-1", "draft", "deleted", "user-123").Return(nil)
+	mockOutbox.On("ClaimEvents", "ref-1", service.OutboxBatchSize).Return([]postgres.OutboxEvent{{
+		ID: "ev-1", EventType: postgres.EventAchievementSoftDelete, Payload: json.RawMessage(`{"mongo_id":"mongo-1"}`), Attempts: 1,
+	}}, nil)
+	mockMongo.On("SoftDeleteAchievement", mock.Anything, "mongo-1").Return(nil)
+	mockOutbox.On("MarkEventDone", "ev-1").Return(nil)
+
+	app := setupAppWithAuth(svc.DeleteAchievement)
+	app.Delete("/achievements/:id", svc.DeleteAchievement)
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/ref-1", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockOutbox.AssertExpectations(t)
+	mockMongo.AssertExpectations(t)
+}
+
+func TestDeleteAchievement_MongoFailureLeavesEventForRetry(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	mockOutbox := new(mocks.OutboxRepo)
+	svc := service.NewAchievementService(mockPG, mockMongo, mockOutbox, new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockPG.On("UpdateStatus", "ref-1", "draft", "deleted", "user-123").Return(nil)
+	mockOutbox.On("ClaimEvents", "ref-1", service.OutboxBatchSize).Return([]postgres.OutboxEvent{{
+		ID: "ev-1", EventType: postgres.EventAchievementSoftDelete, Payload: json.RawMessage(`{"mongo_id":"mongo-1"}`), Attempts: 1,
+	}}, nil)
+	mockMongo.On("SoftDeleteAchievement", mock.Anything, "mongo-1").Return(errors.New("mongo unavailable"))
+	// Dijadwalkan ulang, belum dianggap gagal permanen
+	mockOutbox.On("MarkEventFailed", "ev-1", "mongo unavailable", mock.MatchedBy(func(next time.Time) bool {
+		return next.After(time.Now())
+	}), false).Return(nil)
+
+	app := setupAppWithAuth(svc.DeleteAchievement)
+	app.Delete("/achievements/:id", svc.DeleteAchievement)
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/achievements/ref-1", nil))
+
+	// Status di PostgreSQL sudah berubah, jadi request tetap sukses
+	assert.Equal(t, 200, resp.StatusCode)
+	mockOutbox.AssertExpectations(t)
+	mockOutbox.AssertNotCalled(t, "MarkEventDone", mock.Anything)
+}
+
+func TestReconcile_RepairsInconsistencies(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewConsistencyService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.Storage))
+
+	mockPG.On("ListReferenceLinks", "", service.ReconcileBatchSize).Return([]postgres.AchievementReference{
+		{ID: "ref-1", MongoAchievementID: "m-1", Status: "draft"},    // Dokumen hilang
+		{ID: "ref-2", MongoAchievementID: "m-2", Status: "deleted"},  // Dokumen belum di-soft-delete
+		{ID: "ref-3", MongoAchievementID: "m-3", Status: "verified"}, // Dokumen terhapus
+		{ID: "ref-4", MongoAchievementID: "m-4", Status: "draft"},    // Konsisten
+	}, nil)
+	mockMongo.On("FindDeletionStates", mock.Anything, []string{"m-1", "m-2", "m-3", "m-4"}).Return(map[string]bool{
+		"m-2": false, "m-3": true, "m-4": false,
+	}, nil)
+	mockPG.On("MarkReferenceDeleted", "ref-1", mock.Anything).Return(nil)
+	mockMongo.On("SoftDeleteAchievement", mock.Anything, "m-2").Return(nil)
+	mockMongo.On("RestoreAchievement", mock.Anything, "m-3").Return(nil)
+
+	// Dokumen lama tanpa reference
+	mockMongo.On("ListActiveIDsBefore", mock.Anything, mock.Anything, "", service.ReconcileBatchSize).Return([]string{"m-4", "m-orphan"}, nil)
+	mockPG.On("FindReferencedMongoIDs", []string{"m-4", "m-orphan"}).Return(map[string]bool{"m-4": true}, nil)
+	mockMongo.On("SoftDeleteAchievement", mock.Anything, "m-orphan").Return(nil)
+
+	report := svc.Reconcile(context.Background())
+
+	assert.Equal(t, 1, report.DanglingReferences)
+	assert.Equal(t, 1, report.DeletedInMongo)
+	assert.Equal(t, 1, report.RestoredInMongo)
+	assert.Equal(t, 1, report.OrphanDocuments)
+	assert.Empty(t, report.Errors)
+	mockPG.AssertExpectations(t)
+	mockMongo.AssertExpectations(t)
+}
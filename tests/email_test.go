@@ -0,0 +1,493 @@
+package tests
+
+import (
+	"be_uas/app/mailer"
+	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Stand-in SMTP lokal: alamat bounce@ ditolak permanen (550), busy@ ditolak sementara (451)
+type smtpStandIn struct {
+	Addr string
+
+	mu       sync.Mutex
+	messages []string
+}
+
+func (s *smtpStandIn) Messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func fakeSMTP(t *testing.T) *smtpStandIn {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	srv := &smtpStandIn{Addr: ln.Addr().String()}
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				tp := textproto.NewConn(conn)
+				tp.PrintfLine("220 localhost ESMTP stand-in")
+				for {
+					line, err := tp.ReadLine()
+					if err != nil {
+						return
+					}
+					cmd := strings.ToUpper(line)
+					switch {
+					case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+						tp.PrintfLine("250 localhost")
+					case strings.HasPrefix(cmd, "RCPT TO:<BOUNCE@"):
+						tp.PrintfLine("550 5.1.1 No such user")
+					case strings.HasPrefix(cmd, "RCPT TO:<BUSY@"):
+						tp.PrintfLine("451 4.3.0 Try again later")
+					case strings.HasPrefix(cmd, "MAIL"), strings.HasPrefix(cmd, "RCPT"), strings.HasPrefix(cmd, "RSET"):
+						tp.PrintfLine("250 OK")
+					case cmd == "DATA":
+						tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+						data, err := tp.ReadDotBytes()
+						if err != nil {
+							return
+						}
+						srv.mu.Lock()
+						srv.messages = append(srv.messages, string(data))
+						srv.mu.Unlock()
+						tp.PrintfLine("250 OK queued")
+					case cmd == "QUIT":
+						tp.PrintfLine("221 Bye")
+						return
+					default:
+						tp.PrintfLine("502 Command not implemented")
+					}
+				}
+			}(conn)
+		}
+	}()
+	return srv
+}
+
+func testSender(addr string) *mailer.SMTP {
+	return &mailer.SMTP{Addr: addr, From: "Sistem Prestasi <no-reply@kampus.ac.id>", Timeout: 5 * time.Second}
+}
+
+// Pecah email MIME menjadi subject (sudah di-decode) dan isi per content type
+func parseEmail(t *testing.T, raw string) (string, map[string]string) {
+	msg, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(raw)))
+	assert.NoError(t, err)
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextRawPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(p))
+		mediaType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[mediaType] = string(body)
+	}
+	return subject, parts
+}
+
+func TestSMTP_AgainstStandIn(t *testing.T) {
+	srv := fakeSMTP(t)
+	sender := testSender(srv.Addr)
+
+	err := sender.Send(context.Background(), mailer.Message{
+		To: "Budi <budi@kampus.ac.id>", Subject: "Prestasi Anda telah diverifikasi ✓",
+		Text: "Halo Budi,\nPrestasi sudah diverifikasi.", HTML: "<p>Halo Budi,</p>",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, srv.Messages(), 1)
+
+	subject, parts := parseEmail(t, srv.Messages()[0])
+	assert.Equal(t, "Prestasi Anda telah diverifikasi ✓", subject)
+	assert.Equal(t, "Halo Budi,\nPrestasi sudah diverifikasi.", parts["text/plain"])
+	assert.Equal(t, "<p>Halo Budi,</p>", parts["text/html"])
+
+	// 5xx: permanen (tidak dicoba ulang), 4xx: sementara
+	err = sender.Send(context.Background(), mailer.Message{To: "bounce@kampus.ac.id", Subject: "x"})
+	assert.True(t, errors.Is(err, mailer.ErrPermanent), err)
+	err = sender.Send(context.Background(), mailer.Message{To: "busy@kampus.ac.id", Subject: "x"})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, mailer.ErrPermanent))
+	assert.Len(t, srv.Messages(), 1)
+}
+
+func TestEmailTemplates_Localized(t *testing.T) {
+	tpl, err := mailer.LoadTemplates()
+	assert.NoError(t, err)
+	data := mailer.Data{RecipientName: "Budi", Note: "Sertifikat <b>buram</b>", Link: "https://prestasi.test/achievements/ref-1"}
+
+	id, err := tpl.Render(postgres.NotifAchievementRejected, "id", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Prestasi Anda ditolak", id.Subject)
+	assert.Contains(t, id.Text, "Catatan: Sertifikat <b>buram</b>")
+	assert.Contains(t, id.HTML, "Sertifikat &lt;b&gt;buram&lt;/b&gt;") // Catatan di-escape di HTML
+	assert.Contains(t, id.HTML, `href="https://prestasi.test/achievements/ref-1"`)
+
+	en, err := tpl.Render(postgres.NotifAchievementRejected, "en", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Your achievement was rejected", en.Subject)
+	assert.Contains(t, en.Text, "Note: Sertifikat")
+
+	// Bahasa tidak dikenal -> Indonesia
+	fallback, err := tpl.Render(postgres.NotifAchievementRejected, "fr", data)
+	assert.NoError(t, err)
+	assert.Equal(t, id.Subject, fallback.Subject)
+
+	for _, name := range append(postgres.EmailEventTypes, postgres.EmailLecturerDigest) {
+		for _, locale := range mailer.Locales {
+			msg, err := tpl.Render(name, locale, mailer.Data{RecipientName: "X", Pending: []mailer.DigestItem{{StudentName: "Y"}}})
+			assert.NoError(t, err, name+"/"+locale)
+			assert.NotEmpty(t, msg.Subject, name+"/"+locale)
+		}
+	}
+	assert.False(t, tpl.Has(postgres.NotifCommentCreated))
+
+	// Dosen wali lama mendapat pesan berbeda dari dosen wali baru
+	former, err := tpl.Render(postgres.NotifAdvisorChanged, "en", mailer.Data{RecipientName: "X", StudentName: "Budi", Advisor: true, FormerAdvisor: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "Advisee reassigned: Budi", former.Subject)
+	assert.Contains(t, former.HTML, "no longer your advisee")
+}
+
+func TestRejectAchievement_EmailEventWrittenByTransition(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	notifRepo := new(mocks.NotificationRepo)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+	svc.Notifier = service.NewNotificationService(notifRepo)
+
+	note := "Sertifikat tidak terbaca"
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted"}, nil)
+	mockPG.On("UpdateVerification", "ref-1", "submitted", "rejected", "dosen-uuid-123", &note, (*postgres.PointsSnapshot)(nil)).Return(nil)
+	notifRepo.On("GetAchievementParticipants", "ref-1").Return(&postgres.NotificationParticipants{StudentUserID: "user-123", AdvisorUserID: "dosen-uuid-123"}, nil)
+	notifRepo.On("CreateNotifications", mock.Anything).Return(nil)
+
+	app := setupAppWithDosenAuth(svc.RejectAchievement)
+	app.Post("/achievements/:id/reject", svc.RejectAchievement)
+	resp := postJSON(app, "/achievements/ref-1/reject", map[string]string{"note": note})
+
+	// Event email dicatat repository di transaksi status (UpdateVerification)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockPG.AssertExpectations(t)
+	assert.Equal(t, postgres.NotifAchievementRejected, postgres.TransitionEmailEvents["rejected"])
+}
+
+func TestDeliverPending_RendersEventInRecipientLocale(t *testing.T) {
+	notifRepo := new(mocks.NotificationRepo)
+	emailRepo := new(mocks.EmailRepo)
+	tpl, _ := mailer.LoadTemplates()
+	svc := service.NewEmailService(emailRepo, notifRepo, mailer.Log{}, tpl)
+	svc.BaseURL = "https://prestasi.test"
+
+	note := "Sertifikat tidak terbaca"
+	emailRepo.On("ClaimEmailEvents", service.EmailBatchSize).Return([]postgres.EmailEvent{
+		{ID: "ev-1", Attempts: 1, Event: postgres.NotificationEvent{Type: postgres.NotifAchievementRejected, ActorID: "dosen-uuid-123", AchievementRefID: "ref-1", Note: note}},
+		{ID: "ev-2", Attempts: 3, Event: postgres.NotificationEvent{Type: postgres.NotifAchievementVerified, AchievementRefID: "ref-gone"}},
+	}, nil)
+	notifRepo.On("GetAchievementParticipants", "ref-1").Return(&postgres.NotificationParticipants{
+		StudentUserID: "user-123", AdvisorUserID: "dosen-uuid-123", StudentName: "Budi", StudentNIM: "2101",
+	}, nil)
+	notifRepo.On("GetAchievementParticipants", "ref-gone").Return(nil, errors.New("db down"))
+	emailRepo.On("GetEmailRecipients", []string{"user-123"}, postgres.NotifAchievementRejected, false).Return([]postgres.EmailRecipient{
+		{UserID: "user-123", Email: "budi@kampus.ac.id", FullName: "Budi", Locale: "en"},
+	}, nil)
+	emailRepo.On("CompleteEmailEvent", "ev-1", mock.MatchedBy(func(es []postgres.Email) bool {
+		return len(es) == 1 && es[0].To == "budi@kampus.ac.id" && es[0].Subject == "Your achievement was rejected" &&
+			strings.Contains(es[0].Text, note) && strings.Contains(es[0].HTML, "https://prestasi.test/achievements/ref-1")
+	})).Return(nil)
+	emailRepo.On("MarkEmailEventFailed", "ev-2", "db down", mock.Anything, false).Return(nil)
+	emailRepo.On("ClaimEmails", service.EmailBatchSize).Return([]postgres.Email{}, nil)
+
+	report := svc.DeliverPending(context.Background())
+
+	// Gagal render dijadwalkan ulang, event lain tetap diproses
+	assert.Equal(t, 1, report.Rendered)
+	assert.Empty(t, report.Errors)
+	emailRepo.AssertExpectations(t)
+}
+
+// Event email pergantian dosen wali ditulis di transaksi update, tanpa lewat notifikasi in-app
+func TestUpdateStudentAdvisor_WritesEmailEventInSameTransaction(t *testing.T) {
+	db, state := openFakeDB(t, "")
+	state.Rows = map[string][]driver.Value{"FOR UPDATE OF s": {"lecturer-1", "dosen-user-1"}}
+	repo := repoPG.NewAcademicRepoPG(db)
+
+	ev, err := repo.UpdateStudentAdvisor("student-1", "lecturer-2", "admin-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "dosen-user-1", ev.PreviousAdvisorUserID)
+
+	committed := state.Committed()
+	assert.Len(t, committed, 3)
+	assert.Contains(t, committed[0], "FOR UPDATE OF s")
+	assert.Contains(t, committed[1], "UPDATE students")
+	assert.Contains(t, committed[2], "INSERT INTO email_events")
+}
+
+func TestUpdateStudentAdvisor_EmailEventFailureRollsBack(t *testing.T) {
+	db, state := openFakeDB(t, "INSERT INTO email_events")
+	state.Rows = map[string][]driver.Value{"FOR UPDATE OF s": {"lecturer-1", "dosen-user-1"}}
+	repo := repoPG.NewAcademicRepoPG(db)
+
+	_, err := repo.UpdateStudentAdvisor("student-1", "lecturer-2", "admin-1")
+	assert.Error(t, err)
+	assert.Empty(t, state.Committed())
+}
+
+// Dosen wali sama: tidak ada update maupun event email
+func TestUpdateStudentAdvisor_NoOpWritesNothing(t *testing.T) {
+	db, state := openFakeDB(t, "")
+	state.Rows = map[string][]driver.Value{"FOR UPDATE OF s": {"lecturer-2", "dosen-user-2"}}
+	repo := repoPG.NewAcademicRepoPG(db)
+
+	ev, err := repo.UpdateStudentAdvisor("student-1", "lecturer-2", "admin-1")
+	assert.NoError(t, err)
+	assert.Nil(t, ev)
+	assert.Empty(t, state.Committed())
+	assert.Empty(t, state.Pending())
+}
+
+func TestUpdateStudentAdvisor_StudentNotFound(t *testing.T) {
+	db, _ := openFakeDB(t, "")
+	repo := repoPG.NewAcademicRepoPG(db)
+
+	_, err := repo.UpdateStudentAdvisor("missing", "lecturer-2", "admin-1")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDeliverPending_RetryAndPermanentFailure(t *testing.T) {
+	srv := fakeSMTP(t)
+	repo := new(mocks.EmailRepo)
+	svc := service.NewEmailService(repo, nil, testSender(srv.Addr), nil)
+
+	repo.On("ClaimEmailEvents", service.EmailBatchSize).Return([]postgres.EmailEvent{}, nil)
+	repo.On("ClaimEmails", service.EmailBatchSize).Return([]postgres.Email{
+		{ID: "e-ok", To: "budi@kampus.ac.id", Subject: "Halo", Text: "Halo", HTML: "<p>Halo</p>", Attempts: 1},
+		{ID: "e-busy", To: "busy@kampus.ac.id", Subject: "Halo", Attempts: 2},
+		{ID: "e-bounce", To: "bounce@kampus.ac.id", Subject: "Halo", Attempts: 1},
+		{ID: "e-last", To: "busy@kampus.ac.id", Subject: "Halo", Attempts: service.MaxEmailAttempts},
+	}, nil)
+	repo.On("MarkEmailSent", "e-ok").Return(nil)
+	var retryAt time.Time
+	repo.On("MarkEmailFailed", "e-busy", mock.Anything, mock.Anything, false).Run(func(args mock.Arguments) {
+		retryAt = args.Get(2).(time.Time)
+	}).Return(nil)
+	repo.On("MarkEmailFailed", "e-bounce", mock.Anything, mock.Anything, true).Return(nil)
+	repo.On("MarkEmailFailed", "e-last", mock.Anything, mock.Anything, true).Return(nil)
+
+	report := svc.DeliverPending(context.Background())
+
+	assert.Equal(t, service.EmailReport{Sent: 1, Retried: 1, Failed: 2, Errors: []string{}}, report)
+	assert.Len(t, srv.Messages(), 1)
+	// Backoff eksponensial: percobaan ke-2 -> 2x EmailRetryBase
+	assert.WithinDuration(t, time.Now().Add(2*service.EmailRetryBase), retryAt, 5*time.Second)
+	repo.AssertExpectations(t)
+}
+
+func TestDeliverPending_ReleasesUnsentWhenBatchTimesOut(t *testing.T) {
+	srv := fakeSMTP(t)
+	repo := new(mocks.EmailRepo)
+	svc := service.NewEmailService(repo, nil, testSender(srv.Addr), nil)
+	assert.Less(t, service.EmailBatchTimeout, repoPG.EmailLease)
+
+	repo.On("ClaimEmailEvents", service.EmailBatchSize).Return([]postgres.EmailEvent{}, nil)
+	repo.On("ClaimEmails", service.EmailBatchSize).Return([]postgres.Email{
+		{ID: "e-1", To: "budi@kampus.ac.id", Subject: "Halo", Attempts: 1},
+		{ID: "e-2", To: "citra@kampus.ac.id", Subject: "Halo", Attempts: 1},
+	}, nil)
+	repo.On("ReleaseEmails", []string{"e-1", "e-2"}).Return(nil)
+
+	// Batas waktu batch sudah lewat sebelum pengiriman pertama
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report := svc.DeliverPending(ctx)
+
+	assert.Equal(t, 0, report.Sent+report.Retried+report.Failed)
+	assert.Empty(t, srv.Messages())
+	repo.AssertExpectations(t)
+}
+
+func TestSendDigests_OncePerDayWithPendingOnly(t *testing.T) {
+	repo := new(mocks.EmailRepo)
+	tpl, _ := mailer.LoadTemplates()
+	svc := service.NewEmailService(repo, nil, mailer.Log{}, tpl)
+	svc.DigestHour = 7
+
+	// Sebelum jam kirim: tidak ada yang diproses
+	n, err := svc.SendDigests(time.Date(2026, 3, 2, 6, 59, 0, 0, time.Local))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	repo.AssertNotCalled(t, "GetDigestRecipients", mock.Anything)
+
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.Local)
+	repo.On("GetDigestRecipients", time.Date(2026, 3, 2, 7, 0, 0, 0, time.Local)).Return([]postgres.EmailRecipient{
+		{UserID: "dosen-1", Email: "dosen1@kampus.ac.id", FullName: "Dr. Sari", Locale: "id"},
+		{UserID: "dosen-2", Email: "dosen2@kampus.ac.id", FullName: "Dr. Andi", Locale: "en"},
+	}, nil)
+	repo.On("GetPendingVerifications", "dosen-1").Return([]postgres.PendingVerification{
+		{AchievementRefID: "ref-1", StudentName: "Budi", StudentNIM: "2101", SubmittedAt: now.Add(-24 * time.Hour)},
+		{AchievementRefID: "ref-2", StudentName: "Citra", StudentNIM: "2102", SubmittedAt: now.Add(-2 * time.Hour)},
+	}, nil)
+	repo.On("GetPendingVerifications", "dosen-2").Return([]postgres.PendingVerification{}, nil)
+	cutoff := time.Date(2026, 3, 2, 7, 0, 0, 0, time.Local)
+	repo.On("ClaimDigest", "dosen-1", cutoff, now, mock.MatchedBy(func(e *postgres.Email) bool {
+		return e != nil && e.To == "dosen1@kampus.ac.id" && e.EventType == postgres.EmailLecturerDigest &&
+			e.Subject == "2 prestasi menunggu verifikasi Anda" && strings.Contains(e.Text, "Citra (2102)")
+	})).Return(true, nil)
+	// Tanpa prestasi tertunda tetap diklaim agar tidak dicek ulang hari ini
+	repo.On("ClaimDigest", "dosen-2", cutoff, now, (*postgres.Email)(nil)).Return(true, nil)
+
+	n, err = svc.SendDigests(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+}
+
+// Replika lain sudah mengklaim ringkasan dosen ini: tidak dihitung terkirim
+func TestSendDigests_ClaimLostToOtherReplica(t *testing.T) {
+	repo := new(mocks.EmailRepo)
+	tpl, _ := mailer.LoadTemplates()
+	svc := service.NewEmailService(repo, nil, mailer.Log{}, tpl)
+	svc.DigestHour = 7
+
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.Local)
+	repo.On("GetDigestRecipients", mock.Anything).Return([]postgres.EmailRecipient{{UserID: "dosen-1", Email: "dosen1@kampus.ac.id", Locale: "id"}}, nil)
+	repo.On("GetPendingVerifications", "dosen-1").Return([]postgres.PendingVerification{{AchievementRefID: "ref-1", StudentName: "Budi", SubmittedAt: now}}, nil)
+	repo.On("ClaimDigest", "dosen-1", mock.Anything, now, mock.Anything).Return(false, nil)
+
+	n, err := svc.SendDigests(now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+// Email ringkasan hanya disimpan jika klaim berhasil, di transaksi yang sama
+func TestClaimDigest_InsertsEmailOnlyWhenClaimed(t *testing.T) {
+	now := time.Now()
+	digest := &postgres.Email{To: "dosen1@kampus.ac.id", EventType: postgres.EmailLecturerDigest}
+
+	db, state := openFakeDB(t, "")
+	claimed, err := repoPG.NewEmailRepoPG(db).ClaimDigest("dosen-1", now.Add(-time.Hour), now, digest)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Empty(t, state.Committed())
+
+	state.Rows = map[string][]driver.Value{"RETURNING user_id": {"dosen-1"}}
+	claimed, err = repoPG.NewEmailRepoPG(db).ClaimDigest("dosen-1", now.Add(-time.Hour), now, digest)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	committed := state.Committed()
+	assert.Len(t, committed, 2)
+	assert.Contains(t, committed[1], "INSERT INTO email_outbox")
+}
+
+func TestSendDigests_ContinuesPastRecipientErrors(t *testing.T) {
+	repo := new(mocks.EmailRepo)
+	tpl, _ := mailer.LoadTemplates()
+	svc := service.NewEmailService(repo, nil, mailer.Log{}, tpl)
+	svc.DigestHour = 7
+
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.Local)
+	pending := []postgres.PendingVerification{{AchievementRefID: "ref-1", StudentName: "Budi", StudentNIM: "2101", SubmittedAt: now}}
+	repo.On("GetDigestRecipients", mock.Anything).Return([]postgres.EmailRecipient{
+		{UserID: "dosen-1", Email: "dosen1@kampus.ac.id", Locale: "id"},
+		{UserID: "dosen-2", Email: "dosen2@kampus.ac.id", Locale: "id"},
+		{UserID: "dosen-3", Email: "dosen3@kampus.ac.id", Locale: "id"},
+	}, nil)
+	repo.On("GetPendingVerifications", "dosen-1").Return(nil, errors.New("db down"))
+	repo.On("GetPendingVerifications", "dosen-2").Return(pending, nil)
+	repo.On("GetPendingVerifications", "dosen-3").Return(pending, nil)
+	repo.On("ClaimDigest", "dosen-2", mock.Anything, now, mock.Anything).Return(false, errors.New("insert failed"))
+	repo.On("ClaimDigest", "dosen-3", mock.Anything, now, mock.Anything).Return(true, nil)
+
+	n, err := svc.SendDigests(now)
+
+	// Dosen lain tetap dikirimi; yang gagal tidak diklaim agar dicoba lagi
+	assert.Equal(t, 1, n)
+	assert.ErrorContains(t, err, "dosen-1")
+	assert.ErrorContains(t, err, "dosen-2")
+	repo.AssertNotCalled(t, "ClaimDigest", "dosen-1", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationPreferences_EmailAndSettings(t *testing.T) {
+	repo := new(mocks.NotificationRepo)
+	svc := service.NewNotificationService(repo)
+	locale := "en"
+	digest := true
+	repo.On("SetPreferences", "user-123", map[string]bool(nil), map[string]bool{"achievement_verified": false}).Return(nil)
+	repo.On("SetSettings", "user-123", &locale, &digest).Return(nil)
+	email := false
+	repo.On("GetPreferences", "user-123").Return([]postgres.NotificationPreference{{EventType: "achievement_verified", InApp: true, Email: &email}}, nil)
+	repo.On("GetSettings", "user-123").Return(&postgres.NotificationSettings{Locale: "en", EmailDigest: true}, nil)
+
+	app := setupAppWithAuth(svc.UpdateNotificationPreferences)
+	app.Put("/notifications/preferences", svc.UpdateNotificationPreferences)
+	put := func(body string) (int, map[string]json.RawMessage) {
+		req := httptest.NewRequest("PUT", "/notifications/preferences", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		var out map[string]json.RawMessage
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	code, body := put(`{"email": {"achievement_verified": false}, "locale": "en", "email_digest": true}`)
+	assert.Equal(t, 200, code)
+	var prefs []postgres.NotificationPreference
+	json.Unmarshal(body["data"], &prefs)
+	for _, p := range prefs {
+		switch p.EventType {
+		case "achievement_verified":
+			assert.False(t, *p.Email)
+		case "comment_created":
+			assert.Nil(t, p.Email) // Komentar tidak dikirim lewat email
+		default:
+			assert.True(t, p.Email == nil || *p.Email, p.EventType)
+		}
+	}
+	assert.JSONEq(t, `{"locale": "en", "email_digest": true}`, string(body["settings"]))
+
+	code, _ = put(`{"email": {"comment_created": false}}`)
+	assert.Equal(t, 400, code)
+	code, _ = put(`{"locale": "fr"}`)
+	assert.Equal(t, 400, code)
+	code, _ = put(`{}`)
+	assert.Equal(t, 400, code)
+	repo.AssertNumberOfCalls(t, "SetPreferences", 1)
+	repo.AssertNumberOfCalls(t, "SetSettings", 1)
+}
@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Driver database/sql minimal untuk menguji batas transaksi repo tanpa Postgres:
+// statement dalam transaksi baru dianggap tersimpan setelah Commit
+type fakeDB struct {
+	mu        sync.Mutex
+	failOn    string
+	pending   []string
+	committed []string
+
+	// Query yang mengandung key mengembalikan satu baris berisi value; lainnya kosong
+	Rows map[string][]driver.Value
+}
+
+// Statement dalam transaksi yang belum di-commit
+func (db *fakeDB) Pending() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.pending...)
+}
+
+// Statement yang sudah di-commit
+func (db *fakeDB) Committed() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.committed...)
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// Buka *sql.DB baru; statement yang mengandung failOn mengembalikan error
+func openFakeDB(t *testing.T, failOn string) (*sql.DB, *fakeDB) {
+	state := &fakeDB{failOn: failOn}
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = state
+	fakeDBsMu.Unlock()
+
+	db, err := sql.Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDBsMu.Lock()
+		delete(fakeDBs, t.Name())
+		fakeDBsMu.Unlock()
+	})
+	return db, state
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	state, ok := fakeDBs[name]
+	if !ok {
+		return nil, errors.New("fakedb: unknown database " + name)
+	}
+	return &fakeConn{db: state}, nil
+}
+
+type fakeConn struct {
+	db   *fakeDB
+	inTx bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return &fakeTx{conn: c}, nil
+}
+
+func (c *fakeConn) record(query string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.failOn != "" && strings.Contains(query, c.db.failOn) {
+		return errors.New("fakedb: forced failure")
+	}
+	if c.inTx {
+		c.db.pending = append(c.db.pending, query)
+	} else {
+		c.db.committed = append(c.db.committed, query)
+	}
+	return nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (tx *fakeTx) Commit() error {
+	db := tx.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.committed = append(db.committed, db.pending...)
+	db.pending = nil
+	tx.conn.inTx = false
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	db := tx.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.pending = nil
+	tx.conn.inTx = false
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.conn.record(s.query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+// Tanpa baris di Rows, QueryRow -> sql.ErrNoRows
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.conn.record(s.query); err != nil {
+		return nil, err
+	}
+	s.conn.db.mu.Lock()
+	defer s.conn.db.mu.Unlock()
+	for key, row := range s.conn.db.Rows {
+		if strings.Contains(s.query, key) {
+			return &fakeRows{row: row}, nil
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return make([]string, len(r.row))
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.row == nil || r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.row)
+	return nil
+}
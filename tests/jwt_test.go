@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"be_uas/utils"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test tidak memakai JWT_KEYS_DIR: izinkan key sementara untuk seluruh package
+func TestMain(m *testing.M) {
+	os.Setenv("JWT_DEV_EPHEMERAL_KEY", "true")
+	os.Exit(m.Run())
+}
+
+func TestAccessToken_SignedWithKid(t *testing.T) {
+	access, err := utils.GenerateAccessToken("user-1", "mhs", "Mahasiswa", "role-mhs", 0)
+	assert.NoError(t, err)
+
+	claims, err := utils.ParseToken(access)
+	assert.NoError(t, err)
+	assert.Equal(t, "access", claims["typ"])
+	assert.Equal(t, "user-1", claims["user_id"])
+
+	// Header memuat kid dan bukan HS256
+	parsed, _, _ := jwt.NewParser().ParseUnverified(access, jwt.MapClaims{})
+	assert.NotEmpty(t, parsed.Header["kid"])
+	assert.NotEqual(t, "HS256", parsed.Method.Alg())
+}
+
+func TestParseToken_RejectsHS256(t *testing.T) {
+	access, _ := utils.GenerateAccessToken("user-1", "mhs", "Mahasiswa", "role-mhs", 0)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(access, jwt.MapClaims{})
+
+	// Token HMAC dengan kid yang sama tetap ditolak (algoritma dipatok)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ":     "access",
+		"user_id": "admin-1",
+		"role":    "Admin",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = parsed.Header["kid"]
+	forgedString, _ := forged.SignedString([]byte("tebakan"))
+
+	_, err := utils.ParseToken(forgedString)
+	assert.Error(t, err)
+
+	// Signature yang diubah ditolak
+	tampered := access[:strings.LastIndex(access, ".")+1] + "AAAA"
+	_, err = utils.ParseToken(tampered)
+	assert.Error(t, err)
+}
+
+func TestJWKS_ContainsActiveKey(t *testing.T) {
+	access, _ := utils.GenerateAccessToken("user-1", "mhs", "Mahasiswa", "role-mhs", 0)
+	parsed, _, _ := jwt.NewParser().ParseUnverified(access, jwt.MapClaims{})
+
+	jwks, err := utils.JWKS()
+	assert.NoError(t, err)
+
+	keys := jwks["keys"].([]map[string]interface{})
+	assert.NotEmpty(t, keys)
+
+	found := false
+	for _, k := range keys {
+		if k["kid"] == parsed.Header["kid"] {
+			found = true
+			assert.Equal(t, "sig", k["use"])
+			assert.Nil(t, k["d"]) // Private part tidak boleh ikut
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"be_uas/app/listing"
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type listingBody struct {
+	Data []struct {
+		ID     string               `json:"id"`
+		RefID  string               `json:"ref_id"`
+		Detail *mongodb.Achievement `json:"detail"`
+	} `json:"data"`
+	Meta struct {
+		Limit      int    `json:"limit"`
+		NextCursor string `json:"next_cursor"`
+	} `json:"meta"`
+	Error string `json:"error"`
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	c := listing.Cursor{SortBy: listing.SortPoints, Desc: true, Value: "50", ID: "abc"}
+
+	decoded, err := listing.DecodeCursor(c.Encode(), listing.SortPoints, true)
+	assert.NoError(t, err)
+	assert.Equal(t, c, *decoded)
+
+	// Cursor tidak boleh dipakai dengan urutan lain
+	_, err = listing.DecodeCursor(c.Encode(), listing.SortCreatedAt, true)
+	assert.ErrorIs(t, err, listing.ErrInvalidCursor)
+	_, err = listing.DecodeCursor("bukan-cursor", listing.SortPoints, true)
+	assert.ErrorIs(t, err, listing.ErrInvalidCursor)
+}
+
+func TestGetAllAchievements_FiltersAndCursor(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+	// Filter konten dijalankan di MongoDB lebih dulu
+	mockMongo.On("FindIDsByFilter", mock.Anything, mock.MatchedBy(func(f listing.Filter) bool {
+		return f.AchievementType == "competition" && *f.MinPoints == 50
+	})).Return([]string{"m-1", "m-2"}, nil)
+	// Lalu PostgreSQL mem-paging dalam scope mahasiswa sendiri
+	mockPG.On("QueryAchievements", mock.MatchedBy(func(q listing.Query) bool {
+		return q.Filter.StudentID == "student-1" &&
+			assert.ObjectsAreEqual([]string{"m-1", "m-2"}, q.Filter.MongoIDs) &&
+			assert.ObjectsAreEqual([]string{"verified"}, q.Filter.Statuses) &&
+			assert.ObjectsAreEqual([]string{"deleted"}, q.Filter.HiddenStatuses) &&
+			q.Limit == 1 && q.SortBy == listing.SortCreatedAt && q.Desc
+	})).Return([]postgres.AchievementReference{{ID: "ref-1", MongoAchievementID: "m-1"}}, "next-page", nil)
+	mockMongo.On("FindAchievementsByIDs", mock.Anything, []string{"m-1"}).Return(map[string]*mongodb.Achievement{"m-1": {Title: "Satu"}}, nil)
+
+	app := setupAppWithAuth(svc.GetAllAchievements)
+	app.Get("/achievements", svc.GetAllAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements?type=competition&min_points=50&status=verified&limit=1&student_id=lain", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body listingBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, "next-page", body.Meta.NextCursor)
+	mockPG.AssertExpectations(t)
+	mockMongo.AssertExpectations(t)
+}
+
+func TestGetAdviseesAchievements_SortByPoints(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	// Scope dosen wali dijalankan di PostgreSQL, urutan poin di MongoDB
+	mockPG.On("FindReferencesByFilterLimit", mock.MatchedBy(func(f listing.Filter) bool {
+		return f.AdvisorUserID == "dosen-uuid-123" && assert.ObjectsAreEqual([]string{"draft", "deleted"}, f.HiddenStatuses)
+	}), mock.Anything).Return([]postgres.AchievementReference{
+		{ID: "ref-1", MongoAchievementID: "650000000000000000000001"},
+		{ID: "ref-2", MongoAchievementID: "650000000000000000000002"},
+	}, nil)
+	id1, _ := primitive.ObjectIDFromHex("650000000000000000000001")
+	id2, _ := primitive.ObjectIDFromHex("650000000000000000000002")
+	mockMongo.On("QueryAchievements", mock.Anything, mock.MatchedBy(func(q listing.Query) bool {
+		return q.SortBy == listing.SortPoints && q.Desc
+	}), []string{"650000000000000000000001", "650000000000000000000002"}).Return([]mongodb.Achievement{
+		{ID: id2, Points: 90},
+		{ID: id1, Points: 20},
+	}, "", nil)
+
+	app := setupAppWithDosenAuth(svc.GetAdviseesAchievements)
+	app.Get("/achievements/advisees", svc.GetAdviseesAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/advisees?sort=points", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body listingBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, "ref-2", body.Data[0].RefID)
+	assert.Equal(t, 90, body.Data[0].Detail.Points)
+	assert.Equal(t, "ref-1", body.Data[1].RefID)
+	assert.Empty(t, body.Meta.NextCursor)
+}
+
+func TestGetAdviseesAchievements_LargeScopeScansMongoInSortOrder(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	// Scope melebihi batas daftar ID: tidak dikirim sebagai $in
+	mockPG.On("FindReferencesByFilterLimit", mock.Anything, mock.Anything).Return(make([]postgres.AchievementReference, 2001), nil)
+	docs := make([]mongodb.Achievement, 4)
+	for i := range docs {
+		docs[i] = mongodb.Achievement{ID: primitive.NewObjectID(), Points: 100 - i*10}
+	}
+	mockMongo.On("QueryAchievements", mock.Anything, mock.MatchedBy(func(q listing.Query) bool {
+		return q.SortBy == listing.SortPoints && q.After == nil
+	}), []string(nil)).Return(docs, "", nil)
+	// Dokumen ke-2 milik mahasiswa di luar bimbingan
+	mockPG.On("FindReferencesByFilter", mock.MatchedBy(func(f listing.Filter) bool {
+		return f.AdvisorUserID == "dosen-uuid-123" && len(f.MongoIDs) == 4
+	})).Return([]postgres.AchievementReference{
+		{ID: "ref-0", MongoAchievementID: docs[0].ID.Hex()},
+		{ID: "ref-2", MongoAchievementID: docs[2].ID.Hex()},
+		{ID: "ref-3", MongoAchievementID: docs[3].ID.Hex()},
+	}, nil)
+
+	app := setupAppWithDosenAuth(svc.GetAdviseesAchievements)
+	app.Get("/achievements/advisees", svc.GetAdviseesAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/advisees?sort=points&limit=2", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body listingBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, "ref-0", body.Data[0].RefID)
+	assert.Equal(t, "ref-2", body.Data[1].RefID)
+	cursor, err := listing.DecodeCursor(body.Meta.NextCursor, listing.SortPoints, true)
+	assert.NoError(t, err)
+	assert.Equal(t, docs[2].ID.Hex(), cursor.ID)
+	assert.Equal(t, "80", cursor.Value)
+}
+
+func TestGetAllAchievements_InvalidQuery(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+
+	app := setupAppWithAuth(svc.GetAllAchievements)
+	app.Get("/achievements", svc.GetAllAchievements)
+
+	// Cursor dari sort lain, sort tidak dikenal, status tidak dikenal
+	cursor := listing.Cursor{SortBy: listing.SortPoints, Desc: true, Value: "10", ID: "x"}.Encode()
+	for _, url := range []string{
+		"/achievements?cursor=" + cursor,
+		"/achievements?sort=nama",
+		"/achievements?status=arsip",
+	} {
+		resp, _ := app.Test(httptest.NewRequest("GET", url, nil))
+		assert.Equal(t, 400, resp.StatusCode, url)
+	}
+	mockPG.AssertNotCalled(t, "QueryAchievements", mock.Anything)
+}
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"be_uas/utils"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func loginRequest(app *fiber.App, username, password string) *http.Response {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestLogin_LocksAccountAfterMaxFailures(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByUsername", "mahasiswa_test").Return(&postgres.User{
+		ID: "user-1", Username: "mahasiswa_test", PasswordHash: string(hashed), IsActive: true,
+	}, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
+	// Percobaan ke-5 -> akun dikunci 1 menit
+	mockUserRepo.On("RecordFailedLogin", "user-1").Return(service.MaxFailedLogins, nil)
+	mockUserRepo.On("LockUser", "user-1", mock.MatchedBy(func(until time.Time) bool {
+		d := time.Until(until)
+		return d > 50*time.Second && d <= time.Minute
+	})).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "mahasiswa_test", "salah")
+
+	assert.Equal(t, 401, resp.StatusCode)
+	mockUserRepo.AssertExpectations(t)
+	mockAuditRepo.AssertCalled(t, "CreateAuthAudit", mock.MatchedBy(func(l postgres.AuthAuditLog) bool {
+		return l.Event == postgres.AuditAccountLocked && *l.UserID == "user-1"
+	}))
+}
+
+func TestLogin_LockedAccountRejectedEvenWithCorrectPassword(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	lockedUntil := time.Now().Add(2 * time.Minute)
+	mockUserRepo.On("GetByUsername", "mahasiswa_test").Return(&postgres.User{
+		ID: "user-1", PasswordHash: string(hashed), IsActive: true, FailedLoginAttempts: 5, LockedUntil: &lockedUntil,
+	}, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.MatchedBy(func(l postgres.AuthAuditLog) bool {
+		return l.Event == postgres.AuditLoginLocked
+	})).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "mahasiswa_test", "rahasia123")
+
+	// Sama persis dengan password salah: status kunci tidak terlihat dari luar
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "Invalid credentials", body["message"])
+	assert.Empty(t, resp.Header.Get("Retry-After"))
+	mockAuditRepo.AssertExpectations(t)
+
+	// Password salah selama terkunci tidak memperpanjang kunci
+	resp = loginRequest(app, "mahasiswa_test", "salah")
+	assert.Equal(t, 401, resp.StatusCode)
+	mockUserRepo.AssertNotCalled(t, "RecordFailedLogin", mock.Anything)
+}
+
+// Username tidak terdaftar tetap melewati pengecekan password (hash dummy), respon sama dengan password salah
+func TestLogin_UnknownUserRunsPasswordCheck(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
+
+	var checked []string
+	authService.CheckPassword = func(hash, password string) bool {
+		checked = append(checked, hash)
+		return utils.CheckPassword(hash, password)
+	}
+	mockUserRepo.On("GetByUsername", "tidak_ada").Return(nil, sql.ErrNoRows)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "tidak_ada", "rahasia123")
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 401, resp.StatusCode)
+	assert.Equal(t, "Invalid credentials", body["message"])
+	assert.Equal(t, []string{utils.DummyPasswordHash()}, checked)
+	// Cost sama dengan hash password asli
+	cost, err := bcrypt.Cost([]byte(utils.DummyPasswordHash()))
+	assert.NoError(t, err)
+	hashed, _ := utils.HashPassword("x")
+	realCost, _ := bcrypt.Cost([]byte(hashed))
+	assert.Equal(t, realCost, cost)
+}
+
+func TestLogin_IPBackoff(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
+
+	// Gagal terakhir barusan, sudah melewati batas per IP
+	last := time.Now()
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(service.MaxIPFailures+2, &last, nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.MatchedBy(func(l postgres.AuthAuditLog) bool {
+		return l.Event == postgres.AuditLoginIPBlocked && l.Username == "mahasiswa_test"
+	})).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "mahasiswa_test", "apa-saja")
+
+	assert.Equal(t, 429, resp.StatusCode)
+	// User tidak pernah dicek selama IP di-backoff
+	mockUserRepo.AssertNotCalled(t, "GetByUsername", mock.Anything)
+}
+
+func TestLogin_SuccessResetsFailedAttempts(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("rahasia123"), bcrypt.MinCost)
+	expired := time.Now().Add(-time.Minute)
+	mockUserRepo.On("GetByUsername", "mahasiswa_test").Return(&postgres.User{
+		ID: "user-1", PasswordHash: string(hashed), IsActive: true, FailedLoginAttempts: 5, LockedUntil: &expired,
+	}, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	mockUserRepo.On("ResetFailedLogins", "user-1").Return(nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "mahasiswa_test", "rahasia123")
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockUserRepo.AssertExpectations(t)
+}
+
+func TestUnlockUser(t *testing.T) {
+	mockUser := new(mocks.UserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	svc := service.NewAdminService(mockUser, new(mocks.AchievementRepoPG), new(mocks.AchievementRepoMongo), new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
+
+	mockUser.On("ResetFailedLogins", "user-1").Return(nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.MatchedBy(func(l postgres.AuthAuditLog) bool {
+		return l.Event == postgres.AuditAccountUnlocked && *l.UserID == "user-1" && *l.ActorID == "admin-uuid-001"
+	})).Return(nil)
+
+	app := setupAppWithAdminAuth(svc.UnlockUser)
+	app.Post("/users/:id/unlock", svc.UnlockUser)
+	resp, _ := app.Test(httptest.NewRequest("POST", "/users/user-1/unlock", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockUser.AssertExpectations(t)
+	mockAuditRepo.AssertExpectations(t)
+}
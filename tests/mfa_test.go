@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"be_uas/utils"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Secret ASCII "12345678901234567890" dari test vector RFC 6238
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_RFC6238Vector(t *testing.T) {
+	code, err := utils.TOTPCode(rfcSecret, utils.TOTPStep(time.Unix(59, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	step, ok := utils.VerifyTOTP(rfcSecret, "287082", time.Unix(59, 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+
+	// Di luar toleransi skew
+	_, ok = utils.VerifyTOTP(rfcSecret, "287082", time.Unix(59+3*utils.TOTPPeriod, 0))
+	assert.False(t, ok)
+}
+
+func TestTOTP_ProvisioningURIAndRecoveryCodes(t *testing.T) {
+	uri := utils.TOTPProvisioningURI("Sistem Prestasi", "admin_super", rfcSecret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/"))
+	assert.Contains(t, uri, "secret="+rfcSecret)
+
+	codes, hashes, err := utils.GenerateRecoveryCodes(3)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 3)
+	// Input user dinormalisasi sebelum di-hash
+	assert.Equal(t, hashes[0], utils.HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+}
+
+func TestLogin_TOTPEnabledReturnsChallenge(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByUsername", "admin_super").Return(&postgres.User{
+		ID: "admin-1", Username: "admin_super", PasswordHash: string(hashed), IsActive: true, TOTPEnabled: true,
+	}, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "admin_super", "Rahasia123")
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body struct {
+		Status string `json:"status"`
+		Data   struct {
+			MFAToken string `json:"mfa_token"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "mfa_required", body.Status)
+
+	claims, err := utils.ParseToken(body.Data.MFAToken)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.TokenTypeMFA, claims["typ"])
+	// Belum ada refresh token sebelum kode diverifikasi
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+// PUT role tanpa require_2fa tidak boleh mematikan policy 2FA yang sudah aktif
+func TestUpdateRole_Require2FAOnlyWhenSent(t *testing.T) {
+	roleRepo := new(mocks.RoleRepo)
+	svc := service.NewRBACService(roleRepo)
+	roleRepo.On("UpdateRole", "role-1", mock.Anything).Return(nil)
+
+	app := fiber.New()
+	app.Put("/roles/:id", svc.UpdateRole)
+	put := func(body string) int {
+		req := httptest.NewRequest("PUT", "/roles/role-1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, put(`{"name":"Dosen Wali","description":"Pembimbing akademik"}`))
+	assert.Equal(t, 200, put(`{"name":"Dosen Wali","require_2fa":false}`))
+
+	omitted := roleRepo.Calls[0].Arguments.Get(1).(postgres.UpdateRoleRequest)
+	assert.Nil(t, omitted.Require2FA)
+	assert.Equal(t, "Pembimbing akademik", omitted.Description)
+	sent := roleRepo.Calls[1].Arguments.Get(1).(postgres.UpdateRoleRequest)
+	if assert.NotNil(t, sent.Require2FA) {
+		assert.False(t, *sent.Require2FA)
+	}
+}
+
+func TestLogin_Require2FAWithoutEnrolment(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByUsername", "dosen").Return(&postgres.User{
+		ID: "dosen-1", PasswordHash: string(hashed), IsActive: true, Require2FA: true,
+	}, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "dosen", "Rahasia123")
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "mfa_enrollment_required", body["status"])
+}
+
+func setupMFALogin(t *testing.T) (*service.AuthService, *mocks.UserRepo, *mocks.TokenRepo, *mocks.MFARepo, *mocks.AuditRepo, string) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockMFARepo := new(mocks.MFARepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, mockMFARepo)
+
+	mfaToken, err := utils.GenerateMFAToken(utils.TokenTypeMFA, "admin-1", "admin_super", "Admin", "role-admin", 0)
+	assert.NoError(t, err)
+
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+	mockTokenRepo.On("GetSessionState", "admin-1", mock.Anything).Return(&postgres.SessionState{IsActive: true}, nil)
+	mockUserRepo.On("GetUserByID", "admin-1").Return(&postgres.User{ID: "admin-1", Username: "admin_super", IsActive: true, TOTPEnabled: true}, nil)
+	mockMFARepo.On("GetTOTPState", "admin-1").Return(&postgres.TOTPState{Secret: rfcSecret, Enabled: true}, nil)
+	return authService, mockUserRepo, mockTokenRepo, mockMFARepo, mockAuditRepo, mfaToken
+}
+
+func TestVerifyLogin2FA_Success(t *testing.T) {
+	authService, _, mockTokenRepo, mockMFARepo, _, mfaToken := setupMFALogin(t)
+
+	now := time.Now()
+	code, _ := utils.TOTPCode(rfcSecret, utils.TOTPStep(now))
+	mockMFARepo.On("MarkTOTPStepUsed", "admin-1", mock.Anything).Return(true, nil)
+	// MFA token langsung dicabut agar tidak bisa dipakai ulang
+	mockTokenRepo.On("RevokeToken", mock.Anything, "admin-1", mock.Anything).Return(nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login/2fa", authService.VerifyLogin2FA)
+	resp := postJSON(app, "/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": code})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body postgres.LoginResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.NotEmpty(t, body.Data.Token)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestVerifyLogin2FA_RecoveryCode(t *testing.T) {
+	authService, _, mockTokenRepo, mockMFARepo, _, mfaToken := setupMFALogin(t)
+
+	mockMFARepo.On("UseRecoveryCode", "admin-1", utils.HashRecoveryCode("abcde-fghij")).Return(true, nil)
+	mockTokenRepo.On("RevokeToken", mock.Anything, "admin-1", mock.Anything).Return(nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login/2fa", authService.VerifyLogin2FA)
+	resp := postJSON(app, "/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": "ABCDE-FGHIJ"})
+
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func TestVerifyLogin2FA_WrongCodeCountsAsFailedLogin(t *testing.T) {
+	authService, mockUserRepo, mockTokenRepo, mockMFARepo, mockAuditRepo, mfaToken := setupMFALogin(t)
+
+	mockMFARepo.On("UseRecoveryCode", "admin-1", mock.Anything).Return(false, nil)
+	mockUserRepo.On("RecordFailedLogin", "admin-1").Return(1, nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/login/2fa", authService.VerifyLogin2FA)
+	resp := postJSON(app, "/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": "000000x"})
+
+	assert.Equal(t, 401, resp.StatusCode)
+	mockUserRepo.AssertCalled(t, "RecordFailedLogin", "admin-1")
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestVerifyLogin2FA_RejectsAccessToken(t *testing.T) {
+	authService := service.NewAuthService(new(mocks.UserRepo), new(mocks.TokenRepo), new(mocks.AuditRepo), new(mocks.MFARepo))
+	mockAudit := authService.AuditRepo.(*mocks.AuditRepo)
+	mockAudit.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+
+	access, _ := utils.GenerateAccessToken("admin-1", "admin_super", "Admin", "role-admin", 0)
+
+	app := fiber.New()
+	app.Post("/auth/login/2fa", authService.VerifyLogin2FA)
+	resp := postJSON(app, "/auth/login/2fa", map[string]string{"mfa_token": access, "code": "123456"})
+
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestEnableTOTP_WithEnrollmentTokenIssuesSession(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockMFARepo := new(mocks.MFARepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, mockMFARepo)
+
+	code, _ := utils.TOTPCode(rfcSecret, utils.TOTPStep(time.Now()))
+	mockMFARepo.On("GetTOTPState", "dosen-1").Return(&postgres.TOTPState{Secret: rfcSecret}, nil)
+	mockMFARepo.On("MarkTOTPStepUsed", "dosen-1", mock.Anything).Return(true, nil)
+	mockMFARepo.On("EnableTOTP", "dosen-1", mock.MatchedBy(func(h []string) bool {
+		return len(h) == service.RecoveryCodeCount
+	})).Return(nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
+	mockTokenRepo.On("RevokeToken", "enroll-jti", "dosen-1", mock.Anything).Return(nil)
+	mockUserRepo.On("GetUserByID", "dosen-1").Return(&postgres.User{ID: "dosen-1", IsActive: true, TOTPEnabled: true}, nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
+
+	// Simulasi MFAEnrollmentAuth dengan token enrolment
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "dosen-1")
+		c.Locals("jti", "enroll-jti")
+		c.Locals("token_typ", utils.TokenTypeMFAEnroll)
+		return c.Next()
+	})
+	app.Post("/auth/2fa/enable", authService.EnableTOTP)
+	resp := postJSON(app, "/auth/2fa/enable", map[string]string{"code": code})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body struct {
+		Data struct {
+			RecoveryCodes []string `json:"recovery_codes"`
+			Token         string   `json:"token"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data.RecoveryCodes, service.RecoveryCodeCount)
+	assert.NotEmpty(t, body.Data.Token)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestDisableTOTP_BlockedWhenRoleRequires2FA(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockMFARepo := new(mocks.MFARepo)
+	authService := service.NewAuthService(mockUserRepo, new(mocks.TokenRepo), new(mocks.AuditRepo), mockMFARepo)
+
+	mockUserRepo.On("GetUserByID", "user-123").Return(&postgres.User{ID: "user-123", Require2FA: true, TOTPEnabled: true}, nil)
+
+	app := setupAppWithAuth(authService.DisableTOTP)
+	app.Post("/auth/2fa/disable", authService.DisableTOTP)
+	resp := postJSON(app, "/auth/2fa/disable", map[string]string{"password": "Rahasia123", "code": "123456"})
+
+	assert.Equal(t, 403, resp.StatusCode)
+	mockMFARepo.AssertNotCalled(t, "DisableTOTP", mock.Anything)
+}
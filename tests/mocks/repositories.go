@@ -1,9 +1,12 @@
 package mocks
 
 import (
+	"be_uas/app/listing"
 	"be_uas/app/model/mongodb"
 	"be_uas/app/model/postgres"
 	"context"
+	"io"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -28,7 +31,14 @@ func (m *UserRepo) CreateUser(user postgres.User) error {
 }
 
 func (m *UserRepo) GetAllUsers() ([]postgres.User, error)           { return nil, nil }
-func (m *UserRepo) GetUserByID(id string) (*postgres.User, error)   { return nil, nil }
+func (m *UserRepo) GetUserByID(id string) (*postgres.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.User), args.Error(1)
+}
+
 func (m *UserRepo) UpdateUser(user postgres.User) error             { return nil }
 func (m *UserRepo) DeleteUser(id string) error                      { return nil }
 
@@ -44,13 +54,271 @@ func (m *UserRepo) GetStudentIDByUserID(userID string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *UserRepo) RecordFailedLogin(userID string) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *UserRepo) LockUser(userID string, until time.Time) error {
+	args := m.Called(userID, until)
+	return args.Error(0)
+}
+
+func (m *UserRepo) ResetFailedLogins(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *UserRepo) GetPasswordHash(userID string) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+// MOCK AUDIT REPO
+type AuditRepo struct {
+	mock.Mock
+}
+
+func (m *AuditRepo) CreateAuthAudit(log postgres.AuthAuditLog) error {
+	args := m.Called(log)
+	return args.Error(0)
+}
+
+func (m *AuditRepo) GetRecentFailuresByIP(ip string, since time.Time) (int, *time.Time, error) {
+	args := m.Called(ip, since)
+	if args.Get(1) == nil {
+		return args.Int(0), nil, args.Error(2)
+	}
+	return args.Int(0), args.Get(1).(*time.Time), args.Error(2)
+}
+
+func (m *AuditRepo) GetAuthAuditByUserID(userID string, limit int) ([]postgres.AuthAuditLog, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.AuthAuditLog), args.Error(1)
+}
+
+// MOCK MFA REPO
+type MFARepo struct {
+	mock.Mock
+}
+
+func (m *MFARepo) GetTOTPState(userID string) (*postgres.TOTPState, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.TOTPState), args.Error(1)
+}
+
+func (m *MFARepo) SetPendingTOTPSecret(userID, secret string) error {
+	args := m.Called(userID, secret)
+	return args.Error(0)
+}
+
+func (m *MFARepo) EnableTOTP(userID string, recoveryHashes []string) error {
+	args := m.Called(userID, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *MFARepo) DisableTOTP(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *MFARepo) ReplaceRecoveryCodes(userID string, recoveryHashes []string) error {
+	args := m.Called(userID, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *MFARepo) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	args := m.Called(userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MFARepo) MarkTOTPStepUsed(userID string, step int64) (bool, error) {
+	args := m.Called(userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+// MOCK TOKEN REPO
+type TokenRepo struct {
+	mock.Mock
+}
+
+func (m *TokenRepo) RevokeToken(jti, userID string, expiresAt time.Time) error {
+	args := m.Called(jti, userID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *TokenRepo) RevokeAllUserSessions(userID string) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *TokenRepo) GetSessionState(userID, jti string) (*postgres.SessionState, error) {
+	args := m.Called(userID, jti)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.SessionState), args.Error(1)
+}
+
+func (m *TokenRepo) DeleteExpiredTokens() (int64, error) {
+	args := m.Called()
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *TokenRepo) CreateRefreshToken(rt postgres.RefreshToken) error {
+	args := m.Called(rt)
+	return args.Error(0)
+}
+
+func (m *TokenRepo) GetRefreshTokenByHash(hash string) (*postgres.RefreshToken, error) {
+	args := m.Called(hash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.RefreshToken), args.Error(1)
+}
+
+func (m *TokenRepo) MarkRefreshTokenUsed(id string) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *TokenRepo) RevokeRefreshTokenFamily(familyID string) error {
+	args := m.Called(familyID)
+	return args.Error(0)
+}
+
+func (m *TokenRepo) ChangePassword(userID, newPasswordHash string) error {
+	args := m.Called(userID, newPasswordHash)
+	return args.Error(0)
+}
+
+func (m *TokenRepo) CreatePasswordResetToken(t postgres.PasswordResetToken) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *TokenRepo) ConsumePasswordResetToken(tokenHash, newPasswordHash string) (string, error) {
+	args := m.Called(tokenHash, newPasswordHash)
+	return args.String(0), args.Error(1)
+}
+
+// MOCK ACHIEVEMENT TYPE REPO
+type AchievementTypeRepo struct {
+	mock.Mock
+}
+
+func (m *AchievementTypeRepo) GetAllTypes(includeInactive bool) ([]postgres.AchievementType, error) {
+	args := m.Called(includeInactive)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.AchievementType), args.Error(1)
+}
+
+func (m *AchievementTypeRepo) GetTypeByCode(code string) (*postgres.AchievementType, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.AchievementType), args.Error(1)
+}
+
+func (m *AchievementTypeRepo) CreateType(t postgres.AchievementType) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *AchievementTypeRepo) UpdateType(t postgres.AchievementType) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+func (m *AchievementTypeRepo) DeactivateType(code string) error {
+	args := m.Called(code)
+	return args.Error(0)
+}
+
+// MOCK POINT RULE REPO
+type PointRuleRepo struct {
+	mock.Mock
+}
+
+func (m *PointRuleRepo) GetRuleSets() ([]postgres.PointRuleSet, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.PointRuleSet), args.Error(1)
+}
+
+func (m *PointRuleRepo) GetRuleSetByVersion(version int) (*postgres.PointRuleSet, error) {
+	args := m.Called(version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.PointRuleSet), args.Error(1)
+}
+
+func (m *PointRuleRepo) GetActiveRuleSet() (*postgres.PointRuleSet, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.PointRuleSet), args.Error(1)
+}
+
+func (m *PointRuleRepo) CreateRuleSet(rs postgres.PointRuleSet) (int, error) {
+	args := m.Called(rs)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *PointRuleRepo) ActivateRuleSet(version int) error {
+	args := m.Called(version)
+	return args.Error(0)
+}
+
+// MOCK OUTBOX REPO
+type OutboxRepo struct {
+	mock.Mock
+}
+
+func (m *OutboxRepo) ClaimEvents(aggregateID string, limit int) ([]postgres.OutboxEvent, error) {
+	args := m.Called(aggregateID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.OutboxEvent), args.Error(1)
+}
+
+func (m *OutboxRepo) MarkEventDone(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *OutboxRepo) MarkEventFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error {
+	args := m.Called(id, lastError, nextAttemptAt, dead)
+	return args.Error(0)
+}
+
+func (m *OutboxRepo) Enqueue(aggregateID, eventType string, payload interface{}) error {
+	args := m.Called(aggregateID, eventType, payload)
+	return args.Error(0)
+}
+
 // MOCK ACHIEVEMENT REPO (POSTGRES)
 type AchievementRepoPG struct {
 	mock.Mock
 }
 
-func (m *AchievementRepoPG) CreateReference(ref postgres.AchievementReference) error {
-	args := m.Called(ref)
+func (m *AchievementRepoPG) CreateReference(ref postgres.AchievementReference, actorID string) error {
+	args := m.Called(ref, actorID)
 	return args.Error(0)
 }
 
@@ -62,43 +330,109 @@ func (m *AchievementRepoPG) GetReferenceByID(id string) (*postgres.AchievementRe
 	return args.Get(0).(*postgres.AchievementReference), args.Error(1)
 }
 
-func (m *AchievementRepoPG) UpdateStatus(id, status string) error {
+func (m *AchievementRepoPG) UpdateStatus(id, fromStatus, status, actorID string, check func() error) error {
+	args := m.Called(id, fromStatus, status, actorID)
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return args.Error(0)
+}
+
+func (m *AchievementRepoPG) GetStudentIDByUserID(userID string) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *AchievementRepoPG) GetLecturerIDByUserID(userID string) (string, error) {
+	args := m.Called(userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *AchievementRepoPG) IsStudentAdvisedBy(studentID, userID string) (bool, error) {
+	args := m.Called(studentID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *AchievementRepoPG) UpdateVerification(id, fromStatus, status, verifiedBy string, rejectionNote *string, points *postgres.PointsSnapshot) error {
+	args := m.Called(id, fromStatus, status, verifiedBy, rejectionNote, points)
+	return args.Error(0)
+}
+
+// Edit dijalankan jika mock tidak mengembalikan error (mis. StatusConflictError)
+func (m *AchievementRepoPG) LockForEdit(id, status string, edit func() error) error {
 	args := m.Called(id, status)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return edit()
+}
+
+func (m *AchievementRepoPG) GetStatusHistory(refID string, limit, offset int) ([]postgres.AchievementStatusHistory, int, error) {
+	args := m.Called(refID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]postgres.AchievementStatusHistory), args.Int(1), args.Error(2)
+}
+
+func (m *AchievementRepoPG) RequestRevision(id, fromStatus, reviewerID string, note *string, comments []postgres.RevisionCommentInput) error {
+	args := m.Called(id, fromStatus, reviewerID, note, comments)
 	return args.Error(0)
 }
 
-func (m *AchievementRepoPG) GetAchievementsByAdvisorID(advisorID string) ([]postgres.AchievementReference, error) {
-	args := m.Called(advisorID)
+func (m *AchievementRepoPG) GetRevisionComments(refID string) ([]postgres.RevisionComment, error) {
+	args := m.Called(refID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]postgres.AchievementReference), args.Error(1)
+	return args.Get(0).([]postgres.RevisionComment), args.Error(1)
 }
 
-func (m *AchievementRepoPG) GetAchievementsByStudentID(studentID string) ([]postgres.AchievementReference, error) {
-	args := m.Called(studentID)
+func (m *AchievementRepoPG) QueryAchievements(q listing.Query) ([]postgres.AchievementReference, string, error) {
+	args := m.Called(q)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]postgres.AchievementReference), args.String(1), args.Error(2)
+}
+
+func (m *AchievementRepoPG) FindReferencesByFilterLimit(f listing.Filter, limit int) ([]postgres.AchievementReference, error) {
+	args := m.Called(f, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]postgres.AchievementReference), args.Error(1)
 }
 
-func (m *AchievementRepoPG) GetStudentIDByUserID(userID string) (string, error) {
-	args := m.Called(userID)
-	return args.String(0), args.Error(1)
+func (m *AchievementRepoPG) FindReferencesByFilter(f listing.Filter) ([]postgres.AchievementReference, error) {
+	args := m.Called(f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.AchievementReference), args.Error(1)
 }
 
-func (m *AchievementRepoPG) UpdateVerification(id string, status string, verifiedBy string, rejectionNote *string) error {
-	args := m.Called(id, status, verifiedBy, rejectionNote)
-	return args.Error(0)
+func (m *AchievementRepoPG) ListReferenceLinks(afterID string, limit int) ([]postgres.AchievementReference, error) {
+	args := m.Called(afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.AchievementReference), args.Error(1)
 }
 
-func (m *AchievementRepoPG) GetAllAchievements(limit, offset int) ([]postgres.AchievementReference, int, error) {
-	args := m.Called(limit, offset)
+func (m *AchievementRepoPG) FindReferencedMongoIDs(mongoIDs []string) (map[string]bool, error) {
+	args := m.Called(mongoIDs)
 	if args.Get(0) == nil {
-		return nil, args.Int(1), args.Error(2)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).([]postgres.AchievementReference), args.Int(1), args.Error(2)
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *AchievementRepoPG) MarkReferenceDeleted(id, note string) error {
+	args := m.Called(id, note)
+	return args.Error(0)
 }
 
 // Dummy methods
@@ -127,16 +461,465 @@ func (m *AchievementRepoMongo) InsertAchievement(ctx context.Context, data mongo
 	return args.String(0), args.Error(1)
 }
 
-// Dummy methods
+func (m *AchievementRepoMongo) FindAchievementsByIDs(ctx context.Context, hexIDs []string) (map[string]*mongodb.Achievement, error) {
+	args := m.Called(ctx, hexIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*mongodb.Achievement), args.Error(1)
+}
+
+func (m *AchievementRepoMongo) FindIDsByFilter(ctx context.Context, f listing.Filter) ([]string, error) {
+	args := m.Called(ctx, f)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *AchievementRepoMongo) QueryAchievements(ctx context.Context, q listing.Query, hexIDs []string) ([]mongodb.Achievement, string, error) {
+	args := m.Called(ctx, q, hexIDs)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]mongodb.Achievement), args.String(1), args.Error(2)
+}
+
+func (m *AchievementRepoMongo) SearchAchievements(ctx context.Context, text string, hexIDs []string, skip, limit int) ([]mongodb.AchievementSearchHit, error) {
+	args := m.Called(ctx, text, hexIDs, skip, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]mongodb.AchievementSearchHit), args.Error(1)
+}
+
 func (m *AchievementRepoMongo) AddAttachment(ctx context.Context, hexID string, attachment mongodb.Attachment) error {
-	return nil
+	args := m.Called(ctx, hexID, attachment)
+	return args.Error(0)
 }
+
+func (m *AchievementRepoMongo) RemoveAttachment(ctx context.Context, hexID, attachmentID string) error {
+	args := m.Called(ctx, hexID, attachmentID)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) ReplaceAttachment(ctx context.Context, hexID, attachmentID string, attachment mongodb.Attachment) error {
+	args := m.Called(ctx, hexID, attachmentID, attachment)
+	return args.Error(0)
+}
+
+// Dummy methods
 func (m *AchievementRepoMongo) FindAchievementByID(ctx context.Context, hexID string) (*mongodb.Achievement, error) {
-	return nil, nil
+	args := m.Called(ctx, hexID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*mongodb.Achievement), args.Error(1)
 }
 func (m *AchievementRepoMongo) UpdateAchievement(ctx context.Context, hexID string, data mongodb.Achievement) error {
 	return nil
 }
+
+func (m *AchievementRepoMongo) SetPoints(ctx context.Context, hexID string, points, ruleVersion int) error {
+	args := m.Called(ctx, hexID, points, ruleVersion)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) FindPendingScans(ctx context.Context, limit int) ([]mongodb.PendingAttachment, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]mongodb.PendingAttachment), args.Error(1)
+}
+
+func (m *AchievementRepoMongo) FindPendingPreviews(ctx context.Context, limit int) ([]mongodb.PendingAttachment, error) {
+	args := m.Called(ctx, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]mongodb.PendingAttachment), args.Error(1)
+}
+
+func (m *AchievementRepoMongo) SetPreview(ctx context.Context, hexID, attachmentID string, preview mongodb.AttachmentPreview) error {
+	args := m.Called(ctx, hexID, attachmentID, preview)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) SetScanResult(ctx context.Context, hexID, attachmentID, status, signature, storageKey string) error {
+	args := m.Called(ctx, hexID, attachmentID, status, signature, storageKey)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) RecordScanFailure(ctx context.Context, hexID, attachmentID, lastError string, nextAttemptAt time.Time, dead bool) error {
+	args := m.Called(ctx, hexID, attachmentID, lastError, nextAttemptAt, dead)
+	return args.Error(0)
+}
+
 func (m *AchievementRepoMongo) SoftDeleteAchievement(ctx context.Context, hexID string) error {
-	return nil
+	args := m.Called(ctx, hexID)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) DeleteAchievement(ctx context.Context, hexID string) error {
+	args := m.Called(ctx, hexID)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) RestoreAchievement(ctx context.Context, hexID string) error {
+	args := m.Called(ctx, hexID)
+	return args.Error(0)
+}
+
+func (m *AchievementRepoMongo) FindDeletionStates(ctx context.Context, hexIDs []string) (map[string]bool, error) {
+	args := m.Called(ctx, hexIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *AchievementRepoMongo) ListActiveIDsBefore(ctx context.Context, cutoff time.Time, afterHexID string, limit int) ([]string, error) {
+	args := m.Called(ctx, cutoff, afterHexID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MOCK STORAGE
+type Storage struct {
+	mock.Mock
+}
+
+func (m *Storage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, body, size, contentType)
+	return args.Error(0)
+}
+
+func (m *Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *Storage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// MOCK COMMENT REPO
+type CommentRepo struct {
+	mock.Mock
+}
+
+func (m *CommentRepo) ListComments(refID string) ([]postgres.AchievementComment, error) {
+	args := m.Called(refID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.AchievementComment), args.Error(1)
+}
+
+func (m *CommentRepo) GetComment(refID, id string) (*postgres.AchievementComment, error) {
+	args := m.Called(refID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.AchievementComment), args.Error(1)
+}
+
+func (m *CommentRepo) CreateComment(cm postgres.AchievementComment, mentionIDs []string) (*postgres.AchievementComment, error) {
+	args := m.Called(cm, mentionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.AchievementComment), args.Error(1)
+}
+
+func (m *CommentRepo) UpdateComment(id, body string, mentionIDs []string) error {
+	args := m.Called(id, body, mentionIDs)
+	return args.Error(0)
+}
+
+func (m *CommentRepo) DeleteComment(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *CommentRepo) FindMentionableUsers(refID string, usernames []string) ([]postgres.CommentMention, error) {
+	args := m.Called(refID, usernames)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.CommentMention), args.Error(1)
+}
+
+// MOCK NOTIFICATION REPO
+type NotificationRepo struct {
+	mock.Mock
+}
+
+func (m *NotificationRepo) CreateNotifications(ns []postgres.Notification) error {
+	args := m.Called(ns)
+	return args.Error(0)
+}
+
+func (m *NotificationRepo) GetAchievementParticipants(refID string) (*postgres.NotificationParticipants, error) {
+	args := m.Called(refID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.NotificationParticipants), args.Error(1)
+}
+
+func (m *NotificationRepo) GetStudentParticipants(studentID string) (*postgres.NotificationParticipants, error) {
+	args := m.Called(studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.NotificationParticipants), args.Error(1)
+}
+
+func (m *NotificationRepo) ListNotifications(userID string, unreadOnly bool, limit, offset int) ([]postgres.Notification, int, error) {
+	args := m.Called(userID, unreadOnly, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]postgres.Notification), args.Int(1), args.Error(2)
+}
+
+func (m *NotificationRepo) CountUnread(userID string) (int, error) {
+	args := m.Called(userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *NotificationRepo) MarkRead(userID string, ids []string) (int64, error) {
+	args := m.Called(userID, ids)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *NotificationRepo) MarkAllRead(userID string) (int64, error) {
+	args := m.Called(userID)
+	return int64(args.Int(0)), args.Error(1)
+}
+
+func (m *NotificationRepo) GetPreferences(userID string) ([]postgres.NotificationPreference, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.NotificationPreference), args.Error(1)
+}
+
+func (m *NotificationRepo) SetPreferences(userID string, inApp, email map[string]bool) error {
+	args := m.Called(userID, inApp, email)
+	return args.Error(0)
+}
+
+func (m *NotificationRepo) GetSettings(userID string) (*postgres.NotificationSettings, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.NotificationSettings), args.Error(1)
+}
+
+func (m *NotificationRepo) SetSettings(userID string, locale *string, emailDigest *bool) error {
+	args := m.Called(userID, locale, emailDigest)
+	return args.Error(0)
+}
+
+// MOCK EMAIL REPO
+type EmailRepo struct {
+	mock.Mock
+}
+
+func (m *EmailRepo) GetEmailRecipients(userIDs []string, eventType string, excludeDigest bool) ([]postgres.EmailRecipient, error) {
+	args := m.Called(userIDs, eventType, excludeDigest)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.EmailRecipient), args.Error(1)
+}
+
+func (m *EmailRepo) ClaimEmails(limit int) ([]postgres.Email, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.Email), args.Error(1)
+}
+
+func (m *EmailRepo) MarkEmailSent(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *EmailRepo) MarkEmailFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error {
+	args := m.Called(id, lastError, nextAttemptAt, dead)
+	return args.Error(0)
+}
+
+func (m *EmailRepo) ReleaseEmails(ids []string) error {
+	args := m.Called(ids)
+	return args.Error(0)
+}
+
+func (m *EmailRepo) ClaimEmailEvents(limit int) ([]postgres.EmailEvent, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.EmailEvent), args.Error(1)
+}
+
+func (m *EmailRepo) CompleteEmailEvent(id string, emails []postgres.Email) error {
+	args := m.Called(id, emails)
+	return args.Error(0)
+}
+
+func (m *EmailRepo) MarkEmailEventFailed(id, lastError string, nextAttemptAt time.Time, dead bool) error {
+	args := m.Called(id, lastError, nextAttemptAt, dead)
+	return args.Error(0)
+}
+
+func (m *EmailRepo) GetDigestRecipients(cutoff time.Time) ([]postgres.EmailRecipient, error) {
+	args := m.Called(cutoff)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.EmailRecipient), args.Error(1)
+}
+
+func (m *EmailRepo) GetPendingVerifications(advisorUserID string) ([]postgres.PendingVerification, error) {
+	args := m.Called(advisorUserID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.PendingVerification), args.Error(1)
+}
+
+func (m *EmailRepo) ClaimDigest(userID string, cutoff, at time.Time, digest *postgres.Email) (bool, error) {
+	args := m.Called(userID, cutoff, at, digest)
+	return args.Bool(0), args.Error(1)
+}
+
+// MOCK ROLE REPO
+type RoleRepo struct {
+	mock.Mock
+}
+
+func (m *RoleRepo) GetAllRoles() ([]postgres.Role, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.Role), args.Error(1)
+}
+
+func (m *RoleRepo) GetRoleByID(id string) (*postgres.Role, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.Role), args.Error(1)
+}
+
+func (m *RoleRepo) CreateRole(role postgres.Role) (string, error) {
+	args := m.Called(role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *RoleRepo) UpdateRole(id string, req postgres.UpdateRoleRequest) error {
+	args := m.Called(id, req)
+	return args.Error(0)
+}
+
+func (m *RoleRepo) DeleteRole(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *RoleRepo) GetAllPermissions() ([]postgres.Permission, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]postgres.Permission), args.Error(1)
+}
+
+func (m *RoleRepo) CreatePermission(perm postgres.Permission) (string, error) {
+	args := m.Called(perm)
+	return args.String(0), args.Error(1)
+}
+
+func (m *RoleRepo) DeletePermission(id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *RoleRepo) GetPermissionsByRoleID(roleID string) ([]string, error) {
+	args := m.Called(roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *RoleRepo) AssignPermission(roleID, permissionID string) error {
+	args := m.Called(roleID, permissionID)
+	return args.Error(0)
+}
+
+func (m *RoleRepo) RevokePermission(roleID, permissionID string) error {
+	args := m.Called(roleID, permissionID)
+	return args.Error(0)
+}
+
+// MOCK ACADEMIC REPO
+type AcademicRepo struct {
+	mock.Mock
+}
+
+func (m *AcademicRepo) GetAllStudents() ([]map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+func (m *AcademicRepo) GetStudentByID(id string) (map[string]interface{}, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func (m *AcademicRepo) UpdateStudentAdvisor(studentID, advisorID, actorID string) (*postgres.NotificationEvent, error) {
+	args := m.Called(studentID, advisorID, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*postgres.NotificationEvent), args.Error(1)
+}
+
+func (m *AcademicRepo) GetAllLecturers() ([]map[string]interface{}, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
+}
+
+func (m *AcademicRepo) GetLecturerAdvisees(lecturerID string) ([]map[string]interface{}, error) {
+	args := m.Called(lecturerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]interface{}), args.Error(1)
 }
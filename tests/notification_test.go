@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRejectAchievement_NotifiesStudent(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	notifRepo := new(mocks.NotificationRepo)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+	svc.Notifier = service.NewNotificationService(notifRepo)
+
+	note := "Sertifikat tidak terbaca"
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted"}, nil)
+	mockPG.On("UpdateVerification", "ref-1", "submitted", "rejected", "dosen-uuid-123", &note, (*postgres.PointsSnapshot)(nil)).Return(nil)
+	notifRepo.On("GetAchievementParticipants", "ref-1").Return(&postgres.NotificationParticipants{StudentUserID: "user-123", AdvisorUserID: "dosen-uuid-123"}, nil)
+	notifRepo.On("CreateNotifications", mock.MatchedBy(func(ns []postgres.Notification) bool {
+		return len(ns) == 1 && ns[0].UserID == "user-123" && ns[0].EventType == postgres.NotifAchievementRejected &&
+			*ns[0].AchievementRefID == "ref-1" && bytes.Contains([]byte(ns[0].Message), []byte(note))
+	})).Return(nil)
+
+	app := setupAppWithDosenAuth(svc.RejectAchievement)
+	app.Post("/achievements/:id/reject", svc.RejectAchievement)
+	resp := postJSON(app, "/achievements/ref-1/reject", map[string]string{"note": note})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	notifRepo.AssertExpectations(t)
+}
+
+func TestSubmitAchievement_NotificationFailureIsNotFatal(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	notifRepo := new(mocks.NotificationRepo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+	svc.Notifier = service.NewNotificationService(notifRepo)
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{}, nil)
+	mockPG.On("UpdateStatus", "ref-1", "draft", "submitted", "user-123").Return(nil)
+	notifRepo.On("GetAchievementParticipants", "ref-1").Return(nil, errors.New("db down"))
+
+	app := setupAppWithAuth(svc.SubmitAchievement)
+	app.Post("/achievements/:id/submit", svc.SubmitAchievement)
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/ref-1/submit", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	notifRepo.AssertNotCalled(t, "CreateNotifications", mock.Anything)
+}
+
+func TestCreateComment_NotifiesParticipantsAndMentions(t *testing.T) {
+	commentRepo := new(mocks.CommentRepo)
+	notifRepo := new(mocks.NotificationRepo)
+	svc := service.NewCommentService(commentRepo)
+	svc.Notifier = service.NewNotificationService(notifRepo)
+
+	commentRepo.On("FindMentionableUsers", "ref-1", []string{"pak_dosen", "admin"}).Return([]postgres.CommentMention{
+		{UserID: "dosen-user", Username: "pak_dosen"}, {UserID: "admin-user", Username: "admin"},
+	}, nil)
+	commentRepo.On("CreateComment", mock.Anything, []string{"dosen-user", "admin-user"}).Return(&postgres.AchievementComment{ID: "c-1"}, nil)
+	notifRepo.On("GetAchievementParticipants", "ref-1").Return(&postgres.NotificationParticipants{StudentUserID: "user-123", AdvisorUserID: "dosen-user"}, nil)
+
+	var sent []postgres.Notification
+	notifRepo.On("CreateNotifications", mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(0).([]postgres.Notification)
+	}).Return(nil)
+
+	app := setupAppWithAuth(svc.CreateComment)
+	app.Post("/achievements/:id/comments", svc.CreateComment)
+	resp := postJSON(app, "/achievements/ref-1/comments", postgres.CommentRequest{Body: "Mohon dicek @pak_dosen @admin"})
+	assert.Equal(t, 201, resp.StatusCode)
+
+	// Penulis (mahasiswa) tidak menerima notifikasi; dosen wali cukup sekali (sebagai mention)
+	types := map[string]string{}
+	for _, n := range sent {
+		types[n.UserID] = n.EventType
+	}
+	assert.Equal(t, map[string]string{"dosen-user": postgres.NotifCommentMention, "admin-user": postgres.NotifCommentMention}, types)
+}
+
+func TestGetNotifications_WithUnreadCount(t *testing.T) {
+	repo := new(mocks.NotificationRepo)
+	svc := service.NewNotificationService(repo)
+	repo.On("ListNotifications", "user-123", true, 20, 0).Return([]postgres.Notification{
+		{ID: "n-1", UserID: "user-123", EventType: postgres.NotifAchievementVerified, Title: "Achievement verified"},
+	}, 1, nil)
+	repo.On("CountUnread", "user-123").Return(3, nil)
+
+	app := setupAppWithAuth(svc.GetNotifications)
+	app.Get("/notifications", svc.GetNotifications)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/notifications?unread=true", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data []postgres.Notification `json:"data"`
+		Meta map[string]int          `json:"meta"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, 3, body.Meta["unread_count"])
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	repo := new(mocks.NotificationRepo)
+	svc := service.NewNotificationService(repo)
+	repo.On("MarkRead", "user-123", []string{"n-1", "n-2"}).Return(2, nil)
+	repo.On("CountUnread", "user-123").Return(0, nil)
+
+	app := setupAppWithAuth(svc.MarkNotificationsRead)
+	app.Post("/notifications/read", svc.MarkNotificationsRead)
+
+	resp := postJSON(app, "/notifications/read", postgres.MarkNotificationsRequest{IDs: []string{"n-1", "n-2"}})
+	assert.Equal(t, 200, resp.StatusCode)
+	resp = postJSON(app, "/notifications/read", postgres.MarkNotificationsRequest{})
+	assert.Equal(t, 400, resp.StatusCode)
+	repo.AssertNumberOfCalls(t, "MarkRead", 1)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	repo := new(mocks.NotificationRepo)
+	svc := service.NewNotificationService(repo)
+	repo.On("SetPreferences", "user-123", map[string]bool{"comment_created": false}, map[string]bool(nil)).Return(nil)
+	repo.On("GetPreferences", "user-123").Return([]postgres.NotificationPreference{{EventType: "comment_created", InApp: false}}, nil)
+	repo.On("GetSettings", "user-123").Return(&postgres.NotificationSettings{Locale: "id"}, nil)
+
+	app := setupAppWithAuth(svc.UpdateNotificationPreferences)
+	app.Put("/notifications/preferences", svc.UpdateNotificationPreferences)
+	put := func(prefs map[string]bool) int {
+		b, _ := json.Marshal(postgres.NotificationPreferencesRequest{Preferences: prefs})
+		req := httptest.NewRequest("PUT", "/notifications/preferences", bytes.NewReader(b))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		var body struct {
+			Data []postgres.NotificationPreference `json:"data"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode == 200 {
+			// Semua jenis event dikembalikan; yang belum diatur tetap aktif
+			assert.Len(t, body.Data, len(postgres.NotificationEventTypes))
+			for _, p := range body.Data {
+				assert.Equal(t, p.EventType != "comment_created", p.InApp, p.EventType)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, 200, put(map[string]bool{"comment_created": false}))
+	assert.Equal(t, 400, put(map[string]bool{"achievement_exploded": false}))
+	repo.AssertNumberOfCalls(t, "SetPreferences", 1)
+}
+
+func putJSON(app *fiber.App, url string, body interface{}) *http.Response {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest("PUT", url, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp
+}
+
+// Pergantian dosen wali: mahasiswa, dosen wali baru dan dosen wali lama dinotifikasi
+func TestUpdateStudentAdvisor_NotifiesPreviousAdvisor(t *testing.T) {
+	repo := new(mocks.AcademicRepo)
+	notifRepo := new(mocks.NotificationRepo)
+	svc := service.NewAcademicService(repo)
+	svc.Notifier = service.NewNotificationService(notifRepo)
+
+	repo.On("UpdateStudentAdvisor", "student-1", "lecturer-2", "admin-uuid-001").Return(&postgres.NotificationEvent{
+		Type: postgres.NotifAdvisorChanged, ActorID: "admin-uuid-001", StudentID: "student-1", PreviousAdvisorUserID: "dosen-lama",
+	}, nil)
+	notifRepo.On("GetStudentParticipants", "student-1").Return(&postgres.NotificationParticipants{StudentUserID: "user-123", AdvisorUserID: "dosen-baru"}, nil)
+	var got []postgres.Notification
+	notifRepo.On("CreateNotifications", mock.Anything).Run(func(args mock.Arguments) {
+		got = args.Get(0).([]postgres.Notification)
+	}).Return(nil)
+
+	app := setupAppWithAdminAuth(svc.UpdateStudentAdvisor)
+	app.Put("/students/:id/advisor", svc.UpdateStudentAdvisor)
+	resp := putJSON(app, "/students/student-1/advisor", map[string]string{"advisor_id": "lecturer-2"})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	titles := map[string]string{}
+	for _, n := range got {
+		titles[n.UserID] = n.Title
+	}
+	assert.Equal(t, map[string]string{
+		"user-123":   "Academic advisor changed",
+		"dosen-baru": "New advisee assigned",
+		"dosen-lama": "Advisee reassigned",
+	}, titles)
+}
+
+func TestUpdateStudentAdvisor_NoOpDoesNotNotify(t *testing.T) {
+	repo := new(mocks.AcademicRepo)
+	notifRepo := new(mocks.NotificationRepo)
+	svc := service.NewAcademicService(repo)
+	svc.Notifier = service.NewNotificationService(notifRepo)
+
+	repo.On("UpdateStudentAdvisor", "student-1", "lecturer-2", "admin-uuid-001").Return(nil, nil)
+
+	app := setupAppWithAdminAuth(svc.UpdateStudentAdvisor)
+	app.Put("/students/:id/advisor", svc.UpdateStudentAdvisor)
+	resp := putJSON(app, "/students/student-1/advisor", map[string]string{"advisor_id": "lecturer-2"})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	notifRepo.AssertNotCalled(t, "GetStudentParticipants", mock.Anything)
+	notifRepo.AssertNotCalled(t, "CreateNotifications", mock.Anything)
+}
@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"be_uas/utils"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func postJSON(app *fiber.App, url string, body interface{}) *http.Response {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", url, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestValidatePassword_Policy(t *testing.T) {
+	utils.SetPasswordPolicy(utils.DefaultPasswordPolicy)
+
+	assert.Empty(t, utils.ValidatePassword("Rahasia123"))
+	assert.Len(t, utils.ValidatePassword("rahasia"), 3) // pendek, tanpa huruf besar, tanpa angka
+
+	utils.SetPasswordPolicy(utils.PasswordPolicy{MinLength: 4, RequireSymbol: true})
+	defer utils.SetPasswordPolicy(utils.DefaultPasswordPolicy)
+	assert.Equal(t, []string{"must contain a symbol"}, utils.ValidatePassword("abcd"))
+	assert.Empty(t, utils.ValidatePassword("ab!d"))
+}
+
+func TestChangePassword_Success(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetPasswordHash", "user-123").Return(string(hashed), nil)
+	mockTokenRepo.On("ChangePassword", "user-123", mock.MatchedBy(func(h string) bool {
+		return utils.CheckPassword(h, "PasswordBaru1")
+	})).Return(nil)
+	mockUserRepo.On("GetUserByID", "user-123").Return(&postgres.User{ID: "user-123", IsActive: true, TokenVersion: 2}, nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything).Return(nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.MatchedBy(func(l postgres.AuthAuditLog) bool {
+		return l.Event == postgres.AuditPasswordChanged
+	})).Return(nil)
+
+	app := setupAppWithAuth(authService.ChangePassword)
+	app.Post("/auth/password", authService.ChangePassword)
+	resp := postJSON(app, "/auth/password", map[string]string{
+		"current_password": "Rahasia123",
+		"new_password":     "PasswordBaru1",
+	})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockUserRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+// Pencabutan sesi gagal: password baru tidak boleh ikut tersimpan
+func TestChangePassword_RevokeFailsKeepsPassword(t *testing.T) {
+	db, state := openFakeDB(t, "UPDATE refresh_tokens")
+	repo := repoPG.NewTokenRepoPG(db)
+
+	err := repo.ChangePassword("user-123", "hash-baru")
+
+	assert.Error(t, err)
+	for _, q := range state.Committed() {
+		assert.NotContains(t, q, "password_hash")
+	}
+}
+
+func TestAdminResetPassword_RevokeFailsKeepsUser(t *testing.T) {
+	db, state := openFakeDB(t, "UPDATE refresh_tokens")
+	repo := repoPG.NewTokenRepoPG(db)
+
+	err := repo.CreatePasswordResetToken(postgres.PasswordResetToken{UserID: "user-1", TokenHash: "h", ExpiresAt: time.Now().Add(time.Hour)})
+
+	assert.Error(t, err)
+	assert.Empty(t, state.Committed())
+}
+
+func TestChangePassword_CommitsPasswordAndRevokeTogether(t *testing.T) {
+	db, state := openFakeDB(t, "")
+	repo := repoPG.NewTokenRepoPG(db)
+
+	assert.NoError(t, repo.ChangePassword("user-123", "hash-baru"))
+
+	committed := strings.Join(state.Committed(), "\n")
+	assert.Contains(t, committed, "password_hash")
+	assert.Contains(t, committed, "token_version = token_version + 1")
+	assert.Contains(t, committed, "UPDATE refresh_tokens")
+}
+
+func TestChangePassword_RepoFailureReturns500(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetPasswordHash", "user-123").Return(string(hashed), nil)
+	mockTokenRepo.On("ChangePassword", "user-123", mock.Anything).Return(sql.ErrConnDone)
+
+	app := setupAppWithAuth(authService.ChangePassword)
+	app.Post("/auth/password", authService.ChangePassword)
+	resp := postJSON(app, "/auth/password", map[string]string{
+		"current_password": "Rahasia123",
+		"new_password":     "PasswordBaru1",
+	})
+
+	assert.Equal(t, 500, resp.StatusCode)
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetPasswordHash", "user-123").Return(string(hashed), nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
+
+	app := setupAppWithAuth(authService.ChangePassword)
+	app.Post("/auth/password", authService.ChangePassword)
+	resp := postJSON(app, "/auth/password", map[string]string{
+		"current_password": "salah",
+		"new_password":     "PasswordBaru1",
+	})
+
+	assert.Equal(t, 401, resp.StatusCode)
+	mockTokenRepo.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_PolicyViolation(t *testing.T) {
+	utils.SetPasswordPolicy(utils.DefaultPasswordPolicy)
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetPasswordHash", "user-123").Return(string(hashed), nil)
+
+	app := setupAppWithAuth(authService.ChangePassword)
+	app.Post("/auth/password", authService.ChangePassword)
+	resp := postJSON(app, "/auth/password", map[string]string{
+		"current_password": "Rahasia123",
+		"new_password":     "lemah",
+	})
+
+	assert.Equal(t, 422, resp.StatusCode)
+	mockTokenRepo.AssertNotCalled(t, "ChangePassword", mock.Anything, mock.Anything)
+}
+
+func TestAdminResetPassword_IssuesToken(t *testing.T) {
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	svc := service.NewAdminService(new(mocks.UserRepo), new(mocks.AchievementRepoPG), new(mocks.AchievementRepoMongo), mockTokenRepo, mockAuditRepo, new(mocks.MFARepo))
+
+	var stored postgres.PasswordResetToken
+	mockTokenRepo.On("CreatePasswordResetToken", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(postgres.PasswordResetToken)
+	}).Return(nil)
+	mockAuditRepo.On("CreateAuthAudit", mock.Anything).Return(nil)
+
+	app := setupAppWithAdminAuth(svc.ResetUserPassword)
+	app.Post("/users/:id/password-reset", svc.ResetUserPassword)
+	resp, _ := app.Test(httptest.NewRequest("POST", "/users/user-1/password-reset", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body struct {
+		Data struct {
+			ResetToken string `json:"reset_token"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+
+	// Yang disimpan hanya hash, dibuat oleh admin yang sedang login
+	assert.NotEmpty(t, body.Data.ResetToken)
+	assert.Equal(t, utils.HashToken(body.Data.ResetToken), stored.TokenHash)
+	assert.Equal(t, "admin-uuid-001", stored.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(utils.PasswordResetTTL), stored.ExpiresAt, time.Minute)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	utils.SetPasswordPolicy(utils.DefaultPasswordPolicy)
+	mockTokenRepo := new(mocks.TokenRepo)
+	authService := service.NewAuthService(new(mocks.UserRepo), mockTokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	// Token sudah dipakai / kedaluwarsa
+	mockTokenRepo.On("ConsumePasswordResetToken", utils.HashToken("token-lama"), mock.Anything).Return("", sql.ErrNoRows)
+
+	app := fiber.New()
+	app.Post("/auth/password/reset", authService.ResetPassword)
+	resp := postJSON(app, "/auth/password/reset", map[string]string{
+		"token":        "token-lama",
+		"new_password": "PasswordBaru1",
+	})
+
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestLogin_MustChangePassword(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	mockAuditRepo := new(mocks.AuditRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, mockAuditRepo, new(mocks.MFARepo))
+
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	mockUserRepo.On("GetByUsername", "mahasiswa_test").Return(&postgres.User{
+		ID: "user-1", PasswordHash: string(hashed), IsActive: true, MustChangePassword: true,
+	}, nil)
+	mockAuditRepo.On("GetRecentFailuresByIP", mock.Anything, mock.Anything).Return(0, nil, nil)
+
+	app := fiber.New()
+	app.Post("/auth/login", authService.Login)
+	resp := loginRequest(app, "mahasiswa_test", "Rahasia123")
+
+	assert.Equal(t, 403, resp.StatusCode)
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, "password_change_required", body["code"])
+	// Tidak ada token yang diterbitkan
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"be_uas/app/listing"
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/scoring"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const competitionRules = `{
+	"types": {
+		"competition": {
+			"components":  [{"field": "competitionLevel", "values": {"international": 50, "national": 30, "regional": 20, "local": 10}}],
+			"multipliers": [{"field": "rank", "ranges": [{"max": 1, "value": 1}, {"min": 2, "max": 3, "value": 0.8}], "default": 0.5}]
+		}
+	}
+}`
+
+// Belum ada aturan aktif: semua jenis memakai poin default
+func noPointRules() *mocks.PointRuleRepo {
+	repo := new(mocks.PointRuleRepo)
+	repo.On("GetActiveRuleSet").Return(nil, sql.ErrNoRows)
+	return repo
+}
+
+func activePointRules(version int) *mocks.PointRuleRepo {
+	repo := new(mocks.PointRuleRepo)
+	repo.On("GetActiveRuleSet").Return(&postgres.PointRuleSet{
+		Version: version, Rules: json.RawMessage(competitionRules), IsActive: true,
+	}, nil)
+	return repo
+}
+
+func TestScoring_LevelTimesRank(t *testing.T) {
+	rs, err := scoring.Parse([]byte(competitionRules))
+	assert.NoError(t, err)
+
+	res := rs.Compute("competition", map[string]interface{}{"competitionLevel": "national", "rank": float64(2)}, 25)
+	assert.Equal(t, 24, res.Points) // 30 x 0.8
+	assert.Equal(t, "type", res.Rule)
+	assert.Len(t, res.Breakdown, 3)
+
+	// Tanpa rank: multiplier default (peserta)
+	res = rs.Compute("competition", map[string]interface{}{"competitionLevel": "International"}, 25)
+	assert.Equal(t, 25, res.Points) // 50 x 0.5
+
+	// Jenis tanpa aturan / belum ada aturan aktif -> poin default jenis
+	assert.Equal(t, 15, rs.Compute("certification", nil, 15).Points)
+	var none *scoring.RuleSet
+	assert.Equal(t, 15, none.Compute("competition", nil, 15).Points)
+}
+
+func TestScoring_ParseRejectsInvalid(t *testing.T) {
+	_, err := scoring.Parse([]byte(`{"types": {"competition": {"components": [{"field": "rank"}]}}}`))
+	assert.True(t, errors.Is(err, scoring.ErrInvalidRules))
+
+	_, err = scoring.Parse([]byte(`{"types": {"competition": {"min": 10, "max": 5}}}`))
+	assert.True(t, errors.Is(err, scoring.ErrInvalidRules))
+}
+
+func TestCreateAchievement_PointsFromActiveRules(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), activePointRules(3), new(mocks.Storage))
+
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+	// Poin dari client diabaikan
+	mockMongo.On("InsertAchievement", mock.Anything, mock.MatchedBy(func(a mongodb.Achievement) bool {
+		return a.Points == 30 && a.PointsRuleVersion == 3
+	})).Return("mongo-1", nil)
+	mockPG.On("CreateReference", mock.Anything, "user-123").Return(nil)
+
+	app := setupAppWithAuth(svc.CreateAchievement)
+	app.Post("/achievements", svc.CreateAchievement)
+	resp := postJSON(app, "/achievements", mongodb.Achievement{
+		Title:           "Juara 1 Gemastik",
+		AchievementType: "competition",
+		Points:          999,
+		Details:         map[string]interface{}{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1},
+	})
+
+	assert.Equal(t, 201, resp.StatusCode)
+	mockMongo.AssertExpectations(t)
+}
+
+func TestVerifyAchievement_FreezesPoints(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), competitionTypeRepo(), activePointRules(3), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{
+		ID: "ref-1", Status: "submitted", MongoAchievementID: "mongo-1",
+	}, nil)
+	// Dokumen masih memakai poin dari aturan versi lama
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{
+		AchievementType:   "competition",
+		Details:           map[string]interface{}{"competitionLevel": "international", "rank": int32(2)},
+		Points:            30,
+		PointsRuleVersion: 2,
+	}, nil)
+	mockPG.On("UpdateVerification", "ref-1", "submitted", "verified", "dosen-uuid-123", (*string)(nil), &postgres.PointsSnapshot{Points: 40, RuleVersion: 3}).Return(nil)
+	mockMongo.On("SetPoints", mock.Anything, "mongo-1", 40, 3).Return(nil)
+
+	app := setupAppWithDosenAuth(svc.VerifyAchievement)
+	app.Post("/achievements/:id/verify", svc.VerifyAchievement)
+	resp := postJSON(app, "/achievements/ref-1/verify", nil)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockPG.AssertExpectations(t)
+	mockMongo.AssertExpectations(t)
+}
+
+func TestRecomputePoints_SkipsVerifiedAndUnchanged(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewPointsService(activePointRules(4), competitionTypeRepo(), mockPG, mockMongo)
+
+	// Hanya status yang poinnya belum dibekukan
+	mockPG.On("FindReferencesByFilter", mock.MatchedBy(func(f listing.Filter) bool {
+		for _, st := range f.Statuses {
+			if st == "verified" || st == "deleted" {
+				return false
+			}
+		}
+		return len(f.Statuses) > 0
+	})).Return([]postgres.AchievementReference{
+		{ID: "ref-1", MongoAchievementID: "mongo-1"},
+		{ID: "ref-2", MongoAchievementID: "mongo-2"},
+		{ID: "ref-3", MongoAchievementID: "mongo-hilang"},
+	}, nil)
+	mockMongo.On("FindAchievementsByIDs", mock.Anything, []string{"mongo-1", "mongo-2", "mongo-hilang"}).Return(map[string]*mongodb.Achievement{
+		"mongo-1": {AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "regional", "rank": int32(1)}, Points: 20, PointsRuleVersion: 4},
+		"mongo-2": {AchievementType: "competition", Details: map[string]interface{}{"competitionLevel": "local"}, Points: 25},
+	}, nil)
+	mockMongo.On("SetPoints", mock.Anything, "mongo-2", 5, 4).Return(nil)
+
+	report, err := svc.Recompute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, service.RecomputeReport{RuleVersion: 4, Checked: 2, Updated: 1}, report)
+	mockMongo.AssertNumberOfCalls(t, "SetPoints", 1)
+}
+
+func TestCreatePointRuleSet_InvalidRules(t *testing.T) {
+	rules := new(mocks.PointRuleRepo)
+	svc := service.NewPointsService(rules, new(mocks.AchievementTypeRepo), new(mocks.AchievementRepoPG), new(mocks.AchievementRepoMongo))
+
+	app := setupAppWithAdminAuth(svc.CreatePointRuleSet)
+	app.Post("/point-rules", svc.CreatePointRuleSet)
+	resp := postJSON(app, "/point-rules", map[string]interface{}{
+		"description": "Multiplier tanpa nilai",
+		"rules":       map[string]interface{}{"types": map[string]interface{}{"competition": map[string]interface{}{"multipliers": []map[string]string{{"field": "rank"}}}}},
+	})
+
+	assert.Equal(t, 422, resp.StatusCode)
+	rules.AssertNotCalled(t, "CreateRuleSet", mock.Anything)
+}
+
+func TestPreviewPoints_WithVersion(t *testing.T) {
+	rules := new(mocks.PointRuleRepo)
+	rules.On("GetRuleSetByVersion", 7).Return(&postgres.PointRuleSet{Version: 7, Rules: json.RawMessage(competitionRules)}, nil)
+	svc := service.NewPointsService(rules, competitionTypeRepo(), new(mocks.AchievementRepoPG), new(mocks.AchievementRepoMongo))
+
+	app := setupAppWithAdminAuth(svc.PreviewPoints)
+	app.Post("/point-rules/preview", svc.PreviewPoints)
+	resp := postJSON(app, "/point-rules/preview", postgres.PreviewPointsRequest{
+		AchievementType: "competition",
+		Details:         map[string]interface{}{"competitionLevel": "international", "rank": 1},
+		Version:         7,
+	})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body struct {
+		Data struct {
+			Points    int            `json:"points"`
+			Version   int            `json:"version"`
+			Breakdown []scoring.Line `json:"breakdown"`
+		} `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 50, body.Data.Points)
+	assert.Equal(t, 7, body.Data.Version)
+	assert.Len(t, body.Data.Breakdown, 3)
+}
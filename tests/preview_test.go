@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/preview"
+	"be_uas/app/service"
+	"be_uas/app/storage"
+	"be_uas/tests/mocks"
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Renderer PDF palsu: halaman A4 polos (tanpa pdftoppm)
+type fakePDFRenderer struct{}
+
+func (fakePDFRenderer) FirstPage(ctx context.Context, pdf []byte, maxSize int) (image.Image, error) {
+	return image.NewGray(image.Rect(0, 0, maxSize*210/297, maxSize)), nil
+}
+
+// WebP lossless 64x48 satu warna (RGB 64,128,192)
+var webpBytes = []byte{
+	0x52, 0x49, 0x46, 0x46, 0x18, 0x00, 0x00, 0x00, 0x57, 0x45, 0x42, 0x50, 0x56, 0x50, 0x38, 0x4c,
+	0x0c, 0x00, 0x00, 0x00, 0x2f, 0x3f, 0xc0, 0x0b, 0x00, 0x28, 0x60, 0x81, 0x0a, 0xdc, 0xff, 0x00,
+}
+
+func encodedPNG(w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
+
+func TestPreviewFit(t *testing.T) {
+	wide := image.NewRGBA(image.Rect(0, 0, 2000, 1000))
+	assert.Equal(t, image.Rect(0, 0, 320, 160), preview.Fit(wide, 320).Bounds())
+
+	// Gambar kecil tidak diperbesar, transparansi jadi putih
+	small := preview.Fit(image.NewNRGBA(image.Rect(0, 0, 40, 30)), 320)
+	assert.Equal(t, image.Rect(0, 0, 40, 30), small.Bounds())
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, small.RGBAAt(0, 0))
+}
+
+func TestGeneratePending_ImagesAndPDF(t *testing.T) {
+	store, _ := storage.NewLocal(t.TempDir())
+	ctx := context.Background()
+	photo := encodedPNG(1600, 1200)
+	store.Put(ctx, "achievements/ref-1/att-img.png", bytes.NewReader(photo), int64(len(photo)), "image/png")
+	store.Put(ctx, "achievements/ref-1/att-pdf.pdf", bytes.NewReader([]byte("%PDF-1.4")), 8, "application/pdf")
+	store.Put(ctx, "achievements/ref-1/att-webp.webp", bytes.NewReader(webpBytes), int64(len(webpBytes)), "image/webp")
+
+	docID := primitive.NewObjectID()
+	pending := func(id, key, fileType string) mongodb.PendingAttachment {
+		return mongodb.PendingAttachment{AchievementID: docID, Attachment: mongodb.Attachment{
+			ID: id, StorageKey: key, FileType: fileType, FileURL: "/api/v1/achievements/ref-1/attachments/" + id,
+		}}
+	}
+	mockMongo := new(mocks.AchievementRepoMongo)
+	mockMongo.On("FindPendingPreviews", mock.Anything, service.PreviewBatchSize).Return([]mongodb.PendingAttachment{
+		pending("att-img", "achievements/ref-1/att-img.png", "image/png"),
+		pending("att-pdf", "achievements/ref-1/att-pdf.pdf", "application/pdf"),
+		pending("att-webp", "achievements/ref-1/att-webp.webp", "image/webp"),
+	}, nil)
+	mockMongo.On("SetPreview", mock.Anything, docID.Hex(), "att-img", mongodb.AttachmentPreview{
+		Status:       mongodb.PreviewReady,
+		ThumbnailURL: "/api/v1/achievements/ref-1/attachments/att-img/thumbnail",
+		PreviewURL:   "/api/v1/achievements/ref-1/attachments/att-img/preview",
+		ThumbnailKey: "achievements/ref-1/att-img.thumb.jpg",
+		PreviewKey:   "achievements/ref-1/att-img.preview.jpg",
+	}).Return(nil)
+	mockMongo.On("SetPreview", mock.Anything, docID.Hex(), "att-pdf", mock.MatchedBy(func(p mongodb.AttachmentPreview) bool {
+		return p.Status == mongodb.PreviewReady && p.ThumbnailKey == "achievements/ref-1/att-pdf.thumb.jpg"
+	})).Return(nil)
+	mockMongo.On("SetPreview", mock.Anything, docID.Hex(), "att-webp", mock.MatchedBy(func(p mongodb.AttachmentPreview) bool {
+		return p.Status == mongodb.PreviewReady && p.ThumbnailKey == "achievements/ref-1/att-webp.thumb.jpg"
+	})).Return(nil)
+
+	svc := service.NewPreviewService(mockMongo, store, &preview.Generator{PDF: fakePDFRenderer{}})
+	report, err := svc.GeneratePending(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, service.PreviewReport{Generated: 3, Errors: []string{}}, report)
+	mockMongo.AssertExpectations(t)
+
+	rc, err := store.Open(ctx, "achievements/ref-1/att-img.thumb.jpg")
+	assert.NoError(t, err)
+	thumb, err := jpeg.DecodeConfig(rc)
+	rc.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, 320, thumb.Width)
+	assert.Equal(t, 240, thumb.Height)
+}
+
+func TestDownloadAttachmentThumbnail(t *testing.T) {
+	store, _ := storage.NewLocal(t.TempDir())
+	store.Put(context.Background(), "achievements/ref-1/att-1.thumb.jpg", bytes.NewReader([]byte("jpeg")), 4, "image/jpeg")
+
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), store)
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{Attachments: []mongodb.Attachment{
+		{ID: "att-1", ScanStatus: mongodb.ScanClean, Preview: &mongodb.AttachmentPreview{
+			Status: mongodb.PreviewReady, ThumbnailKey: "achievements/ref-1/att-1.thumb.jpg", PreviewKey: "achievements/ref-1/att-1.preview.jpg",
+		}},
+		{ID: "att-2", ScanStatus: mongodb.ScanPending, Preview: &mongodb.AttachmentPreview{Status: mongodb.PreviewPending}},
+	}}, nil)
+
+	app := setupAppWithDosenAuth(svc.DownloadAttachmentThumbnail)
+	app.Get("/achievements/:id/attachments/:attachmentId/thumbnail", svc.DownloadAttachmentThumbnail)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments/att-1/thumbnail", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "image/jpeg", resp.Header.Get("Content-Type"))
+
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments/att-2/thumbnail", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"be_uas/middleware"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper: app dengan role_id tertentu dan route yang butuh permission
+func setupPermissionApp(roleID, permission string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role_id", roleID)
+		return c.Next()
+	})
+	app.Post("/achievements/:id/verify", middleware.RequirePermission(permission), func(c *fiber.Ctx) error {
+		return c.SendStatus(200)
+	})
+	return app
+}
+
+func TestRequirePermission_AllowsAndDenies(t *testing.T) {
+	middleware.SetPermissionLoader(func(roleID string) ([]string, error) {
+		if roleID == "role-dosen" {
+			return []string{"achievement:read", "achievement:verify"}, nil
+		}
+		return []string{"achievement:read"}, nil
+	})
+
+	resp, _ := setupPermissionApp("role-dosen", "achievement:verify").Test(httptest.NewRequest("POST", "/achievements/1/verify", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp, _ = setupPermissionApp("role-mhs", "achievement:verify").Test(httptest.NewRequest("POST", "/achievements/1/verify", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestRequirePermission_CacheInvalidation(t *testing.T) {
+	calls := 0
+	granted := false
+	middleware.SetPermissionLoader(func(roleID string) ([]string, error) {
+		calls++
+		if granted {
+			return []string{"achievement:verify"}, nil
+		}
+		return []string{}, nil
+	})
+
+	app := setupPermissionApp("role-kaprodi", "achievement:verify")
+
+	// Permission belum ada, hasil di-cache
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/1/verify", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+	app.Test(httptest.NewRequest("POST", "/achievements/1/verify", nil))
+	assert.Equal(t, 1, calls)
+
+	// Admin assign permission -> cache role di-invalidate
+	granted = true
+	middleware.InvalidatePermissions("role-kaprodi")
+
+	resp, _ = app.Test(httptest.NewRequest("POST", "/achievements/1/verify", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, calls)
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"be_uas/utils"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func refreshRequest(app *fiber.App, token string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"refreshToken": token})
+	req := httptest.NewRequest("POST", "/auth/refresh", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	rec := httptest.NewRecorder()
+	rec.Code = resp.StatusCode
+	rec.Body.ReadFrom(resp.Body)
+	return rec
+}
+
+func TestRefreshToken_Rotates(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	plain := "refresh-token-lama"
+	mockTokenRepo.On("GetRefreshTokenByHash", utils.HashToken(plain)).Return(&postgres.RefreshToken{
+		ID: "rt-1", UserID: "user-1", FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	mockTokenRepo.On("MarkRefreshTokenUsed", "rt-1").Return(true, nil)
+	mockUserRepo.On("GetUserByID", "user-1").Return(&postgres.User{ID: "user-1", IsActive: true}, nil)
+	// Token baru tetap di family yang sama
+	mockTokenRepo.On("CreateRefreshToken", mock.MatchedBy(func(rt postgres.RefreshToken) bool {
+		return rt.FamilyID == "family-1" && rt.TokenHash != utils.HashToken(plain)
+	})).Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/refresh", authService.RefreshToken)
+	rec := refreshRequest(app, plain)
+
+	assert.Equal(t, 200, rec.Code)
+	var body struct {
+		Data struct {
+			Token        string `json:"token"`
+			RefreshToken string `json:"refreshToken"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	assert.NotEmpty(t, body.Data.Token)
+	assert.NotEqual(t, plain, body.Data.RefreshToken)
+	mockTokenRepo.AssertExpectations(t)
+}
+
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	mockUserRepo := new(mocks.UserRepo)
+	mockTokenRepo := new(mocks.TokenRepo)
+	authService := service.NewAuthService(mockUserRepo, mockTokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	// Token sudah pernah dirotasi sebelumnya
+	usedAt := time.Now().Add(-time.Minute)
+	plain := "refresh-token-dicuri"
+	mockTokenRepo.On("GetRefreshTokenByHash", utils.HashToken(plain)).Return(&postgres.RefreshToken{
+		ID: "rt-1", UserID: "user-1", FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt,
+	}, nil)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", "family-1").Return(nil)
+
+	app := fiber.New()
+	app.Post("/auth/refresh", authService.RefreshToken)
+	rec := refreshRequest(app, plain)
+
+	assert.Equal(t, 401, rec.Code)
+	mockTokenRepo.AssertCalled(t, "RevokeRefreshTokenFamily", "family-1")
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything)
+}
+
+func TestRefreshToken_ReuseRevokeFailureIsServerError(t *testing.T) {
+	mockTokenRepo := new(mocks.TokenRepo)
+	authService := service.NewAuthService(new(mocks.UserRepo), mockTokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	usedAt := time.Now().Add(-time.Minute)
+	plain := "refresh-token-dicuri"
+	mockTokenRepo.On("GetRefreshTokenByHash", utils.HashToken(plain)).Return(&postgres.RefreshToken{
+		ID: "rt-1", UserID: "user-1", FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &usedAt,
+	}, nil)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", "family-1").Return(errors.New("db down"))
+
+	app := fiber.New()
+	app.Post("/auth/refresh", authService.RefreshToken)
+	rec := refreshRequest(app, plain)
+
+	// Family gagal dicabut: jangan laporkan seolah sudah aman
+	assert.Equal(t, 500, rec.Code)
+}
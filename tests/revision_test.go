@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequestRevision_Success(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	note := "Lengkapi data lomba"
+	comments := []postgres.RevisionCommentInput{
+		{Field: "title", Comment: "Gunakan nama resmi lomba"},
+		{Field: "details.rank", Comment: "Peringkat tidak sesuai sertifikat"},
+	}
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted", ReviewRound: 2}, nil)
+	mockPG.On("RequestRevision", "ref-1", "submitted", "dosen-uuid-123", &note, comments).Return(nil)
+
+	app := setupAppWithDosenAuth(svc.RequestRevision)
+	app.Post("/achievements/:id/request-revision", svc.RequestRevision)
+	resp := postJSON(app, "/achievements/ref-1/request-revision", postgres.RevisionRequest{
+		Note: note,
+		Comments: []postgres.RevisionCommentInput{
+			{Field: " title ", Comment: "Gunakan nama resmi lomba"},
+			{Field: "details.rank", Comment: "Peringkat tidak sesuai sertifikat"},
+		},
+	})
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockPG.AssertExpectations(t)
+}
+
+func TestRequestRevision_Validation(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted"}, nil)
+	mockPG.On("GetReferenceByID", "ref-2").Return(&postgres.AchievementReference{ID: "ref-2", Status: "draft"}, nil)
+
+	app := setupAppWithDosenAuth(svc.RequestRevision)
+	app.Post("/achievements/:id/request-revision", svc.RequestRevision)
+
+	// Tanpa komentar, field tidak dikenal, komentar kosong
+	for _, body := range []postgres.RevisionRequest{
+		{Note: "Perbaiki"},
+		{Comments: []postgres.RevisionCommentInput{{Field: "points", Comment: "Poin salah"}}},
+		{Comments: []postgres.RevisionCommentInput{{Field: "description", Comment: "  "}}},
+	} {
+		resp := postJSON(app, "/achievements/ref-1/request-revision", body)
+		assert.Equal(t, 400, resp.StatusCode)
+	}
+
+	// Masih draft: belum bisa direview
+	resp := postJSON(app, "/achievements/ref-2/request-revision", postgres.RevisionRequest{
+		Comments: []postgres.RevisionCommentInput{{Field: "title", Comment: "Judul kurang jelas"}},
+	})
+	assert.Equal(t, 409, resp.StatusCode)
+	mockPG.AssertNotCalled(t, "RequestRevision", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRequestRevision_ConcurrentVerifyConflict(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	// Handler membaca submitted, tapi request lain sudah memverifikasi sebelum baris dikunci
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "submitted"}, nil)
+	mockPG.On("RequestRevision", "ref-1", "submitted", "dosen-uuid-123", (*string)(nil), mock.Anything).
+		Return(&repoPG.StatusConflictError{Expected: "submitted", Actual: "verified"})
+
+	app := setupAppWithDosenAuth(svc.RequestRevision)
+	app.Post("/achievements/:id/request-revision", svc.RequestRevision)
+	resp := postJSON(app, "/achievements/ref-1/request-revision", postgres.RevisionRequest{
+		Comments: []postgres.RevisionCommentInput{{Field: "title", Comment: "Judul kurang jelas"}},
+	})
+
+	var body map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Equal(t, 409, resp.StatusCode)
+	assert.Equal(t, "verified", body["status"])
+}
+
+func TestResubmitAfterRevision(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "needs_revision", MongoAchievementID: "mongo-1", ReviewRound: 1}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{}, nil)
+	mockPG.On("UpdateStatus", "ref-1", "needs_revision", "submitted", "user-123").Return(nil)
+
+	app := setupAppWithAuth(svc.SubmitAchievement)
+	app.Post("/achievements/:id/submit", svc.SubmitAchievement)
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/ref-1/submit", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	mockPG.AssertExpectations(t)
+}
+
+func TestGetAchievementHistory_RevisionRounds(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	svc := service.NewAchievementService(mockPG, new(mocks.AchievementRepoMongo), new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	str := func(s string) *string { return &s }
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "needs_revision", ReviewRound: 2}, nil)
+	mockPG.On("GetStatusHistory", "ref-1", 20, 0).Return([]postgres.AchievementStatusHistory{
+		{ToStatus: "draft", Round: 0},
+		{FromStatus: str("draft"), ToStatus: "submitted", Round: 1},
+		{FromStatus: str("submitted"), ToStatus: "needs_revision", Round: 1},
+		{FromStatus: str("needs_revision"), ToStatus: "submitted", Round: 2},
+		{FromStatus: str("submitted"), ToStatus: "needs_revision", Round: 2},
+	}, 5, nil)
+	mockPG.On("GetRevisionComments", "ref-1").Return([]postgres.RevisionComment{
+		{Round: 1, Field: "title", Comment: "Judul kurang jelas"},
+		{Round: 1, Field: "attachments", Comment: "Sertifikat belum ada"},
+		{Round: 2, Field: "details.rank", Comment: "Peringkat masih salah"},
+	}, nil)
+
+	app := setupAppWithAuth(svc.GetAchievementHistory)
+	app.Get("/achievements/:id/history", svc.GetAchievementHistory)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/ref-1/history", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+
+	var body struct {
+		Data []postgres.AchievementStatusHistory `json:"data"`
+	}
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 5)
+	assert.Empty(t, body.Data[1].Comments)
+	assert.Len(t, body.Data[2].Comments, 2)
+	assert.Len(t, body.Data[4].Comments, 1)
+	assert.Equal(t, "details.rank", body.Data[4].Comments[0].Field)
+}
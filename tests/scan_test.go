@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	repoPG "be_uas/app/repository/postgres"
+	"be_uas/app/scanner"
+	"be_uas/app/service"
+	"be_uas/app/storage"
+	"be_uas/tests/mocks"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Stand-in clamd: menerima INSTREAM dan membalas FOUND jika ada string EICAR
+func fakeClamd(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				if cmd, _ := r.ReadString(0); cmd != "zINSTREAM\x00" {
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+					return
+				}
+				var data bytes.Buffer
+				for {
+					var size uint32
+					if binary.Read(r, binary.BigEndian, &size) != nil {
+						return
+					}
+					if size == 0 {
+						break
+					}
+					io.CopyN(&data, r, int64(size))
+				}
+				if bytes.Contains(data.Bytes(), []byte(scanner.EICAR)) {
+					conn.Write([]byte("stream: Win.Test.EICAR_HDB-1 FOUND\x00"))
+					return
+				}
+				conn.Write([]byte("stream: OK\x00"))
+			}(conn)
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamAV_AgainstStandIn(t *testing.T) {
+	clam, err := scanner.NewClamAV(fakeClamd(t), 0)
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	res, err := clam.Scan(ctx, bytes.NewReader(pngBytes))
+	assert.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	// Lebih besar dari satu chunk INSTREAM
+	infected := append(bytes.Repeat([]byte{'A'}, 100*1024), []byte(scanner.EICAR)...)
+	res, err = clam.Scan(ctx, bytes.NewReader(infected))
+	assert.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Win.Test.EICAR_HDB-1", res.Signature)
+}
+
+func TestStubScanner_SignatureAcrossReads(t *testing.T) {
+	res, err := scanner.Stub{}.Scan(context.Background(), iotest.OneByteReader(strings.NewReader("prefix "+scanner.EICAR)))
+	assert.NoError(t, err)
+	assert.True(t, res.Infected)
+
+	res, err = scanner.Stub{}.Scan(context.Background(), bytes.NewReader(pngBytes))
+	assert.NoError(t, err)
+	assert.False(t, res.Infected)
+}
+
+// Stub tidak boleh terpilih diam-diam: backend wajib diisi eksplisit
+func TestScannerFromEnv_RequiresExplicitBackend(t *testing.T) {
+	t.Setenv("SCANNER_BACKEND", "")
+	_, err := scanner.FromEnv()
+	assert.ErrorContains(t, err, "SCANNER_BACKEND is not set")
+
+	t.Setenv("SCANNER_BACKEND", "stub")
+	s, err := scanner.FromEnv()
+	assert.NoError(t, err)
+	assert.IsType(t, scanner.Stub{}, s)
+}
+
+func TestScanPending_QuarantinesInfected(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocal(dir)
+	ctx := context.Background()
+	store.Put(ctx, "achievements/ref-1/att-ok.png", bytes.NewReader(pngBytes), int64(len(pngBytes)), "image/png")
+	store.Put(ctx, "achievements/ref-1/att-bad.pdf", strings.NewReader(scanner.EICAR), int64(len(scanner.EICAR)), "application/pdf")
+
+	docID := primitive.NewObjectID()
+	mockMongo := new(mocks.AchievementRepoMongo)
+	mockMongo.On("FindPendingScans", mock.Anything, service.ScanBatchSize).Return([]mongodb.PendingAttachment{
+		{AchievementID: docID, Attachment: mongodb.Attachment{ID: "att-ok", StorageKey: "achievements/ref-1/att-ok.png", Size: int64(len(pngBytes)), FileType: "image/png"}},
+		{AchievementID: docID, Attachment: mongodb.Attachment{ID: "att-bad", StorageKey: "achievements/ref-1/att-bad.pdf", Size: int64(len(scanner.EICAR)), FileType: "application/pdf"}},
+	}, nil)
+	mockMongo.On("SetScanResult", mock.Anything, docID.Hex(), "att-ok", mongodb.ScanClean, "", "achievements/ref-1/att-ok.png").Return(nil)
+	mockMongo.On("SetScanResult", mock.Anything, docID.Hex(), "att-bad", mongodb.ScanInfected, "Eicar-Test-Signature", "quarantine/achievements/ref-1/att-bad.pdf").Return(nil)
+
+	svc := service.NewScanService(mockMongo, store, scanner.Stub{})
+	report, err := svc.ScanPending(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, service.ScanReport{Scanned: 2, Clean: 1, Infected: 1, Errors: []string{}}, report)
+	mockMongo.AssertExpectations(t)
+
+	// File terinfeksi hanya tersisa di karantina
+	_, err = os.Stat(filepath.Join(dir, "achievements", "ref-1", "att-bad.pdf"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "quarantine", "achievements", "ref-1", "att-bad.pdf"))
+	assert.NoError(t, err)
+}
+
+// Scanner yang selalu gagal (mis. clamd tidak terjangkau)
+type failingScanner struct{}
+
+func (failingScanner) Scan(ctx context.Context, body io.Reader) (scanner.Result, error) {
+	return scanner.Result{}, errors.New("clamd unreachable")
+}
+
+// Kegagalan scan dijadwalkan ulang dengan backoff; file hilang / batas percobaan langsung ditandai gagal
+func TestScanPending_RecordsFailuresWithBackoff(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocal(dir)
+	ctx := context.Background()
+	store.Put(ctx, "achievements/ref-1/att-retry.png", bytes.NewReader(pngBytes), int64(len(pngBytes)), "image/png")
+	store.Put(ctx, "achievements/ref-1/att-last.png", bytes.NewReader(pngBytes), int64(len(pngBytes)), "image/png")
+
+	docID := primitive.NewObjectID()
+	mockMongo := new(mocks.AchievementRepoMongo)
+	mockMongo.On("FindPendingScans", mock.Anything, service.ScanBatchSize).Return([]mongodb.PendingAttachment{
+		{AchievementID: docID, Attachment: mongodb.Attachment{ID: "att-gone", StorageKey: "achievements/ref-1/att-gone.png"}},
+		{AchievementID: docID, Attachment: mongodb.Attachment{ID: "att-retry", StorageKey: "achievements/ref-1/att-retry.png", ScanAttempts: 1}},
+		{AchievementID: docID, Attachment: mongodb.Attachment{ID: "att-last", StorageKey: "achievements/ref-1/att-last.png", ScanAttempts: service.MaxScanAttempts - 1}},
+	}, nil)
+	mockMongo.On("RecordScanFailure", mock.Anything, docID.Hex(), "att-gone", mock.Anything, mock.Anything, true).Return(nil)
+	mockMongo.On("RecordScanFailure", mock.Anything, docID.Hex(), "att-retry", "clamd unreachable", mock.MatchedBy(func(next time.Time) bool {
+		d := time.Until(next)
+		return d > 110*time.Second && d <= 2*service.ScanRetryBase
+	}), false).Return(nil)
+	mockMongo.On("RecordScanFailure", mock.Anything, docID.Hex(), "att-last", "clamd unreachable", mock.Anything, true).Return(nil)
+
+	svc := service.NewScanService(mockMongo, store, failingScanner{})
+	report, err := svc.ScanPending(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Failed)
+	assert.Len(t, report.Errors, 3)
+	mockMongo.AssertExpectations(t)
+}
+
+func TestSubmitAchievement_BlockedByUnscannedAttachments(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{Attachments: []mongodb.Attachment{
+		{ID: "att-1", ScanStatus: mongodb.ScanClean},
+		{ID: "att-2", ScanStatus: mongodb.ScanPending},
+		{ID: "att-3", ScanStatus: mongodb.ScanInfected},
+		{ID: "att-4", ScanStatus: mongodb.ScanFailed},
+	}}, nil)
+	// Lampiran dicek di dalam transisi yang terkunci (mock menjalankan check seperti repo)
+	mockPG.On("UpdateStatus", "ref-1", "draft", "submitted", "user-123").Return(nil)
+
+	app := setupAppWithAuth(svc.SubmitAchievement)
+	app.Post("/achievements/:id/submit", svc.SubmitAchievement)
+	resp, _ := app.Test(httptest.NewRequest("POST", "/achievements/ref-1/submit", nil))
+
+	assert.Equal(t, 409, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"id":"att-2"`)
+	assert.Contains(t, string(body), `"id":"att-3"`)
+	assert.Contains(t, string(body), `"id":"att-4"`)
+	assert.NotContains(t, string(body), `"id":"att-1"`)
+	mockPG.AssertExpectations(t)
+}
+
+// Check lampiran berjalan setelah reference dikunci; jika gagal, transisi tidak di-commit
+func TestUpdateStatus_CheckRunsUnderLock(t *testing.T) {
+	db, state := openFakeDB(t, "")
+	state.Rows = map[string][]driver.Value{"FOR UPDATE": {"draft"}}
+	repo := repoPG.NewAchievementRepoPG(db)
+
+	var lockedFirst bool
+	err := repo.UpdateStatus("ref-1", "draft", "submitted", "user-123", func() error {
+		lockedFirst = len(state.Pending()) == 1
+		return errors.New("attachment pending scan")
+	})
+
+	assert.EqualError(t, err, "attachment pending scan")
+	assert.True(t, lockedFirst)
+	assert.Empty(t, state.Committed())
+}
+
+func TestDownloadAttachment_BlockedUntilClean(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	mockStore := new(mocks.Storage)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), mockStore)
+
+	mockPG.On("GetReferenceByID", "ref-1").Return(&postgres.AchievementReference{ID: "ref-1", Status: "draft", MongoAchievementID: "mongo-1"}, nil)
+	mockMongo.On("FindAchievementByID", mock.Anything, "mongo-1").Return(&mongodb.Achievement{Attachments: []mongodb.Attachment{
+		{ID: "att-pending", StorageKey: "achievements/ref-1/att-pending.pdf", ScanStatus: mongodb.ScanPending},
+		{ID: "att-bad", StorageKey: "quarantine/achievements/ref-1/att-bad.pdf", ScanStatus: mongodb.ScanInfected},
+	}}, nil)
+
+	app := setupAppWithAuth(svc.DownloadAttachment)
+	app.Get("/achievements/:id/attachments/:attachmentId", svc.DownloadAttachment)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments/att-pending", nil))
+	assert.Equal(t, 409, resp.StatusCode)
+	resp, _ = app.Test(httptest.NewRequest("GET", "/achievements/ref-1/attachments/att-bad", nil))
+	assert.Equal(t, 403, resp.StatusCode)
+	mockStore.AssertNotCalled(t, "Open", mock.Anything, mock.Anything)
+}
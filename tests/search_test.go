@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"be_uas/app/listing"
+	"be_uas/app/model/mongodb"
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/tests/mocks"
+	"be_uas/utils"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type searchBody struct {
+	Data []struct {
+		ID         string            `json:"id"`
+		Score      float64           `json:"score"`
+		Highlights map[string]string `json:"highlights"`
+	} `json:"data"`
+}
+
+func TestSearchTermsAndHighlight(t *testing.T) {
+	terms := utils.SearchTerms(`"lomba robot" nasional -lokal`)
+	assert.Equal(t, []string{"lomba robot", "nasional"}, terms)
+
+	snippet, ok := utils.Highlight("Juara 1 Lomba Robot <Nasional> 2024", terms, 60)
+	assert.True(t, ok)
+	assert.Equal(t, "Juara 1 <mark>Lomba Robot</mark> &lt;<mark>Nasional</mark>&gt; 2024", snippet)
+
+	_, ok = utils.Highlight("Juara 1 Debat", terms, 60)
+	assert.False(t, ok)
+
+	// Huruf non-ASCII dilipat sama pada kata kunci dan teks
+	snippet, ok = utils.Highlight("Olimpiade SAINS ÉLITE", utils.SearchTerms("Élite"), 60)
+	assert.True(t, ok)
+	assert.Equal(t, "Olimpiade SAINS <mark>ÉLITE</mark>", snippet)
+}
+
+func TestSearchAchievements_StudentScope(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	oid := primitive.NewObjectID()
+	mockPG.On("GetStudentIDByUserID", "user-123").Return("student-1", nil)
+	mockPG.On("FindReferencesByFilter", mock.MatchedBy(func(f listing.Filter) bool {
+		return f.StudentID == "student-1"
+	})).Return([]postgres.AchievementReference{{ID: "ref-1", MongoAchievementID: oid.Hex()}}, nil)
+	// Pencarian dibatasi ke dokumen milik sendiri
+	mockMongo.On("SearchAchievements", mock.Anything, "robot", []string{oid.Hex()}, 0, service.SearchDefaultLimit).Return([]mongodb.AchievementSearchHit{{
+		Achievement: mongodb.Achievement{ID: oid, Title: "Juara Lomba Robot", Tags: []string{"robotik"}},
+		Score:       12.5,
+	}}, nil)
+
+	app := setupAppWithAuth(svc.SearchAchievements)
+	app.Get("/achievements/search", svc.SearchAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/search?q=robot", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body searchBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, "ref-1", body.Data[0].ID)
+	assert.Equal(t, 12.5, body.Data[0].Score)
+	assert.Equal(t, "Juara Lomba <mark>Robot</mark>", body.Data[0].Highlights["title"])
+	assert.Equal(t, "<mark>robot</mark>ik", body.Data[0].Highlights["tags"])
+}
+
+func TestSearchAchievements_AdminDropsInvisibleHits(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	visible, deleted := primitive.NewObjectID(), primitive.NewObjectID()
+	mockMongo.On("SearchAchievements", mock.Anything, "debat", []string(nil), 0, service.SearchDefaultLimit).Return([]mongodb.AchievementSearchHit{
+		{Achievement: mongodb.Achievement{ID: deleted, Title: "Debat"}, Score: 3},
+		{Achievement: mongodb.Achievement{ID: visible, Title: "Debat"}, Score: 2},
+	}, nil)
+	// Reference yang dihapus tidak dikembalikan oleh filter
+	mockPG.On("FindReferencesByFilter", mock.MatchedBy(func(f listing.Filter) bool {
+		return len(f.MongoIDs) == 2 && assert.ObjectsAreEqual([]string{"deleted"}, f.HiddenStatuses)
+	})).Return([]postgres.AchievementReference{{ID: "ref-visible", MongoAchievementID: visible.Hex()}}, nil)
+
+	app := setupAppWithAdminAuth(svc.SearchAchievements)
+	app.Get("/achievements/search", svc.SearchAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/search?q=debat", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body searchBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, "ref-visible", body.Data[0].ID)
+}
+
+func TestSearchAchievements_AdminPagesCountVisibleHitsOnly(t *testing.T) {
+	mockPG := new(mocks.AchievementRepoPG)
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(mockPG, mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	ids := make([]primitive.ObjectID, 5)
+	hits := make([]mongodb.AchievementSearchHit, 5)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+		hits[i] = mongodb.AchievementSearchHit{Achievement: mongodb.Achievement{ID: ids[i], Title: "Debat"}, Score: float64(10 - i)}
+	}
+	// Halaman 2 (limit 2) butuh 4 hit terlihat; hit ke-2 tersembunyi sehingga perlu putaran berikutnya
+	mockMongo.On("SearchAchievements", mock.Anything, "debat", []string(nil), 0, 4).Return(hits[:4], nil)
+	mockMongo.On("SearchAchievements", mock.Anything, "debat", []string(nil), 4, 4).Return(hits[4:], nil)
+	mockPG.On("FindReferencesByFilter", mock.MatchedBy(func(f listing.Filter) bool { return len(f.MongoIDs) == 4 })).Return([]postgres.AchievementReference{
+		{ID: "ref-0", MongoAchievementID: ids[0].Hex()},
+		{ID: "ref-2", MongoAchievementID: ids[2].Hex()},
+		{ID: "ref-3", MongoAchievementID: ids[3].Hex()},
+	}, nil)
+	mockPG.On("FindReferencesByFilter", mock.MatchedBy(func(f listing.Filter) bool { return len(f.MongoIDs) == 1 })).Return([]postgres.AchievementReference{
+		{ID: "ref-4", MongoAchievementID: ids[4].Hex()},
+	}, nil)
+
+	app := setupAppWithAdminAuth(svc.SearchAchievements)
+	app.Get("/achievements/search", svc.SearchAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/search?q=debat&page=2&limit=2", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var body searchBody
+	json.NewDecoder(resp.Body).Decode(&body)
+	assert.Len(t, body.Data, 2)
+	assert.Equal(t, "ref-3", body.Data[0].ID)
+	assert.Equal(t, "ref-4", body.Data[1].ID)
+}
+
+func TestSearchAchievements_EmptyQuery(t *testing.T) {
+	mockMongo := new(mocks.AchievementRepoMongo)
+	svc := service.NewAchievementService(new(mocks.AchievementRepoPG), mockMongo, new(mocks.OutboxRepo), new(mocks.AchievementTypeRepo), new(mocks.PointRuleRepo), new(mocks.Storage))
+
+	app := setupAppWithAuth(svc.SearchAchievements)
+	app.Get("/achievements/search", svc.SearchAchievements)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/achievements/search?q=%20", nil))
+
+	assert.Equal(t, 400, resp.StatusCode)
+	mockMongo.AssertNotCalled(t, "SearchAchievements", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
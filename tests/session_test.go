@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"be_uas/app/model/postgres"
+	"be_uas/app/service"
+	"be_uas/middleware"
+	"be_uas/tests/mocks"
+	"be_uas/utils"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Helper: app dengan AuthRequired asli di depan handler
+func setupProtectedApp(tokenRepo *mocks.TokenRepo, handler fiber.Handler) *fiber.App {
+	middleware.SetTokenRepo(tokenRepo)
+	app := fiber.New()
+	app.Post("/auth/logout", middleware.AuthRequired(), handler)
+	return app
+}
+
+func TestAuthRequired_RevokedToken(t *testing.T) {
+	access, _ := utils.GenerateAccessToken("user-1", "mhs", "Mahasiswa", "role-mhs", 0)
+
+	tokenRepo := new(mocks.TokenRepo)
+	tokenRepo.On("GetSessionState", "user-1", mock.Anything).Return(&postgres.SessionState{
+		IsActive: true, TokenVersion: 0, TokenRevoked: true,
+	}, nil)
+
+	app := setupProtectedApp(tokenRepo, func(c *fiber.Ctx) error { return c.SendStatus(200) })
+	req := httptest.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+access)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestAuthRequired_SessionsRevokedByVersion(t *testing.T) {
+	access, _ := utils.GenerateAccessToken("user-1", "dosen", "Dosen Wali", "role-dosen", 2)
+
+	// token_version sudah dinaikkan (role diganti / user dinonaktifkan)
+	tokenRepo := new(mocks.TokenRepo)
+	tokenRepo.On("GetSessionState", "user-1", mock.Anything).Return(&postgres.SessionState{
+		IsActive: true, TokenVersion: 3,
+	}, nil)
+
+	app := setupProtectedApp(tokenRepo, func(c *fiber.Ctx) error { return c.SendStatus(200) })
+	req := httptest.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+access)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 401, resp.StatusCode)
+}
+
+func TestLogout_RevokesCurrentToken(t *testing.T) {
+	access, _ := utils.GenerateAccessToken("user-1", "mhs", "Mahasiswa", "role-mhs", 0)
+
+	tokenRepo := new(mocks.TokenRepo)
+	tokenRepo.On("GetSessionState", "user-1", mock.Anything).Return(&postgres.SessionState{IsActive: true}, nil)
+	tokenRepo.On("RevokeToken", mock.AnythingOfType("string"), "user-1", mock.Anything).Return(nil)
+
+	authService := service.NewAuthService(new(mocks.UserRepo), tokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+	app := setupProtectedApp(tokenRepo, authService.Logout)
+
+	req := httptest.NewRequest("POST", "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+access)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	tokenRepo.AssertCalled(t, "RevokeToken", mock.AnythingOfType("string"), "user-1", mock.Anything)
+}
+
+func TestUpdateRole_RevokesSessions(t *testing.T) {
+	mockUser := new(mocks.UserRepo)
+	tokenRepo := new(mocks.TokenRepo)
+	svc := service.NewAdminService(mockUser, new(mocks.AchievementRepoPG), new(mocks.AchievementRepoMongo), tokenRepo, new(mocks.AuditRepo), new(mocks.MFARepo))
+
+	mockUser.On("GetRoleIDByName", "Dosen Wali").Return("role-uuid-dosen", nil)
+	tokenRepo.On("RevokeAllUserSessions", "user-xyz").Return(nil)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"role_name": "Dosen Wali"})
+	app := setupAppWithAdminAuth(svc.UpdateRole)
+	app.Put("/users/:id/role", svc.UpdateRole)
+
+	req := httptest.NewRequest("PUT", "/users/user-xyz/role", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	tokenRepo.AssertExpectations(t)
+}
@@ -19,6 +19,7 @@ func setupAppWithAdminAuth(handler fiber.Handler) *fiber.App {
 	app.Use(func(c *fiber.Ctx) error {
 		c.Locals("user_id", "admin-uuid-001")
 		c.Locals("role", "Admin")
+		c.Locals("permissions", adminPerms)
 		return c.Next()
 	})
 	return app
@@ -30,7 +31,7 @@ func TestCreateUser_Success(t *testing.T) {
 	mockMongo := new(mocks.AchievementRepoMongo)
 
 	// Inject ke Admin Service
-	svc := service.NewAdminService(mockUser, mockPG, mockMongo)
+	svc := service.NewAdminService(mockUser, mockPG, mockMongo, new(mocks.TokenRepo), new(mocks.AuditRepo), new(mocks.MFARepo))
 
 	// 2. Expectation
 	// Skenario: Admin ingin membuat user dengan role "Dosen Wali"
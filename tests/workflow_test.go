@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"be_uas/app/workflow"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Permission default per role (sesuai seed migrasi)
+var (
+	studentPerms  = []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete"}
+	lecturerPerms = []string{"achievement:read", "achievement:verify", "student:read"}
+	adminPerms    = []string{"achievement:create", "achievement:read", "achievement:update", "achievement:delete", "achievement:verify", "student:read", "user:manage"}
+)
+
+func TestWorkflow_ValidTransitions(t *testing.T) {
+	cases := []struct {
+		from, action string
+		perms        []string
+		to           string
+	}{
+		{workflow.StatusDraft, workflow.ActionSubmit, studentPerms, workflow.StatusSubmitted},
+		{workflow.StatusDraft, workflow.ActionDelete, studentPerms, workflow.StatusDeleted},
+		{workflow.StatusDraft, workflow.ActionEdit, adminPerms, workflow.StatusDraft},
+		{workflow.StatusSubmitted, workflow.ActionVerify, lecturerPerms, workflow.StatusVerified},
+		{workflow.StatusSubmitted, workflow.ActionReject, adminPerms, workflow.StatusRejected},
+		{workflow.StatusRejected, workflow.ActionRevise, studentPerms, workflow.StatusDraft},
+		{workflow.StatusSubmitted, workflow.ActionRequestRevision, lecturerPerms, workflow.StatusNeedsRevision},
+		{workflow.StatusNeedsRevision, workflow.ActionEdit, studentPerms, workflow.StatusNeedsRevision},
+		{workflow.StatusNeedsRevision, workflow.ActionSubmit, studentPerms, workflow.StatusSubmitted},
+	}
+
+	for _, tc := range cases {
+		to, err := workflow.Transition(tc.from, tc.action, tc.perms)
+		assert.NoError(t, err, "%s -> %s", tc.from, tc.action)
+		assert.Equal(t, tc.to, to)
+	}
+}
+
+func TestWorkflow_InvalidTransitions(t *testing.T) {
+	// Verifikasi draft / deleted tidak diizinkan
+	_, err := workflow.Transition(workflow.StatusDraft, workflow.ActionVerify, lecturerPerms)
+	assert.True(t, errors.Is(err, workflow.ErrInvalidTransition))
+
+	_, err = workflow.Transition(workflow.StatusDeleted, workflow.ActionVerify, adminPerms)
+	assert.True(t, errors.Is(err, workflow.ErrInvalidTransition))
+
+	// Prestasi yang sudah verified tidak bisa diedit
+	_, err = workflow.Transition(workflow.StatusVerified, workflow.ActionEdit, studentPerms)
+	assert.True(t, errors.Is(err, workflow.ErrInvalidTransition))
+
+	// Mahasiswa tidak punya achievement:verify
+	_, err = workflow.Transition(workflow.StatusSubmitted, workflow.ActionVerify, studentPerms)
+	assert.True(t, errors.Is(err, workflow.ErrRoleNotAllowed))
+
+	var te *workflow.TransitionError
+	assert.True(t, errors.As(err, &te))
+	assert.Equal(t, workflow.StatusSubmitted, te.From)
+	assert.Equal(t, "achievement:verify", te.Permission)
+}
+
+func TestWorkflow_AvailableActions(t *testing.T) {
+	assert.Equal(t, []string{"delete", "edit", "submit"}, workflow.AvailableActions(workflow.StatusDraft, studentPerms))
+	assert.Equal(t, []string{"reject", "request_revision", "verify"}, workflow.AvailableActions(workflow.StatusSubmitted, lecturerPerms))
+	assert.Empty(t, workflow.AvailableActions(workflow.StatusVerified, adminPerms))
+}
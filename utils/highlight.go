@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kata kunci dari query pencarian (mengikuti sintaks $text MongoDB: "frasa" dan -pengecualian)
+func SearchTerms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	add := func(t string) {
+		t = foldCase(strings.TrimSpace(t))
+		if t != "" && !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+
+	// Frasa dalam tanda kutip dipertahankan utuh
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		if i%2 == 1 {
+			add(part)
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			if strings.HasPrefix(word, "-") {
+				continue
+			}
+			add(strings.TrimFunc(word, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }))
+		}
+	}
+	return terms
+}
+
+// Potongan teks di sekitar kemunculan kata kunci pertama, kata kunci dibungkus <mark>.
+// Teks di-escape agar aman ditampilkan sebagai HTML. Return false jika tidak ada kata kunci yang muncul
+func Highlight(text string, terms []string, radius int) (string, bool) {
+	lower := foldCase(text)
+	first := -1
+	for _, t := range terms {
+		if i := strings.Index(lower, t); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := first-radius, first+radius
+	prefix, suffix := "…", "…"
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(text) {
+		end, suffix = len(text), ""
+	}
+	// Jangan memotong di tengah karakter multi-byte
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+
+	window, windowLower := text[start:end], lower[start:end]
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i := 0; i < len(window); {
+		matched := ""
+		for _, t := range terms {
+			if strings.HasPrefix(windowLower[i:], t) && len(t) > len(matched) {
+				matched = t
+			}
+		}
+		if matched == "" {
+			j := i + 1
+			for j < len(window) && !isRuneStart(window[j]) {
+				j++
+			}
+			b.WriteString(html.EscapeString(window[i:j]))
+			i = j
+			continue
+		}
+		b.WriteString("<mark>" + html.EscapeString(window[i:i+len(matched)]) + "</mark>")
+		i += len(matched)
+	}
+	b.WriteString(suffix)
+	return b.String(), true
+}
+
+// Lowercase yang tidak mengubah panjang byte, agar indeks tetap sejajar dengan teks asli.
+// Dipakai untuk kata kunci dan teks agar keduanya dilipat dengan cara yang sama
+func foldCase(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if l := unicode.ToLower(r); r != utf8.RuneError && utf8.RuneLen(l) == size {
+			b.WriteRune(l)
+		} else {
+			b.WriteString(s[i : i+size]) // Byte tidak valid / panjang berubah: biarkan apa adanya
+		}
+		i += size
+	}
+	return b.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
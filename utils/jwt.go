@@ -1,42 +1,49 @@
 package utils
 
 import (
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-// Return: (AccessToken, RefreshToken, Error)
-func GenerateTokens(userID, username, role, roleID string) (string, string, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
+const (
+	AccessTokenTTL  = time.Hour * 10
+	RefreshTokenTTL = time.Hour * 24 * 7
+	MFATokenTTL     = time.Minute * 5
+)
+
+// Nilai claim "typ"
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeMFA       = "mfa"        // Langkah kedua login (verifikasi kode TOTP)
+	TokenTypeMFAEnroll = "mfa_enroll" // Enrolment 2FA wajib sebelum dapat access token
+)
+
+// Access token (typ=access). tokenVersion harus sama dengan users.token_version agar dianggap valid
+func GenerateAccessToken(userID, username, role, roleID string, tokenVersion int) (string, error) {
+	return generateToken(TokenTypeAccess, AccessTokenTTL, userID, username, role, roleID, tokenVersion)
+}
+
+// Token sementara untuk alur 2FA (typ=mfa / mfa_enroll), tidak diterima AuthRequired
+func GenerateMFAToken(typ, userID, username, role, roleID string, tokenVersion int) (string, error) {
+	return generateToken(typ, MFATokenTTL, userID, username, role, roleID, tokenVersion)
+}
+
+func generateToken(typ string, ttl time.Duration, userID, username, role, roleID string, tokenVersion int) (string, error) {
+	now := time.Now()
 
-	// 1. Access Token Claims
 	claims := jwt.MapClaims{
+		"typ":      typ,
+		"jti":      uuid.New().String(),
 		"user_id":  userID,
 		"username": username,
 		"role":     role,
 		"role_id":  roleID,
-		"exp":      time.Now().Add(time.Hour * 10).Unix(),
+		"ver":      tokenVersion,
+		"iat":      now.Unix(),
+		"exp":      now.Add(ttl).Unix(),
 	}
 	
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err := token.SignedString(secret)
-	if err != nil {
-		return "", "", err
-	}
-
-	// Refresh Token Claims
-	refreshClaims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24 * 7).Unix(),
-	}
-	
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	rt, err := refreshToken.SignedString(secret)
-	if err != nil {
-		return "", "", err
-	}
-
-	return t, rt, nil
-}
\ No newline at end of file
+	return signToken(claims)
+}
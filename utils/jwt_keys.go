@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algoritma yang diterima parser (HS256 / none selalu ditolak)
+var allowedAlgs = []string{"RS256", "EdDSA"}
+
+type jwtKey struct {
+	Kid     string
+	Alg     string
+	Method  jwt.SigningMethod
+	Private crypto.Signer // nil jika key hanya untuk verifikasi (key lama saat rotasi)
+	Public  crypto.PublicKey
+}
+
+type keySet struct {
+	active *jwtKey
+	keys   map[string]*jwtKey
+}
+
+var (
+	keysOnce sync.Once
+	keys     *keySet
+	keysErr  error
+)
+
+// LoadJWTKeys memuat key dari JWT_KEYS_DIR (file <kid>.pem, private atau public key PEM).
+// JWT_ACTIVE_KID menentukan key untuk signing; key lain tetap dipakai untuk verifikasi.
+// Jika JWT_KEYS_DIR kosong, startup gagal kecuali JWT_DEV_EPHEMERAL_KEY=true
+// (key Ed25519 sementara, hanya untuk development).
+func LoadJWTKeys() error {
+	keysOnce.Do(func() {
+		keys, keysErr = loadKeySet(os.Getenv("JWT_KEYS_DIR"), os.Getenv("JWT_ACTIVE_KID"), os.Getenv("JWT_DEV_EPHEMERAL_KEY") == "true")
+	})
+	return keysErr
+}
+
+func getKeySet() (*keySet, error) {
+	if err := LoadJWTKeys(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func loadKeySet(dir, activeKid string, allowEphemeral bool) (*keySet, error) {
+	ks := &keySet{keys: map[string]*jwtKey{}}
+
+	if dir == "" {
+		if !allowEphemeral {
+			return nil, errors.New("JWT_KEYS_DIR is not set (set JWT_DEV_EPHEMERAL_KEY=true to use an ephemeral key in development)")
+		}
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		key, err := newJWTKey("dev-ephemeral", priv)
+		if err != nil {
+			return nil, err
+		}
+		log.Println("Warning: JWT_KEYS_DIR is not set, using an ephemeral signing key")
+		ks.keys[key.Kid] = key
+		ks.active = key
+		return ks, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+		parsed, err := parsePEMKey(raw)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		key, err := newJWTKey(kid, parsed)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", kid, err)
+		}
+		ks.keys[kid] = key
+	}
+
+	// Key aktif: JWT_ACTIVE_KID, atau satu-satunya private key yang ada
+	if activeKid != "" {
+		ks.active = ks.keys[activeKid]
+	} else {
+		for _, k := range ks.keys {
+			if k.Private != nil {
+				if ks.active != nil {
+					return nil, errors.New("multiple private keys found, set JWT_ACTIVE_KID")
+				}
+				ks.active = k
+			}
+		}
+	}
+	if ks.active == nil || ks.active.Private == nil {
+		return nil, errors.New("no active private signing key found in JWT_KEYS_DIR")
+	}
+	return ks, nil
+}
+
+func parsePEMKey(raw []byte) (interface{}, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+func newJWTKey(kid string, parsed interface{}) (*jwtKey, error) {
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &jwtKey{Kid: kid, Alg: "RS256", Method: jwt.SigningMethodRS256, Private: k, Public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &jwtKey{Kid: kid, Alg: "RS256", Method: jwt.SigningMethodRS256, Public: k}, nil
+	case ed25519.PrivateKey:
+		return &jwtKey{Kid: kid, Alg: "EdDSA", Method: jwt.SigningMethodEdDSA, Private: k, Public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &jwtKey{Kid: kid, Alg: "EdDSA", Method: jwt.SigningMethodEdDSA, Public: k}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T", parsed)
+}
+
+// Tanda tangani claims dengan key aktif (header kid diisi)
+func signToken(claims jwt.Claims) (string, error) {
+	ks, err := getKeySet()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.Kid
+	return token.SignedString(ks.active.Private)
+}
+
+// ParseToken memverifikasi signature berdasarkan kid dengan algoritma yang dipatok
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	ks, err := getKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		// Algoritma token harus sama dengan algoritma key
+		if token.Method.Alg() != key.Alg {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods(allowedAlgs), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return token.Claims.(jwt.MapClaims), nil
+}
+
+// JWKS berisi semua public key (aktif + key lama yang masih diterima)
+func JWKS() (map[string]interface{}, error) {
+	ks, err := getKeySet()
+	if err != nil {
+		return nil, err
+	}
+
+	kids := make([]string, 0, len(ks.keys))
+	for kid := range ks.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := []map[string]interface{}{}
+	for _, kid := range kids {
+		key := ks.keys[kid]
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, map[string]interface{}{
+				"kty": "RSA",
+				"use": "sig",
+				"alg": key.Alg,
+				"kid": kid,
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, map[string]interface{}{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"use": "sig",
+				"alg": key.Alg,
+				"kid": kid,
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return map[string]interface{}{"keys": jwks}, nil
+}
@@ -1,6 +1,14 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 10) 
@@ -10,4 +18,96 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(hashedPassword, password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
-}
\ No newline at end of file
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// Hash pembanding untuk username yang tidak ada, cost sama dengan HashPassword
+// agar waktu respon login tidak membedakan username terdaftar
+func DummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword("dummy-password-tidak-pernah-cocok")
+	})
+	return dummyHash
+}
+
+// Aturan password baru (ganti password / reset)
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+var passwordPolicy = DefaultPasswordPolicy
+
+// Baca policy dari env (PASSWORD_MIN_LENGTH, PASSWORD_REQUIRE_UPPER/LOWER/DIGIT/SYMBOL), sisanya pakai default
+func LoadPasswordPolicy() {
+	p := DefaultPasswordPolicy
+	if v, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && v > 0 {
+		p.MinLength = v
+	}
+	p.RequireUpper = envBool("PASSWORD_REQUIRE_UPPER", p.RequireUpper)
+	p.RequireLower = envBool("PASSWORD_REQUIRE_LOWER", p.RequireLower)
+	p.RequireDigit = envBool("PASSWORD_REQUIRE_DIGIT", p.RequireDigit)
+	p.RequireSymbol = envBool("PASSWORD_REQUIRE_SYMBOL", p.RequireSymbol)
+	passwordPolicy = p
+}
+
+func SetPasswordPolicy(p PasswordPolicy) {
+	passwordPolicy = p
+}
+
+func envBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Daftar aturan yang dilanggar (kosong = valid)
+func ValidatePassword(password string) []string {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var violations []string
+	if len([]rune(password)) < passwordPolicy.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", passwordPolicy.MinLength))
+	}
+	if passwordPolicy.RequireUpper && !upper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if passwordPolicy.RequireLower && !lower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if passwordPolicy.RequireDigit && !digit {
+		violations = append(violations, "must contain a digit")
+	}
+	if passwordPolicy.RequireSymbol && !symbol {
+		violations = append(violations, "must contain a symbol")
+	}
+	return violations
+}